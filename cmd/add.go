@@ -37,6 +37,9 @@ var newRecordCmd = &cobra.Command{
 			return fmt.Errorf("%w", err)
 		}
 		defer r.Close()
+		if addNonInteractive(cmd) {
+			return addBatch(r, args)
+		}
 		// setup terminal and interrupt func handler (ctrl+c,esc handler)
 		t := terminal.New(terminal.WithInterruptFn(func(err error) {
 			r.Close()
@@ -51,7 +54,7 @@ var newRecordCmd = &cobra.Command{
 
 // add adds a new bookmark.
 func add(t *terminal.Term, r *Repo, args []string) error {
-	if t.IsPiped() && len(args) < 2 {
+	if t.IsPiped() && len(args) < 2 && !autoTagFlag {
 		return fmt.Errorf("%w: URL or TAGS cannot be empty", bookmark.ErrInvalid)
 	}
 	// header
@@ -60,7 +63,8 @@ func add(t *terminal.Term, r *Repo, args []string) error {
 	f.Header(h + color.Gray(" (ctrl+c to exit)\n").Italic().String()).Row("\n").Flush()
 
 	b := bookmark.New()
-	if err := parserNewBookmark(t, r, b, args); err != nil {
+	content, err := parserNewBookmark(t, r, b, args)
+	if err != nil {
 		return err
 	}
 	// ask confirmation
@@ -73,20 +77,95 @@ func add(t *terminal.Term, r *Repo, args []string) error {
 		}
 		t.ClearLine(1)
 	}
+	if laterFlag {
+		b.SetState(bookmark.StateUnread)
+	}
 	// validate
 	if err := bookmark.Validate(b); err != nil {
 		return fmt.Errorf("validation failed: %w", err)
 	}
+	// save offline copy
+	if archiveOnAddFlag || config.Archive.OnAdd {
+		if text := addHandleArchive(b); text != "" {
+			content = text
+		}
+	}
 	// insert new bookmark
 	if err := r.InsertOne(context.Background(), b); err != nil {
 		return fmt.Errorf("%w", err)
 	}
+	// index page content
+	handler.IndexBookmarkContent(r, b, content)
 	success := color.BrightGreen("Successfully").Italic().String()
 	f.Success(success + " bookmark created\n").Flush()
 
 	return nil
 }
 
+// addNonInteractive checks if the bookmarks must be added without prompting.
+func addNonInteractive(cmd *cobra.Command) bool {
+	if addFromFileFlag != "" {
+		return true
+	}
+	for _, name := range []string{"tags", "desc", "no-fetch", "if-exists", "json"} {
+		if cmd.Flags().Changed(name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// addBatch adds the URL from args, or the URLs from the input file, without
+// prompting.
+func addBatch(r *Repo, args []string) error {
+	var entries []handler.AddEntry
+	if addFromFileFlag != "" {
+		e, err := handler.ReadAddEntries(addFromFileFlag)
+		if err != nil {
+			return fmt.Errorf("%w", err)
+		}
+		entries = e
+	}
+	if len(args) > 0 {
+		entries = append(entries, handler.AddEntry{URL: args[0], Tags: strings.Join(args[1:], ",")})
+	}
+	if len(entries) == 0 {
+		return bookmark.ErrURLEmpty
+	}
+
+	results, err := handler.AddMany(r, entries, &handler.AddOptions{
+		Title:    titleFlag,
+		Desc:     addDescFlag,
+		Tags:     addTagsFlag,
+		IfExists: addIfExistsFlag,
+		NoFetch:  addNoFetchFlag,
+		Later:    laterFlag,
+		Archive:  archiveOnAddFlag || config.Archive.OnAdd,
+		AutoTag:  autoTagFlag,
+	})
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+	if JSON {
+		fmt.Println(string(format.ToJSON(results)))
+	} else {
+		handler.PrintAddResults(results)
+	}
+
+	var failed int
+	for _, res := range results {
+		if res.Status == handler.AddStatusError {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%w: %d of %d", handler.ErrAddFailed, failed, len(results))
+	}
+
+	return nil
+}
+
 // addHandleConfirmation confirms if the user wants to save the bookmark.
 func addHandleConfirmation(r *Repo, t *terminal.Term, b *Bookmark) error {
 	f := frame.New(frame.WithColorBorder(color.Gray))
@@ -108,6 +187,26 @@ func addHandleConfirmation(r *Repo, t *terminal.Term, b *Bookmark) error {
 	return nil
 }
 
+// addHandleArchive saves an offline copy of the new bookmark page and
+// returns its readable text.
+func addHandleArchive(b *Bookmark) string {
+	sp := rotato.New(
+		rotato.WithMesg("archiving webpage..."),
+		rotato.WithMesgColor(rotato.ColorYellow),
+		rotato.WithSpinnerColor(rotato.ColorBrightMagenta),
+	)
+	sp.Start()
+	text, err := handler.ArchivePage(b)
+	sp.Done()
+	if err != nil {
+		slog.Warn("archiving webpage", "url", b.URL, "error", err)
+		f := frame.New(frame.WithColorBorder(color.Gray))
+		f.Mid(color.BrightOrange("could not save offline copy\n").Italic().String()).Flush()
+	}
+
+	return text
+}
+
 // addHandleURL retrieves a URL from args or prompts the user for input.
 func addHandleURL(t *terminal.Term, args *[]string) string {
 	f := frame.New(frame.WithColorBorder(color.Gray))
@@ -136,14 +235,18 @@ func addHandleURL(t *terminal.Term, args *[]string) string {
 	return url
 }
 
-// addHandleTags retrieves the Tags from args or prompts the user for input.
-func addHandleTags(t *terminal.Term, r *Repo, args *[]string) string {
+// addHandleTags retrieves the Tags from args or prompts the user for input,
+// pre-filled with the suggested tags.
+func addHandleTags(t *terminal.Term, r *Repo, args *[]string, suggested []string) string {
 	f := frame.New(frame.WithColorBorder(color.Gray))
 	f.Header(color.BrightBlue("Tags\t:").String())
 	// this checks if tags are provided, parses them and return them
 	if len(*args) > 0 {
 		tags := strings.TrimRight((*args)[0], "\n")
 		tags = strings.Join(strings.Fields(tags), ",")
+		if autoTagFlag {
+			tags = strings.Join(append([]string{tags}, suggested...), ",")
+		}
 		tags = bookmark.ParseTags(tags)
 		f.Text(" " + color.Gray(tags).String()).Ln().Flush()
 
@@ -151,11 +254,18 @@ func addHandleTags(t *terminal.Term, r *Repo, args *[]string) string {
 
 		return tags
 	}
+	// use the suggested tags without prompting
+	if autoTagFlag {
+		tags := bookmark.ParseTags(strings.Join(suggested, ","))
+		f.Text(" " + color.Gray(tags).String()).Ln().Flush()
+
+		return tags
+	}
 	// prompt for tags
 	f.Text(color.Gray(" (spaces|comma separated)").Italic().String()).Ln().Flush()
 
 	mTags, _ := repo.CounterTags(r)
-	tags := bookmark.ParseTags(t.ChooseTags(f.Border.Mid, mTags))
+	tags := bookmark.ParseTags(t.ChooseTagsWithSuggested(f.Border.Mid, mTags, suggested))
 
 	f.Clear().Mid(color.BrightBlue("Tags\t:").String()).
 		Text(" " + color.Gray(tags).String()).Ln()
@@ -166,30 +276,52 @@ func addHandleTags(t *terminal.Term, r *Repo, args *[]string) string {
 	return tags
 }
 
-// parserNewBookmark fetch metadata and parses the new bookmark.
-func parserNewBookmark(t *terminal.Term, r *Repo, b *Bookmark, args []string) error {
+// addHandleRules applies the auto-tagging rules and displays the rules
+// that fired.
+func addHandleRules(b *Bookmark) {
+	ms := handler.ApplyRules(b)
+	if len(ms) == 0 {
+		return
+	}
+	f := frame.New(frame.WithColorBorder(color.Gray))
+	for _, m := range ms {
+		rule := color.Gray(m.Rule.String()).Italic().String()
+		tags := color.Gray(strings.Join(m.Tags, ",")).String()
+		f.Mid(color.BrightBlue("Rule\t:").String()).Text(" " + rule + " " + tags).Ln()
+	}
+	f.Flush()
+}
+
+// parserNewBookmark fetch metadata and parses the new bookmark, returning
+// the readable text of the page.
+func parserNewBookmark(t *terminal.Term, r *Repo, b *Bookmark, args []string) (string, error) {
 	// retrieve url
 	url, err := parseNewURL(t, &args)
 	if err != nil {
-		return err
+		return "", err
 	}
-	if b, exists := r.Has(url); exists {
-		return fmt.Errorf("%w with id=%d", bookmark.ErrDuplicate, b.ID)
+	if b, exists := handler.Duplicate(r, url); exists {
+		return "", fmt.Errorf("%w with id=%d", bookmark.ErrDuplicate, b.ID)
 	}
-	// retrieve tags
-	tags := addHandleTags(t, r, &args)
-	// fetch title and description
-	title, desc := parseTitleAndDescription(url)
+	// fetch title, description, metadata and page text
 	b.URL = url
+	title, desc, text := parseTitleAndDescription(b)
+	if d, exists := r.HasCanonical(b.CanonicalURL); exists {
+		return "", fmt.Errorf("%w with id=%d (canonical URL)", bookmark.ErrDuplicate, d.ID)
+	}
 	b.Title = title
-	b.Tags = bookmark.ParseTags(tags)
 	b.Desc = strings.Join(format.SplitIntoChunks(desc, terminal.MinWidth), "\n")
+	// retrieve tags
+	tags := addHandleTags(t, r, &args, handler.SuggestTags(r, b, text))
+	b.Tags = bookmark.ParseTags(tags)
+	addHandleRules(b)
 
-	return nil
+	return text, nil
 }
 
-// parseTitleAndDescription fetch and display title and description.
-func parseTitleAndDescription(url string) (title, desc string) {
+// parseTitleAndDescription fetch and display title and description, sets
+// the page metadata, and returns the readable text of the page.
+func parseTitleAndDescription(b *Bookmark) (title, desc, text string) {
 	const indentation int = 10
 	f := frame.New(frame.WithColorBorder(color.Gray))
 	width := terminal.MinWidth - len(f.Border.Row)
@@ -197,7 +329,7 @@ func parseTitleAndDescription(url string) (title, desc string) {
 	if titleFlag != "" {
 		t := color.Gray(format.SplitAndAlign(titleFlag, width, indentation)).String()
 		f.Mid(color.BrightCyan("Title\t: ").String()).Text(t).Ln().Flush()
-		return titleFlag, desc
+		return titleFlag, desc, text
 	}
 
 	sp := rotato.New(
@@ -207,12 +339,14 @@ func parseTitleAndDescription(url string) (title, desc string) {
 	)
 	sp.Start()
 	// scrape data
-	sc := scraper.New(url)
+	sc := scraper.New(b.URL)
 	if err := sc.Scrape(); err != nil {
-		return title, desc
+		return title, desc, text
 	}
+	bookmark.ScrapeMetadata(b, sc)
 	title = sc.Title()
 	desc = sc.Desc()
+	text = sc.Text()
 	sp.Done()
 
 	t := color.Gray(format.SplitAndAlign(title, width, indentation)).String()
@@ -224,7 +358,7 @@ func parseTitleAndDescription(url string) (title, desc string) {
 
 	f.Flush()
 
-	return title, desc
+	return title, desc, text
 }
 
 // parseNewURL parse URL from args.
@@ -235,7 +369,7 @@ func parseNewURL(t *terminal.Term, args *[]string) (string, error) {
 	}
 
 	// Trim trailing slash for future comparisons
-	return strings.TrimRight(url, "/"), nil
+	return strings.TrimRight(handler.CleanURL(url), "/"), nil
 }
 
 // addHandleClipboard checks if there a valid URL in the clipboard.
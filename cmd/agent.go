@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/haaag/gm/internal/agent"
+	"github.com/haaag/gm/internal/config"
+	"github.com/haaag/gm/internal/format"
+	"github.com/haaag/gm/internal/format/color"
+	"github.com/haaag/gm/internal/format/frame"
+)
+
+// agentTTLFlag is the inactivity after which a key expires.
+var agentTTLFlag time.Duration
+
+// agentCmd runs the passphrase agent.
+var agentCmd = &cobra.Command{
+	Use:   "agent",
+	Short: "Hold the keys of encrypted databases for a session",
+	Long: `Run the agent in the foreground, holding in memory the keys of the
+encrypted databases unlocked during the session, so the password is asked
+once. Like ssh-agent, run it in the background:
+
+  gm agent &
+
+The keys expire after the TTL without use and are cleared when the agent
+stops. The socket is only accessible by the user, its path can be set
+with the GOMARKS_AGENT_SOCK environment variable.`,
+	Example: `  gm agent --ttl 30m &
+  gm agent lock`,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		if agentTTLFlag <= 0 {
+			return fmt.Errorf("%w: ttl %s", agent.ErrRequest, agentTTLFlag)
+		}
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		p := agent.SocketPath()
+		f := frame.New(frame.WithColorBorder(color.Gray))
+		f.Success(fmt.Sprintf("agent listening on %s (ttl %s)\n",
+			color.Text(p).Italic(), agentTTLFlag)).Flush()
+
+		return agent.New(agentTTLFlag).Serve(ctx, p)
+	},
+}
+
+// agentLockCmd clears the keys of the agent.
+var agentLockCmd = &cobra.Command{
+	Use:   "lock",
+	Short: "Clear the keys held by the agent",
+	RunE: func(_ *cobra.Command, _ []string) error {
+		if err := agent.Lock(); err != nil {
+			return fmt.Errorf("%w", err)
+		}
+		fmt.Printf("%s: %s\n", config.App.Name, color.Blue("agent locked").Bold())
+
+		return nil
+	},
+}
+
+// agentStopCmd stops the agent.
+var agentStopCmd = &cobra.Command{
+	Use:   "stop",
+	Short: "Stop the agent, clearing its keys",
+	RunE: func(_ *cobra.Command, _ []string) error {
+		if err := agent.Stop(); err != nil {
+			return fmt.Errorf("%w", err)
+		}
+		fmt.Printf("%s: %s\n", config.App.Name, color.Blue("agent stopped").Bold())
+
+		return nil
+	},
+}
+
+// agentStatusCmd shows the status of the agent.
+var agentStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show the status of the agent",
+	RunE: func(_ *cobra.Command, _ []string) error {
+		s, err := agent.State()
+		if err != nil {
+			return fmt.Errorf("%w", err)
+		}
+		if JSON {
+			fmt.Println(string(format.ToJSON(s)))
+			return nil
+		}
+		f := frame.New(frame.WithColorBorder(color.Gray))
+		f.Header(color.BrightYellow("Agent:").String()).Ln().
+			Mid(fmt.Sprintf("socket %s", color.Text(s.Socket).Italic())).Ln().
+			Mid(fmt.Sprintf("pid    %d", s.PID)).Ln().
+			Mid(fmt.Sprintf("keys   %d", s.Keys)).Ln().
+			Footer(fmt.Sprintf("ttl    %s\n", s.TTL)).Flush()
+
+		return nil
+	},
+}
+
+func init() {
+	agentCmd.Flags().DurationVar(&agentTTLFlag, "ttl", 15*time.Minute, "expire keys after this inactivity")
+	agentStatusCmd.Flags().BoolVarP(&JSON, "json", "j", false, "output in JSON format")
+	agentCmd.AddCommand(agentLockCmd, agentStopCmd, agentStatusCmd)
+	rootCmd.AddCommand(agentCmd)
+}
@@ -77,11 +77,107 @@ var backupUnlockCmd = &cobra.Command{
 		if err != nil {
 			return fmt.Errorf("%w", err)
 		}
+		if identityFlag != "" {
+			config.Age.Identity = identityFlag
+		}
 
 		return handler.UnlockRepo(t, r[0])
 	},
 }
 
+// backupPasswdCmd changes the password of a locked backup.
+var backupPasswdCmd = &cobra.Command{
+	Use:   "passwd",
+	Short: "Change the password of a locked backup",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		t := terminal.New(terminal.WithInterruptFn(func(err error) { sys.ErrAndExit(err) }))
+		if !files.Exists(config.App.Path.Backup) {
+			return fmt.Errorf("%w", repo.ErrBackupNotFound)
+		}
+		r, err := handler.SelectFileEncrypted(config.App.Path.Backup, "select backup to change password")
+		if err != nil {
+			return fmt.Errorf("%w", err)
+		}
+
+		return handler.PasswdRepo(t, r[0])
+	},
+}
+
+// backupRestoreCmd replaces the database with a backup.
+var backupRestoreCmd = &cobra.Command{
+	Use:   "restore [backup]",
+	Short: "Restore the database from a backup",
+	Long: `Replace the database with a backup, showing first the records added,
+removed and changed. The newest backup is restored unless one is given or
+selected with --menu. The current state is backed up first.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		r, err := repo.New(config.App.DBPath)
+		if err != nil {
+			return fmt.Errorf("backup: %w", err)
+		}
+		defer r.Close()
+		t := terminal.New(terminal.WithInterruptFn(func(err error) {
+			r.Close()
+			sys.ErrAndExit(err)
+		}))
+		var name string
+		if len(args) > 0 {
+			name = args[0]
+		}
+		bk, err := handler.BackupToRestore(r, name, Menu)
+		if err != nil {
+			return fmt.Errorf("%w", err)
+		}
+
+		return handler.RestoreBackup(t, r, bk)
+	},
+}
+
+// pruneDryRunFlag only lists the expired backups.
+var pruneDryRunFlag bool
+
+// backupPruneCmd removes the backups the retention policy does not keep.
+var backupPruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Remove backups by the retention policy",
+	Long: `Remove the backups not kept by the retention policy of the config file,
+keep_last, keep_daily, keep_weekly and keep_monthly. Retention is opt-in,
+with every rule at 0 all backups are kept. Once set, the policy is also
+applied after each backup.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		r, err := repo.New(config.App.DBPath)
+		if err != nil {
+			return fmt.Errorf("backup: %w", err)
+		}
+		defer r.Close()
+		t := terminal.New(terminal.WithInterruptFn(func(err error) {
+			r.Close()
+			sys.ErrAndExit(err)
+		}))
+
+		return handler.PruneBackups(t, r, pruneDryRunFlag)
+	},
+}
+
+// backupVerifyCmd checks the backups with the manifest.
+var backupVerifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Verify the checksum and integrity of backups",
+	Long: `Check the SHA-256 of each backup with the one recorded in the manifest
+when created and run the SQLite integrity check on its database. Locked
+backups are only checked by their checksum.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		r, err := repo.New(config.App.DBPath)
+		if err != nil {
+			return fmt.Errorf("backup: %w", err)
+		}
+		defer r.Close()
+
+		return handler.VerifyBackups(r, JSON)
+	},
+}
+
 // backupListCmd list backups.
 var backupListCmd = &cobra.Command{
 	Use:     "list",
@@ -139,12 +235,18 @@ var backupNewCmd = &cobra.Command{
 		success := color.BrightGreen("Successfully").Italic().String()
 		s := color.Text(newBkPath).Italic().String()
 		f.Clear().Success(success + " backup created: " + s + "\n").Flush()
+		// new backups are encrypted to the configured age recipients.
+		bkPath := filepath.Join(r.Cfg.BackupDir, newBkPath)
+		locked, err := handler.LockBackup(bkPath)
+		if err != nil {
+			return fmt.Errorf("%w", err)
+		}
 		// FIX: don't return -> gomarks: action aborted
-		if config.App.Force {
+		if locked || config.App.Force {
 			return nil
 		}
 
-		return handler.LockRepo(t, filepath.Join(r.Cfg.BackupDir, newBkPath))
+		return handler.LockRepo(t, bkPath)
 	},
 }
 
@@ -157,6 +259,13 @@ func init() {
 	f.BoolP("help", "h", false, "Hidden help")
 	_ = f.MarkHidden("help")
 	backupUnlockCmd.Flags().BoolVarP(&Menu, "menu", "m", false, "select a backup to lock|unlock (fzf)")
-	backupCmd.AddCommand(backupNewCmd, backupListCmd, backupRmCmd, backupLockCmd, backupUnlockCmd)
+	backupUnlockCmd.Flags().StringVarP(&identityFlag, "identity", "i", "", "age identity file")
+	backupRestoreCmd.Flags().BoolVarP(&Menu, "menu", "m", false, "select a backup to restore (fzf)")
+	backupPruneCmd.Flags().BoolVar(&pruneDryRunFlag, "dry-run", false, "list expired backups without removing")
+	backupVerifyCmd.Flags().BoolVarP(&JSON, "json", "j", false, "output in JSON format")
+	backupCmd.AddCommand(
+		backupNewCmd, backupListCmd, backupRmCmd, backupLockCmd, backupUnlockCmd,
+		backupPasswdCmd, backupPruneCmd, backupRestoreCmd, backupVerifyCmd,
+	)
 	rootCmd.AddCommand(backupCmd)
 }
@@ -0,0 +1,293 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/haaag/gm/internal/config"
+	"github.com/haaag/gm/internal/format/color"
+	"github.com/haaag/gm/internal/format/frame"
+	"github.com/haaag/gm/internal/handler"
+	"github.com/haaag/gm/internal/repo"
+	"github.com/haaag/gm/internal/slice"
+	"github.com/haaag/gm/internal/sys"
+	"github.com/haaag/gm/internal/sys/files"
+	"github.com/haaag/gm/internal/sys/terminal"
+)
+
+var (
+	// collDescFlag is the description of a new collection.
+	collDescFlag string
+
+	// collFormatFlag is the export format.
+	collFormatFlag string
+
+	// collOutputFlag is the export output file.
+	collOutputFlag string
+
+	// collWidthFlag sets the output width, used when the menu reloads the
+	// items.
+	collWidthFlag int
+)
+
+// collectionCmd manages collections, ordered lists of bookmarks.
+var collectionCmd = &cobra.Command{
+	Use:     "collection",
+	Aliases: []string{"coll", "collections"},
+	Short:   "Ordered, named lists of bookmarks",
+	Long: `Collections are curated reading lists, unlike tags the bookmarks keep
+the order they were added or moved to. A bookmark can belong to many
+collections.
+
+Without a subcommand, lists the collections.`,
+	PersistentPreRunE: func(cmd *cobra.Command, _ []string) error {
+		return handler.CheckDBNotEncrypted()
+	},
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		r, err := repo.New(config.App.DBPath)
+		if err != nil {
+			return fmt.Errorf("%w", err)
+		}
+		defer r.Close()
+
+		return handler.ListCollections(r, JSON)
+	},
+}
+
+// collectionNewCmd creates a collection.
+var collectionNewCmd = &cobra.Command{
+	Use:     "new <name>",
+	Short:   "Create a new collection",
+	Example: `  gm collection new onboarding --desc "first week reading"`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 1 {
+			return cmd.Usage()
+		}
+		r, err := repo.New(config.App.DBPath)
+		if err != nil {
+			return fmt.Errorf("%w", err)
+		}
+		defer r.Close()
+
+		return handler.NewCollection(r, args[0], collDescFlag)
+	},
+}
+
+// collectionAddCmd appends bookmarks to a collection.
+var collectionAddCmd = &cobra.Command{
+	Use:     "add <name> <id|query>",
+	Short:   "Append bookmarks to a collection",
+	Example: `  gm collection add onboarding 12 4 31`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) < 2 {
+			return cmd.Usage()
+		}
+		r, err := repo.New(config.App.DBPath)
+		if err != nil {
+			return fmt.Errorf("%w", err)
+		}
+		defer r.Close()
+
+		bs, err := collectionRecords(r, args[1:])
+		if err != nil {
+			return err
+		}
+
+		return handler.CollectionAdd(r, args[0], bs)
+	},
+}
+
+// collectionRmCmd removes bookmarks from a collection, or the collection.
+var collectionRmCmd = &cobra.Command{
+	Use:   "rm <name> [id|query]",
+	Short: "Remove bookmarks from a collection, or the collection",
+	Long: `Remove the bookmarks from the collection, the bookmarks are kept in the
+database.
+
+Without bookmarks, removes the collection.`,
+	Example: `  gm collection rm onboarding 31
+  gm collection rm onboarding`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 0 {
+			return cmd.Usage()
+		}
+		r, err := repo.New(config.App.DBPath)
+		if err != nil {
+			return fmt.Errorf("%w", err)
+		}
+		defer r.Close()
+
+		if len(args) == 1 {
+			t := terminal.New(terminal.WithInterruptFn(func(err error) {
+				r.Close()
+				sys.ErrAndExit(err)
+			}))
+			defer t.CancelInterruptHandler()
+
+			return handler.RemoveCollection(t, r, args[0])
+		}
+		bs, err := collectionRecords(r, args[1:])
+		if err != nil {
+			return err
+		}
+
+		return handler.CollectionRemove(r, args[0], bs)
+	},
+}
+
+// collectionMoveCmd moves a bookmark within a collection.
+var collectionMoveCmd = &cobra.Command{
+	Use:   "move <name> <id> <position|up|down|top|bottom>",
+	Short: "Move a bookmark within a collection",
+	Example: `  gm collection move onboarding 31 1
+  gm collection move onboarding 31 down`,
+	Aliases: []string{"mv"},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 3 {
+			return cmd.Usage()
+		}
+		r, err := repo.New(config.App.DBPath)
+		if err != nil {
+			return fmt.Errorf("%w", err)
+		}
+		defer r.Close()
+
+		bs := slice.New[Bookmark]()
+		if err := handler.ByIDs(r, bs, args[1:2]); err != nil {
+			return fmt.Errorf("%w", err)
+		}
+		if bs.Len() != 1 {
+			return fmt.Errorf("%w: %q", repo.ErrRecordNotFound, args[1])
+		}
+
+		b := bs.Item(0)
+
+		return handler.CollectionMove(r, args[0], &b, args[2])
+	},
+}
+
+// collectionShowCmd shows the bookmarks of a collection in order.
+var collectionShowCmd = &cobra.Command{
+	Use:   "show <name>",
+	Short: "Show the bookmarks of a collection in order",
+	Long: `Show the bookmarks of the collection in order.
+
+With the menu (fzf), use the keybinds to move the current bookmark up or
+down.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 1 {
+			return cmd.Usage()
+		}
+		r, err := repo.New(config.App.DBPath)
+		if err != nil {
+			return fmt.Errorf("%w", err)
+		}
+		defer r.Close()
+
+		if collWidthFlag > 0 {
+			terminal.MaxWidth = collWidthFlag
+		}
+		bs := slice.New[Bookmark]()
+		c, err := handler.CollectionRecords(r, args[0], bs)
+		if err != nil {
+			return fmt.Errorf("%w", err)
+		}
+		switch {
+		case JSON:
+			return handler.JSON(bs)
+		case Oneline:
+			return handler.Oneline(bs)
+		case Field != "":
+			return handler.ByField(bs, Field)
+		case Menu:
+			if err := handler.SelectFromCollection(c, bs); err != nil {
+				return fmt.Errorf("%w", err)
+			}
+
+			return handler.Print(bs)
+		default:
+			return handler.PrintCollection(c, bs)
+		}
+	},
+}
+
+// collectionExportCmd exports a collection in order.
+var collectionExportCmd = &cobra.Command{
+	Use:   "export <name>",
+	Short: "Export a collection to Markdown or HTML",
+	Example: `  gm collection export onboarding > onboarding.md
+  gm collection export onboarding --format html -o onboarding.html`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 1 {
+			return cmd.Usage()
+		}
+		r, err := repo.New(config.App.DBPath)
+		if err != nil {
+			return fmt.Errorf("%w", err)
+		}
+		defer r.Close()
+
+		bs := slice.New[Bookmark]()
+		c, err := handler.CollectionRecords(r, args[0], bs)
+		if err != nil {
+			return fmt.Errorf("%w", err)
+		}
+		out, err := handler.ExportCollection(c, bs, collFormatFlag)
+		if err != nil {
+			return fmt.Errorf("%w", err)
+		}
+		if collOutputFlag == "" {
+			fmt.Print(out)
+			return nil
+		}
+		if err := os.WriteFile(collOutputFlag, []byte(out), files.FilePerm); err != nil {
+			return fmt.Errorf("writing export: %w", err)
+		}
+		f := frame.New(frame.WithColorBorder(color.Gray))
+		p := color.Text(collOutputFlag).Italic().String()
+		f.Success(fmt.Sprintf("exported %d bookmarks to %s\n", bs.Len(), p)).Flush()
+
+		return nil
+	},
+}
+
+// collectionRecords gets the bookmarks by IDs or query, an empty query is
+// not allowed.
+func collectionRecords(r *repo.SQLiteRepository, args []string) (*slice.Slice[Bookmark], error) {
+	if len(args) == 0 {
+		return nil, repo.ErrRecordQueryNotProvided
+	}
+	bs := slice.New[Bookmark]()
+	if err := handler.Records(r, bs, args); err != nil {
+		return nil, fmt.Errorf("%w", err)
+	}
+	if bs.Empty() {
+		return nil, repo.ErrRecordNotFound
+	}
+	// keep the order given by the user
+	handler.OrderByIDs(bs, args)
+
+	return bs, nil
+}
+
+func init() {
+	collectionCmd.Flags().BoolVarP(&JSON, "json", "j", false, "output in JSON format")
+	collectionNewCmd.Flags().StringVarP(&collDescFlag, "desc", "d", "", "description of the collection")
+	sf := collectionShowCmd.Flags()
+	sf.BoolVarP(&JSON, "json", "j", false, "output in JSON format")
+	sf.BoolVarP(&Oneline, "oneline", "O", false, "output in formatted oneline (fzf)")
+	sf.BoolVarP(&Menu, "menu", "m", false, "menu mode (fzf), reorder with keybinds")
+	sf.StringVarP(&Field, "field", "f", "", "output by field [id|url|title|tags|notes]")
+	sf.IntVar(&collWidthFlag, "width", 0, "output width")
+	_ = sf.MarkHidden("width")
+	ef := collectionExportCmd.Flags()
+	ef.StringVarP(&collFormatFlag, "format", "F", "md", "export format [md|html]")
+	ef.StringVarP(&collOutputFlag, "output", "o", "", "write to file instead of stdout")
+	collectionCmd.AddCommand(
+		collectionNewCmd, collectionAddCmd, collectionRmCmd,
+		collectionMoveCmd, collectionShowCmd, collectionExportCmd,
+	)
+	rootCmd.AddCommand(collectionCmd)
+}
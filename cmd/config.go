@@ -103,6 +103,16 @@ func loadConfig(p string) error {
 
 	config.Fzf = cfg.Menu
 	config.App.Colorscheme = cfg.Colorscheme
+	config.Wayback = cfg.Wayback
+	config.Archive = cfg.Archive
+	config.Rules = cfg.Rules
+	config.Canonical = cfg.Canonical
+	config.Watch = cfg.Watch
+	config.Remind = cfg.Remind
+	config.Journal = cfg.Journal
+	config.History = cfg.History
+	config.Age = cfg.Age
+	config.Backup = cfg.Backup
 
 	return nil
 }
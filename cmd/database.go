@@ -107,11 +107,27 @@ var databaseRmCmd = &cobra.Command{
 	},
 }
 
+// age recipients and identity flags.
+var (
+	recipientsFlag     []string
+	recipientsFileFlag string
+	identityFlag       string
+)
+
 var databaseLockCmd = &cobra.Command{
 	Use:   "lock",
 	Short: "Lock a database",
+	Long: `Lock a database with a password, or to age recipients with --recipient
+and --recipients-file. Any of the recipients' identities can unlock it.
+
+The recipients are recorded in <name>.enc.recipients, keep it next to the
+database: without it the database opens read-only.`,
 	RunE: func(_ *cobra.Command, _ []string) error {
 		t := terminal.New(terminal.WithInterruptFn(func(err error) { sys.ErrAndExit(err) }))
+		if len(recipientsFlag) > 0 || recipientsFileFlag != "" {
+			return handler.LockRepoTo(t, config.App.DBPath, recipientsFlag, recipientsFileFlag)
+		}
+
 		return handler.LockRepo(t, config.App.DBPath)
 	},
 }
@@ -122,10 +138,36 @@ var databaseUnlockCmd = &cobra.Command{
 	RunE: func(cmd *cobra.Command, args []string) error {
 		t := terminal.New(terminal.WithInterruptFn(func(err error) { sys.ErrAndExit(err) }))
 		r := filepath.Join(config.App.Path.Data, config.App.DBName)
+		if identityFlag != "" {
+			config.Age.Identity = identityFlag
+		}
+
 		return handler.UnlockRepo(t, r)
 	},
 }
 
+var databaseRekeyCmd = &cobra.Command{
+	Use:   "rekey",
+	Short: "Upgrade the encryption of a locked database",
+	Long: `Encrypt again a database locked by an older version, deriving the key
+with Argon2id instead of a single SHA-256 of the password.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		t := terminal.New(terminal.WithInterruptFn(func(err error) { sys.ErrAndExit(err) }))
+		r := filepath.Join(config.App.Path.Data, config.App.DBName)
+		return handler.RekeyRepo(t, r)
+	},
+}
+
+var databasePasswdCmd = &cobra.Command{
+	Use:   "passwd",
+	Short: "Change the password of a locked database",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		t := terminal.New(terminal.WithInterruptFn(func(err error) { sys.ErrAndExit(err) }))
+		r := filepath.Join(config.App.Path.Data, config.App.DBName)
+		return handler.PasswdRepo(t, r)
+	},
+}
+
 func init() {
 	f := dbCmd.Flags()
 	f.BoolVar(&Force, "force", false, "force action | don't ask confirmation")
@@ -139,10 +181,16 @@ func init() {
 	databaseInfoCmd.Flags().BoolVarP(&JSON, "json", "j", false, "output in JSON format")
 	// remove database
 	databaseRmCmd.Flags().BoolVarP(&Menu, "menu", "m", false, "select database to remove (fzf)")
+	// lock to age recipients
+	databaseLockCmd.Flags().StringSliceVarP(&recipientsFlag, "recipient", "r", nil, "age recipient public key (age1...)")
+	databaseLockCmd.Flags().StringVar(&recipientsFileFlag, "recipients-file", "", "file with age recipients, one per line")
+	// unlock with an age identity
+	databaseUnlockCmd.Flags().StringVarP(&identityFlag, "identity", "i", "", "age identity file")
 	// add subcommands
 	dbCmd.AddCommand(
 		databaseDropCmd, databaseInfoCmd, databaseNewCmd, databaseListCmd,
-		databaseRmCmd, databaseLockCmd, databaseUnlockCmd,
+		databaseRmCmd, databaseLockCmd, databaseUnlockCmd, databaseRekeyCmd,
+		databasePasswdCmd,
 	)
 	rootCmd.AddCommand(dbCmd)
 }
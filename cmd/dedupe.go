@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/haaag/gm/internal/config"
+	"github.com/haaag/gm/internal/handler"
+	"github.com/haaag/gm/internal/repo"
+	"github.com/haaag/gm/internal/slice"
+	"github.com/haaag/gm/internal/sys"
+	"github.com/haaag/gm/internal/sys/terminal"
+)
+
+// dedupeCmd finds and merges near-duplicate bookmarks.
+var dedupeCmd = &cobra.Command{
+	Use:   "dedupe",
+	Short: "Find and merge near-duplicate bookmarks",
+	PreRunE: func(cmd *cobra.Command, _ []string) error {
+		return handler.CheckDBNotEncrypted()
+	},
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		r, err := repo.New(config.App.DBPath)
+		if err != nil {
+			return fmt.Errorf("%w", err)
+		}
+		defer r.Close()
+		t := terminal.New(terminal.WithInterruptFn(func(err error) {
+			r.Close()
+			sys.ErrAndExit(err)
+		}))
+		defer t.CancelInterruptHandler()
+
+		bs := slice.New[Bookmark]()
+		if err := r.All(bs); err != nil {
+			return fmt.Errorf("%w", err)
+		}
+
+		return handler.Dedupe(t, r, bs)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(dedupeCmd)
+}
@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/haaag/gm/internal/config"
+	"github.com/haaag/gm/internal/handler"
+	"github.com/haaag/gm/internal/repo"
+	"github.com/haaag/gm/internal/slice"
+)
+
+// historyCmd shows the changes made to bookmarks over time.
+var historyCmd = &cobra.Command{
+	Use:   "history <id|query>",
+	Short: "Show the history of changes of a bookmark",
+	Long: `Show every change made to the bookmarks, oldest first, with the old and
+new value of each field, the time and the command that made the change.
+
+The number of revisions kept per bookmark is set by 'history.revisions' in
+the config file, 0 disables the history.`,
+	Example: `  gm history 42
+  gm history 42 --json`,
+	PreRunE: func(cmd *cobra.Command, _ []string) error {
+		return handler.CheckDBNotEncrypted()
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 0 {
+			return cmd.Usage()
+		}
+		r, err := repo.New(config.App.DBPath)
+		if err != nil {
+			return fmt.Errorf("%w", err)
+		}
+		defer r.Close()
+
+		bs := slice.New[Bookmark]()
+		if err := handler.Records(r, bs, args); err != nil {
+			return fmt.Errorf("%w", err)
+		}
+		if bs.Empty() {
+			return repo.ErrRecordNotFound
+		}
+
+		return handler.PrintHistory(r, bs, JSON)
+	},
+}
+
+func init() {
+	historyCmd.Flags().BoolVarP(&JSON, "json", "j", false, "output in JSON format")
+	rootCmd.AddCommand(historyCmd)
+}
@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/haaag/gm/internal/config"
+	"github.com/haaag/gm/internal/handler"
+	"github.com/haaag/gm/internal/repo"
+	"github.com/haaag/gm/internal/slice"
+	"github.com/haaag/gm/internal/sys/terminal"
+)
+
+// inboxWidthFlag sets the output width, used when the menu reloads the items.
+var inboxWidthFlag int
+
+// inboxCmd shows the read-later inbox.
+var inboxCmd = &cobra.Command{
+	Use:   "inbox",
+	Short: "Read-later inbox of unread bookmarks",
+	Long: `Show the unread bookmarks, oldest first, in the menu (fzf).
+
+Selected bookmarks are opened and marked as read. Use the keybinds to mark
+them as read, archive or snooze them for a day.`,
+	Aliases: []string{"in"},
+	PreRunE: func(cmd *cobra.Command, _ []string) error {
+		return handler.CheckDBNotEncrypted()
+	},
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		r, err := repo.New(config.App.DBPath)
+		if err != nil {
+			return fmt.Errorf("%w", err)
+		}
+		defer r.Close()
+
+		if inboxWidthFlag > 0 {
+			terminal.MaxWidth = inboxWidthFlag
+		}
+		bs := slice.New[Bookmark]()
+		if err := handler.Inbox(r, bs); err != nil {
+			if Oneline {
+				// keep the menu reload quiet
+				return nil
+			}
+
+			return fmt.Errorf("%w", err)
+		}
+		switch {
+		case JSON:
+			return handler.JSON(bs)
+		case Oneline:
+			return handler.Oneline(bs)
+		}
+
+		if err := handler.SelectFromInbox(bs); err != nil {
+			return fmt.Errorf("%w", err)
+		}
+
+		return handler.OpenAndMarkRead(r, bs)
+	},
+}
+
+func init() {
+	f := inboxCmd.Flags()
+	f.BoolVarP(&JSON, "json", "j", false, "output in JSON format")
+	f.BoolVarP(&Oneline, "oneline", "O", false, "output in formatted oneline (fzf)")
+	f.IntVar(&inboxWidthFlag, "width", 0, "output width")
+	_ = f.MarkHidden("width")
+	rootCmd.AddCommand(inboxCmd)
+}
@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/haaag/gm/internal/config"
+	"github.com/haaag/gm/internal/handler"
+	"github.com/haaag/gm/internal/repo"
+	"github.com/haaag/gm/internal/sys/terminal"
+)
+
+// indexCmd indexes the readable text of the bookmarked pages.
+var indexCmd = &cobra.Command{
+	Use:   "index",
+	Short: "Index the page content of bookmarks",
+	PreRunE: func(cmd *cobra.Command, _ []string) error {
+		return handler.CheckDBNotEncrypted()
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		r, err := repo.New(config.App.DBPath)
+		if err != nil {
+			return fmt.Errorf("%w", err)
+		}
+		defer r.Close()
+		terminal.ReadPipedInput(&args)
+		bs, err := handler.Data(cmd, handler.MenuForRecords[Bookmark](cmd), r, args)
+		if err != nil {
+			return fmt.Errorf("%w", err)
+		}
+		if bs.Empty() {
+			return repo.ErrRecordNotFound
+		}
+
+		return handler.Index(r, bs)
+	},
+}
+
+func init() {
+	f := indexCmd.Flags()
+	f.StringSliceVarP(&Tags, "tag", "t", nil, "filter by tag")
+	f.BoolVarP(&Menu, "menu", "m", false, "menu mode (fzf)")
+	f.BoolVarP(&Multiline, "multiline", "M", false, "menu mode with multiline view (fzf)")
+	f.IntVarP(&Head, "head", "H", 0, "the <int> first part of bookmarks")
+	f.IntVarP(&Tail, "tail", "T", 0, "the <int> last part of bookmarks")
+	rootCmd.AddCommand(indexCmd)
+}
@@ -6,12 +6,31 @@ import (
 	"github.com/haaag/gm/internal/handler"
 )
 
-var titleFlag string
+var (
+	titleFlag string
+
+	// archiveOnAddFlag saves an offline copy of the new bookmark.
+	archiveOnAddFlag bool
+
+	// autoTagFlag tags the new bookmark with the suggested tags.
+	autoTagFlag bool
+
+	// laterFlag adds the new bookmark to the read-later inbox.
+	laterFlag bool
+
+	// non-interactive add
+	addTagsFlag     string
+	addDescFlag     string
+	addIfExistsFlag string
+	addFromFileFlag string
+	addNoFetchFlag  bool
+)
 
 // newCmd represents the new command.
 var newCmd = &cobra.Command{
-	Use:   "new",
-	Short: "New bookmark, database, backup",
+	Use:     "new",
+	Short:   "New bookmark, database, backup",
+	Aliases: []string{"add"},
 	PreRunE: func(cmd *cobra.Command, _ []string) error {
 		return handler.CheckDBNotEncrypted()
 	},
@@ -50,8 +69,24 @@ var newBookmarkCmd = &cobra.Command{
 	},
 }
 
+// addFlags sets the flags to add a new bookmark.
+func addFlags(cmd *cobra.Command) {
+	f := cmd.Flags()
+	f.StringVar(&titleFlag, "title", "", "new bookmark title")
+	f.BoolVar(&archiveOnAddFlag, "archive", false, "save an offline copy")
+	f.BoolVar(&autoTagFlag, "auto-tag", false, "tag with suggested tags")
+	f.BoolVar(&laterFlag, "later", false, "add to the read-later inbox as unread")
+	f.StringVar(&addTagsFlag, "tags", "", "tags, comma separated (no prompt)")
+	f.StringVar(&addDescFlag, "desc", "", "new bookmark description (no prompt)")
+	f.BoolVar(&addNoFetchFlag, "no-fetch", false, "do not scrape the webpage (no prompt)")
+	f.StringVar(&addIfExistsFlag, "if-exists", handler.IfExistsError, "if bookmarked: skip|update|error (no prompt)")
+	f.StringVarP(&addFromFileFlag, "from-file", "F", "", "add URLs from file, one per line, '-' for stdin")
+	f.BoolVarP(&JSON, "json", "j", false, "output results in JSON format (no prompt)")
+}
+
 func init() {
-	newBookmarkCmd.Flags().StringVar(&titleFlag, "title", "", "new bookmark title")
+	addFlags(newCmd)
+	addFlags(newBookmarkCmd)
 	newCmd.AddCommand(newDatabaseCmd, newBackupCmd, newBookmarkCmd)
 	rootCmd.AddCommand(newCmd)
 }
@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/haaag/gm/internal/config"
+	"github.com/haaag/gm/internal/handler"
+	"github.com/haaag/gm/internal/repo"
+	"github.com/haaag/gm/internal/slice"
+)
+
+// notePrintFlag prints the notes instead of editing them.
+var notePrintFlag bool
+
+// noteCmd edits the Markdown notes of bookmarks.
+var noteCmd = &cobra.Command{
+	Use:   "note <id|query>",
+	Short: "Edit the Markdown notes of a bookmark",
+	Long: `Edit the notes of the bookmarks with the text editor.
+
+Notes are long-form Markdown owned by the user, they are never replaced
+when the page is fetched again and are included in searches.`,
+	Example: `  gm note 42
+  gm note 42 --print`,
+	PreRunE: func(cmd *cobra.Command, _ []string) error {
+		return handler.CheckDBNotEncrypted()
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 0 {
+			return cmd.Usage()
+		}
+		r, err := repo.New(config.App.DBPath)
+		if err != nil {
+			return fmt.Errorf("%w", err)
+		}
+		defer r.Close()
+
+		bs := slice.New[Bookmark]()
+		if err := handler.Records(r, bs, args); err != nil {
+			return fmt.Errorf("%w", err)
+		}
+		if bs.Empty() {
+			return repo.ErrRecordNotFound
+		}
+		if notePrintFlag {
+			return handler.PrintNotes(bs)
+		}
+
+		return handler.EditNotes(r, bs)
+	},
+}
+
+func init() {
+	noteCmd.Flags().BoolVarP(&notePrintFlag, "print", "p", false, "print the notes")
+	rootCmd.AddCommand(noteCmd)
+}
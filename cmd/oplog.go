@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/spf13/cobra"
+
+	"github.com/haaag/gm/internal/config"
+	"github.com/haaag/gm/internal/handler"
+	"github.com/haaag/gm/internal/repo"
+)
+
+// logLimitFlag is the number of operations to show.
+var logLimitFlag int
+
+// oplogSteps returns the number of operations to undo or redo, 1 by default.
+func oplogSteps(args []string) (int, error) {
+	if len(args) == 0 {
+		return 1, nil
+	}
+	n, err := strconv.Atoi(args[0])
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("%w: %q", handler.ErrInvalidOption, args[0])
+	}
+
+	return n, nil
+}
+
+// undoCmd reverts the last operations.
+var undoCmd = &cobra.Command{
+	Use:   "undo [n]",
+	Short: "Undo the last operations",
+	Long: `Undo the last operation that changed the bookmarks, or the last n.
+
+Every change (add, edit, remove, import, tags, state, notes...) is recorded
+in the journal, see 'log'. The number of operations kept is set by
+'journal.depth' in the config file.
+
+Only the bookmarks are journaled: changes to collections and to the content
+index are not recorded, and undoing a drop restores the bookmarks but not
+their collections.`,
+	Example: `  gm undo
+  gm undo 3`,
+	Args: cobra.MaximumNArgs(1),
+	PreRunE: func(cmd *cobra.Command, _ []string) error {
+		return handler.CheckDBNotEncrypted()
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		n, err := oplogSteps(args)
+		if err != nil {
+			return err
+		}
+		r, err := repo.New(config.App.DBPath)
+		if err != nil {
+			return fmt.Errorf("%w", err)
+		}
+		defer r.Close()
+
+		return handler.Undo(r, n)
+	},
+}
+
+// redoCmd applies again the undone operations.
+var redoCmd = &cobra.Command{
+	Use:   "redo [n]",
+	Short: "Redo the last undone operations",
+	Long: `Redo the last undone operation, or the last n.
+
+A new change discards the undone operations.`,
+	Args: cobra.MaximumNArgs(1),
+	PreRunE: func(cmd *cobra.Command, _ []string) error {
+		return handler.CheckDBNotEncrypted()
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		n, err := oplogSteps(args)
+		if err != nil {
+			return err
+		}
+		r, err := repo.New(config.App.DBPath)
+		if err != nil {
+			return fmt.Errorf("%w", err)
+		}
+		defer r.Close()
+
+		return handler.Redo(r, n)
+	},
+}
+
+// logCmd shows the journal of operations.
+var logCmd = &cobra.Command{
+	Use:   "log",
+	Short: "Show the journal of operations",
+	PreRunE: func(cmd *cobra.Command, _ []string) error {
+		return handler.CheckDBNotEncrypted()
+	},
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		r, err := repo.New(config.App.DBPath)
+		if err != nil {
+			return fmt.Errorf("%w", err)
+		}
+		defer r.Close()
+
+		return handler.PrintOplog(r, logLimitFlag, JSON)
+	},
+}
+
+func init() {
+	lf := logCmd.Flags()
+	lf.BoolVarP(&JSON, "json", "j", false, "output in JSON format")
+	lf.IntVar(&logLimitFlag, "limit", 20, "number of operations to show, 0 for all")
+	rootCmd.AddCommand(undoCmd, redoCmd, logCmd)
+}
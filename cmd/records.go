@@ -8,9 +8,31 @@ import (
 	"github.com/haaag/gm/internal/config"
 	"github.com/haaag/gm/internal/handler"
 	"github.com/haaag/gm/internal/repo"
+	"github.com/haaag/gm/internal/sys"
 	"github.com/haaag/gm/internal/sys/terminal"
 )
 
+var (
+	// bulkFlag edits all the bookmarks in a single buffer.
+	bulkFlag bool
+
+	// snippetFlag shows the page content matching the query (fzf preview).
+	snippetFlag string
+
+	// read-later inbox
+	stateFlag  string
+	markFlag   string
+	snoozeFlag string
+
+	// batch field operations
+	addTagFlag    []string
+	removeTagFlag []string
+	setTitleFlag  string
+	setDescFlag   string
+	setFavFlag    bool
+	tagPromptFlag string
+)
+
 // recordsCmd is the main command and entrypoint.
 var recordsCmd = &cobra.Command{
 	Use:     "records",
@@ -33,12 +55,44 @@ var recordsCmd = &cobra.Command{
 		if bs.Empty() {
 			return repo.ErrRecordNotFound
 		}
+		// read-later state
+		switch {
+		case markFlag != "":
+			if err := handler.MarkState(r, bs, markFlag); err != nil {
+				return err
+			}
+		case snoozeFlag != "":
+			if err := handler.Remind(r, bs, snoozeFlag); err != nil {
+				return err
+			}
+		}
+		if (markFlag != "" || snoozeFlag != "") && !Open {
+			return nil
+		}
+		// batch field operations
+		if op := batchOp(cmd); tagPromptFlag != "" || !op.Empty() {
+			t := terminal.New(terminal.WithInterruptFn(func(err error) {
+				r.Close()
+				sys.ErrAndExit(err)
+			}))
+			defer t.CancelInterruptHandler()
+			if tagPromptFlag != "" {
+				op, err = handler.BatchTagPrompt(t, r, bs, tagPromptFlag)
+				if err != nil {
+					return fmt.Errorf("%w", err)
+				}
+			}
+
+			return handler.BatchUpdate(t, r, bs, op)
+		}
 		// actions
 		switch {
 		case Status:
 			return handler.CheckStatus(bs)
 		case Remove:
 			return handler.Remove(r, bs)
+		case bulkFlag:
+			return handler.BulkEdition(r, bs)
 		case Edit:
 			return handler.Edition(r, bs)
 		case Copy:
@@ -56,30 +110,63 @@ var recordsCmd = &cobra.Command{
 			return handler.JSON(bs)
 		case Oneline:
 			return handler.Oneline(bs)
+		case snippetFlag != "":
+			return handler.PrintWithSnippet(r, bs, snippetFlag)
 		default:
 			return handler.Print(bs)
 		}
 	},
 }
 
+// batchOp returns the batch field operations set by the flags.
+func batchOp(cmd *cobra.Command) *handler.BatchOp {
+	op := &handler.BatchOp{AddTags: addTagFlag, RemoveTags: removeTagFlag}
+	f := cmd.Flags()
+	if f.Changed("set-title") {
+		op.Title = &setTitleFlag
+	}
+	if f.Changed("set-desc") {
+		op.Desc = &setDescFlag
+	}
+	if f.Changed("set-fav") {
+		op.Favorite = &setFavFlag
+	}
+
+	return op
+}
+
 func init() {
 	rf := recordsCmd.Flags()
 	rf.BoolVarP(&JSON, "json", "j", false, "output in JSON format")
 	rf.BoolVarP(&Multiline, "multiline", "M", false, "output in formatted multiline (fzf)")
 	rf.BoolVarP(&Oneline, "oneline", "O", false, "output in formatted oneline (fzf)")
-	rf.StringVarP(&Field, "field", "f", "", "output by field [id|url|title|tags]")
+	rf.StringVarP(&Field, "field", "f", "", "output by field [id|url|title|tags|notes]")
 	// Actions
 	rf.BoolVarP(&Copy, "copy", "c", false, "copy bookmark to clipboard")
 	rf.BoolVarP(&Open, "open", "o", false, "open bookmark in default browser")
 	rf.BoolVarP(&QR, "qr", "q", false, "generate qr-code")
 	rf.BoolVarP(&Remove, "remove", "r", false, "remove a bookmarks by query or id")
 	rf.StringSliceVarP(&Tags, "tag", "t", nil, "list by tag")
+	rf.StringVar(&stateFlag, "state", "", "list by read-later state [unread|read|archived]")
+	rf.StringVar(&markFlag, "mark", "", "set read-later state [unread|read|archived|none]")
+	rf.StringVar(&snoozeFlag, "snooze", "", "remind in <duration> or at <date>, hides it from the inbox (3d, 2006-01-02)")
 	// Experimental
 	rf.BoolVarP(&Menu, "menu", "m", false, "menu mode (fzf)")
 	rf.BoolVarP(&Edit, "edit", "e", false, "edit with preferred text editor")
+	rf.BoolVar(&bulkFlag, "bulk", false, "edit all the bookmarks in a single buffer")
 	rf.BoolVarP(&Status, "status", "s", false, "check bookmarks status")
+	// Batch
+	rf.StringSliceVar(&addTagFlag, "add-tag", nil, "add tags to the bookmarks")
+	rf.StringSliceVar(&removeTagFlag, "remove-tag", nil, "remove tags from the bookmarks")
+	rf.StringVar(&setTitleFlag, "set-title", "", "set the title of the bookmarks")
+	rf.StringVar(&setDescFlag, "set-desc", "", "set the description of the bookmarks")
+	rf.BoolVar(&setFavFlag, "set-fav", false, "mark the bookmarks as favorite (--set-fav=false to unmark)")
+	rf.StringVar(&tagPromptFlag, "tag-prompt", "", "prompt for tags to [add|remove]")
+	_ = rf.MarkHidden("tag-prompt")
 	// Modifiers
 	rf.IntVarP(&Head, "head", "H", 0, "the <int> first part of bookmarks")
 	rf.IntVarP(&Tail, "tail", "T", 0, "the <int> last part of bookmarks")
+	rf.StringVar(&snippetFlag, "snippet", "", "show page content matching the query")
+	_ = rf.MarkHidden("snippet")
 	rootCmd.AddCommand(recordsCmd)
 }
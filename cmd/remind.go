@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/haaag/gm/internal/config"
+	"github.com/haaag/gm/internal/handler"
+	"github.com/haaag/gm/internal/repo"
+	"github.com/haaag/gm/internal/slice"
+)
+
+var (
+	// remindClearFlag removes the reminder.
+	remindClearFlag bool
+
+	// dueNotifyFlag sends a desktop notification for each due bookmark.
+	dueNotifyFlag bool
+)
+
+// remindCmd sets a reminder for bookmarks.
+var remindCmd = &cobra.Command{
+	Use:   "remind <id|query> <duration|date>",
+	Short: "Remind a bookmark in a duration or at a date",
+	Long: `Set a reminder for the bookmarks, in a duration like 3d, 12h or 2w, or
+at a date like 2026-11-01 or "2026-11-01 09:00".
+
+Unread bookmarks with a pending reminder are hidden from the inbox.`,
+	Example: `  gm remind 42 3d
+  gm remind 42 2026-11-01
+  gm remind 42 --clear`,
+	PreRunE: func(cmd *cobra.Command, _ []string) error {
+		return handler.CheckDBNotEncrypted()
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		minArgs := 2
+		if remindClearFlag {
+			minArgs = 1
+		}
+		if len(args) < minArgs {
+			return cmd.Usage()
+		}
+		r, err := repo.New(config.App.DBPath)
+		if err != nil {
+			return fmt.Errorf("%w", err)
+		}
+		defer r.Close()
+
+		query := args
+		if !remindClearFlag {
+			query = args[:len(args)-1]
+		}
+		bs := slice.New[Bookmark]()
+		if err := handler.Records(r, bs, query); err != nil {
+			return fmt.Errorf("%w", err)
+		}
+		if bs.Empty() {
+			return repo.ErrRecordNotFound
+		}
+		if remindClearFlag {
+			return handler.ClearRemind(r, bs)
+		}
+
+		return handler.Remind(r, bs, args[len(args)-1])
+	},
+}
+
+// dueCmd lists the bookmarks whose reminder has passed.
+var dueCmd = &cobra.Command{
+	Use:   "due",
+	Short: "List bookmarks whose reminder has passed",
+	PreRunE: func(cmd *cobra.Command, _ []string) error {
+		return handler.CheckDBNotEncrypted()
+	},
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		r, err := repo.New(config.App.DBPath)
+		if err != nil {
+			return fmt.Errorf("%w", err)
+		}
+		defer r.Close()
+
+		bs := slice.New[Bookmark]()
+		if err := handler.Due(r, bs); err != nil {
+			if errors.Is(err, handler.ErrNoDue) && JSON {
+				// status bars expect a list
+				fmt.Println("[]")
+				return nil
+			}
+
+			return fmt.Errorf("%w", err)
+		}
+		if dueNotifyFlag {
+			if err := handler.NotifyDue(bs); err != nil {
+				return fmt.Errorf("%w", err)
+			}
+		}
+		switch {
+		case JSON:
+			return handler.JSON(bs)
+		case Oneline:
+			return handler.Oneline(bs)
+		case Field != "":
+			return handler.ByField(bs, Field)
+		default:
+			return handler.Print(bs)
+		}
+	},
+}
+
+func init() {
+	remindCmd.Flags().BoolVar(&remindClearFlag, "clear", false, "remove the reminder")
+	df := dueCmd.Flags()
+	df.BoolVarP(&JSON, "json", "j", false, "output in JSON format")
+	df.BoolVarP(&Oneline, "oneline", "O", false, "output in formatted oneline (fzf)")
+	df.StringVarP(&Field, "field", "f", "", "output by field [id|url|title|tags|notes]")
+	df.BoolVar(&dueNotifyFlag, "notify", false, "send a desktop notification for each due bookmark")
+	rootCmd.AddCommand(remindCmd, dueCmd)
+}
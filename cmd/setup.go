@@ -15,6 +15,7 @@ import (
 	"github.com/haaag/gm/internal/format"
 	"github.com/haaag/gm/internal/format/color"
 	"github.com/haaag/gm/internal/format/frame"
+	"github.com/haaag/gm/internal/handler"
 	"github.com/haaag/gm/internal/menu"
 	"github.com/haaag/gm/internal/repo"
 	"github.com/haaag/gm/internal/sys"
@@ -64,6 +65,8 @@ func initConfig() {
 	config.SetDBName(files.EnsureExt(DBName, ".db"))
 	// set database path
 	config.SetDBPath(filepath.Join(dataHomePath, config.App.DBName))
+	// encrypted databases are decrypted in memory
+	repo.SetKeyFn(handler.UnlockKey)
 
 	// load config from YAML
 	if err := loadConfig(config.App.Path.ConfigFile); err != nil {
@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/haaag/gm/internal/config"
+	"github.com/haaag/gm/internal/handler"
+	"github.com/haaag/gm/internal/repo"
+	"github.com/haaag/gm/internal/sys"
+	"github.com/haaag/gm/internal/sys/terminal"
+)
+
+var (
+	// archiveFlag looks up an archived copy for dead bookmarks.
+	archiveFlag bool
+
+	// snapshotFlag requests a new archived copy for live bookmarks.
+	snapshotFlag bool
+)
+
+// statusCmd checks the status of the bookmarks.
+var statusCmd = &cobra.Command{
+	Use:     "status",
+	Aliases: []string{"s", "check"},
+	Short:   "Check bookmarks status",
+	PreRunE: func(cmd *cobra.Command, _ []string) error {
+		return handler.CheckDBNotEncrypted()
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		r, err := repo.New(config.App.DBPath)
+		if err != nil {
+			return fmt.Errorf("%w", err)
+		}
+		defer r.Close()
+		terminal.ReadPipedInput(&args)
+		bs, err := handler.Data(cmd, handler.MenuForRecords[Bookmark](cmd), r, args)
+		if err != nil {
+			return fmt.Errorf("%w", err)
+		}
+		if bs.Empty() {
+			return repo.ErrRecordNotFound
+		}
+		if !archiveFlag && !snapshotFlag {
+			return handler.CheckStatus(bs)
+		}
+		t := terminal.New(terminal.WithInterruptFn(func(err error) { sys.ErrAndExit(err) }))
+
+		return handler.CheckStatusArchive(t, r, bs, snapshotFlag)
+	},
+}
+
+func init() {
+	f := statusCmd.Flags()
+	f.BoolVarP(&archiveFlag, "archive", "a", false, "look up archived copies for dead bookmarks")
+	f.BoolVarP(&snapshotFlag, "snapshot", "S", false, "request new archived copies for live bookmarks")
+	f.StringSliceVarP(&Tags, "tag", "t", nil, "filter by tag")
+	f.BoolVarP(&Menu, "menu", "m", false, "menu mode (fzf)")
+	f.BoolVarP(&Multiline, "multiline", "M", false, "menu mode with multiline view (fzf)")
+	f.IntVarP(&Head, "head", "H", 0, "the <int> first part of bookmarks")
+	f.IntVarP(&Tail, "tail", "T", 0, "the <int> last part of bookmarks")
+	rootCmd.AddCommand(statusCmd)
+}
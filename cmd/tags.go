@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/haaag/gm/internal/config"
+	"github.com/haaag/gm/internal/handler"
+	"github.com/haaag/gm/internal/repo"
+	"github.com/haaag/gm/internal/slice"
+	"github.com/haaag/gm/internal/sys"
+	"github.com/haaag/gm/internal/sys/terminal"
+)
+
+// rulesDryRunFlag only reports the changes of the auto-tagging rules.
+var rulesDryRunFlag bool
+
+// tagsCmd represents the tags command.
+var tagsCmd = &cobra.Command{
+	Use:   "tags",
+	Short: "Tags management",
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		return cmd.Usage()
+	},
+}
+
+// tagsApplyRulesCmd applies the auto-tagging rules to existing records.
+var tagsApplyRulesCmd = &cobra.Command{
+	Use:     "apply-rules",
+	Short:   "Apply the auto-tagging rules to records",
+	Aliases: []string{"rules"},
+	PreRunE: func(cmd *cobra.Command, _ []string) error {
+		return handler.CheckDBNotEncrypted()
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		r, err := repo.New(config.App.DBPath)
+		if err != nil {
+			return fmt.Errorf("%w", err)
+		}
+		defer r.Close()
+		t := terminal.New(terminal.WithInterruptFn(func(err error) {
+			r.Close()
+			sys.ErrAndExit(err)
+		}))
+		defer t.CancelInterruptHandler()
+
+		bs := slice.New[Bookmark]()
+		if len(args) == 0 {
+			if err := r.All(bs); err != nil {
+				return fmt.Errorf("%w", err)
+			}
+		} else if err := handler.Records(r, bs, args); err != nil {
+			return fmt.Errorf("%w", err)
+		}
+
+		return handler.ApplyRulesToRecords(t, r, bs, rulesDryRunFlag)
+	},
+}
+
+func init() {
+	tagsApplyRulesCmd.Flags().BoolVar(&rulesDryRunFlag, "dry-run", false, "report changes without updating")
+	tagsCmd.AddCommand(tagsApplyRulesCmd)
+	rootCmd.AddCommand(tagsCmd)
+}
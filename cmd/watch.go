@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/haaag/gm/internal/config"
+	"github.com/haaag/gm/internal/format/color"
+	"github.com/haaag/gm/internal/format/frame"
+	"github.com/haaag/gm/internal/handler"
+	"github.com/haaag/gm/internal/repo"
+	"github.com/haaag/gm/internal/sys/terminal"
+)
+
+// watchOpts holds the options of the clipboard watcher.
+var watchOpts = &handler.WatchOptions{}
+
+// watchCmd represents the watch command.
+var watchCmd = &cobra.Command{
+	Use:   "watch",
+	Short: "Watch sources for new URLs",
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		return cmd.Usage()
+	},
+}
+
+// watchClipboardCmd saves the URLs copied to the clipboard.
+var watchClipboardCmd = &cobra.Command{
+	Use:   "clipboard",
+	Short: "Save URLs copied to the clipboard",
+	Long: `Poll the clipboard and save every new URL copied, tagged with the
+watch tag (default "inbox"), skipping the ones already bookmarked and the
+ignored domains.`,
+	Aliases: []string{"clip"},
+	PreRunE: func(cmd *cobra.Command, _ []string) error {
+		return handler.CheckDBNotEncrypted()
+	},
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		r, err := repo.New(config.App.DBPath)
+		if err != nil {
+			return fmt.Errorf("%w", err)
+		}
+		defer r.Close()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		done := make(chan struct{})
+		t := terminal.New()
+		w := handler.NewClipboardWatcher(t, r, watchOpts)
+		t.SetInterruptFn(func(_ error) {
+			// stop polling and wait for the URL being saved, if any
+			cancel()
+			select {
+			case <-done:
+			case <-time.After(5 * time.Second):
+			}
+			r.Close()
+			os.Exit(0)
+		})
+		defer t.CancelInterruptHandler()
+
+		f := frame.New(frame.WithColorBorder(color.Gray))
+		tag := color.BrightBlue("#" + watchOpts.Tag).Italic().String()
+		f.Header(fmt.Sprintf("watching clipboard, saving URLs to %s (ctrl+c to stop)\n", tag)).Flush()
+
+		err = w.Watch(ctx)
+		handler.PrintWatchStats(&w.Stats)
+		close(done)
+		if err != nil {
+			return fmt.Errorf("%w", err)
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	f := watchClipboardCmd.Flags()
+	f.DurationVar(&watchOpts.Interval, "interval", time.Second, "clipboard polling interval")
+	f.StringVar(&watchOpts.Tag, "tag", "", "tag of the saved URLs (default from config)")
+	f.StringSliceVar(&watchOpts.Ignore, "ignore", nil, "domains to ignore")
+	f.BoolVar(&watchOpts.Prompt, "prompt", false, "ask before saving each URL")
+	f.BoolVar(&watchOpts.Notify, "notify", false, "use desktop notifications (notify-send)")
+	f.BoolVar(&watchOpts.NoFetch, "no-fetch", false, "do not fetch title and description")
+	watchCmd.AddCommand(watchClipboardCmd)
+	rootCmd.AddCommand(watchCmd)
+}
@@ -7,6 +7,7 @@ toolchain go1.24.1
 require github.com/mattn/go-sqlite3 v1.14.28
 
 require (
+	filippo.io/age v1.2.1
 	github.com/PuerkitoBio/goquery v1.10.3
 	github.com/atotto/clipboard v0.1.4
 	github.com/c-bata/go-prompt v0.2.6
@@ -14,12 +15,15 @@ require (
 	github.com/jmoiron/sqlx v1.4.0
 	github.com/junegunn/fzf v0.61.3
 	github.com/junegunn/go-shellwords v0.0.0-20250127100254-2aa3b3277741
+	github.com/klauspost/compress v1.18.0
 	github.com/muesli/go-app-paths v0.2.2
 	github.com/pkg/browser v0.0.0-20240102092130-5ac0b6a4141c
 	github.com/skip2/go-qrcode v0.0.0-20200617195104-da1b6568686e
 	github.com/spf13/cobra v1.9.1
 	github.com/stretchr/testify v1.10.0
+	golang.org/x/crypto v0.37.0
 	golang.org/x/image v0.26.0
+	golang.org/x/net v0.39.0
 	golang.org/x/sync v0.13.0
 	golang.org/x/term v0.31.0
 	gopkg.in/ini.v1 v1.67.0
@@ -43,7 +47,6 @@ require (
 	github.com/pmezard/go-difflib v1.0.0 // indirect
 	github.com/rivo/uniseg v0.4.7 // indirect
 	github.com/spf13/pflag v1.0.6 // indirect
-	golang.org/x/net v0.39.0 // indirect
 	golang.org/x/sys v0.32.0 // indirect
 	golang.org/x/text v0.24.0 // indirect
 )
@@ -0,0 +1,376 @@
+// Package agent holds the keys of the encrypted databases in memory for a
+// session, served over a UNIX socket.
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/haaag/gm/internal/config"
+)
+
+var (
+	ErrNotRunning  = errors.New("agent not running")
+	ErrRunning     = errors.New("agent already running")
+	ErrKeyNotFound = errors.New("key not found in agent")
+	ErrRequest     = errors.New("invalid agent request")
+	ErrInsecure    = errors.New("insecure agent socket")
+)
+
+// Agent operations.
+const (
+	opGet    = "get"
+	opAdd    = "add"
+	opLock   = "lock"
+	opStatus = "status"
+	opStop   = "stop"
+)
+
+// socketPerm restricts the socket to the user.
+const socketPerm = 0o600
+
+// dirPerm restricts the directory of the socket to the user.
+const dirPerm = 0o700
+
+// request is a request to the agent.
+type request struct {
+	Op  string `json:"op"`
+	ID  string `json:"id,omitempty"`
+	Key []byte `json:"key,omitempty"`
+}
+
+// response is the response of the agent.
+type response struct {
+	Key  []byte `json:"key,omitempty"`
+	Keys int    `json:"keys"`
+	TTL  string `json:"ttl,omitempty"`
+	PID  int    `json:"pid,omitempty"`
+	Err  string `json:"err,omitempty"`
+}
+
+// Status is the state of the running agent.
+type Status struct {
+	Socket string `json:"socket"`
+	PID    int    `json:"pid"`
+	Keys   int    `json:"keys"`
+	TTL    string `json:"ttl"`
+}
+
+// SocketPath returns the path of the agent socket, from the environment or
+// in a private directory of the runtime directory of the user, like
+// ssh-agent.
+func SocketPath() string {
+	if p := os.Getenv(config.App.Env.Agent); p != "" {
+		return p
+	}
+	dir := os.Getenv("XDG_RUNTIME_DIR")
+	if dir == "" {
+		dir = os.TempDir()
+	}
+	name := fmt.Sprintf("%s-agent-%d", config.App.Cmd, os.Getuid())
+
+	return filepath.Join(dir, name, "agent.sock")
+}
+
+// socketDir creates the private directory of the socket, if missing, and
+// checks nobody else owns or can access it.
+func socketDir(p string) error {
+	dir := filepath.Dir(p)
+	if err := os.Mkdir(dir, dirPerm); err != nil && !errors.Is(err, fs.ErrExist) {
+		return fmt.Errorf("%w", err)
+	}
+
+	return checkPrivate(dir, fs.ModeDir)
+}
+
+// checkSocket checks the socket and its directory are owned by the user and
+// not accessible by others, before trusting the agent with a key.
+func checkSocket(p string) error {
+	if err := checkPrivate(p, fs.ModeSocket); err != nil {
+		return err
+	}
+
+	return checkPrivate(filepath.Dir(p), fs.ModeDir)
+}
+
+// checkPrivate checks the file at p, without following links, is of the
+// given type, owned by the user and with no permissions for others.
+func checkPrivate(p string, typ fs.FileMode) error {
+	fi, err := os.Lstat(p)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrNotRunning, err)
+	}
+	if fi.Mode().Type() != typ {
+		return fmt.Errorf("%w: %q is not a %s", ErrInsecure, p, typ.Type())
+	}
+	if fi.Mode().Perm()&0o077 != 0 {
+		return fmt.Errorf("%w: %q is accessible by others (%s)", ErrInsecure, p, fi.Mode().Perm())
+	}
+	if st, ok := fi.Sys().(*syscall.Stat_t); ok && int(st.Uid) != os.Getuid() {
+		return fmt.Errorf("%w: %q is owned by uid %d", ErrInsecure, p, st.Uid)
+	}
+
+	return nil
+}
+
+// entry is a key held by the agent.
+type entry struct {
+	key  []byte
+	used time.Time
+}
+
+// Agent holds keys in memory, each one expires after the TTL without use.
+type Agent struct {
+	mu   sync.Mutex
+	keys map[string]*entry
+	ttl  time.Duration
+	stop context.CancelFunc
+}
+
+// New returns an agent whose keys expire after the given inactivity.
+func New(ttl time.Duration) *Agent {
+	return &Agent{
+		keys: make(map[string]*entry),
+		ttl:  ttl,
+	}
+}
+
+// Serve listens on the socket until the context is done or the agent is
+// stopped. The keys are cleared on exit.
+func (a *Agent) Serve(ctx context.Context, p string) error {
+	// the socket is created in a private directory, no one else can
+	// connect to it before its permissions are set.
+	if err := socketDir(p); err != nil {
+		return err
+	}
+	if running(p) {
+		return fmt.Errorf("%w: %q", ErrRunning, p)
+	}
+	// remove the socket left by a crashed agent.
+	_ = os.Remove(p)
+	ln, err := net.Listen("unix", p)
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+	if err := os.Chmod(p, socketPerm); err != nil {
+		_ = ln.Close()
+		return fmt.Errorf("%w", err)
+	}
+	ctx, a.stop = context.WithCancel(ctx)
+	defer a.stop()
+	go func() {
+		<-ctx.Done()
+		_ = ln.Close()
+	}()
+	go a.expireLoop(ctx)
+	slog.Info("agent listening", "socket", p, "ttl", a.ttl)
+
+	var wg sync.WaitGroup
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			if ctx.Err() == nil {
+				slog.Error("agent accept", "error", err)
+			}
+
+			break
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			a.handle(conn)
+		}()
+	}
+	wg.Wait()
+	a.lock()
+	_ = os.Remove(p)
+	slog.Info("agent stopped", "socket", p)
+
+	return nil
+}
+
+// handle answers a single request.
+func (a *Agent) handle(conn net.Conn) {
+	defer conn.Close()
+	_ = conn.SetDeadline(time.Now().Add(5 * time.Second))
+	var req request
+	if err := json.NewDecoder(conn).Decode(&req); err != nil {
+		slog.Warn("agent request", "error", err)
+		return
+	}
+	res := a.do(&req)
+	clear(req.Key)
+	if err := json.NewEncoder(conn).Encode(res); err != nil {
+		slog.Warn("agent response", "error", err)
+	}
+	clear(res.Key)
+}
+
+// do runs the request.
+func (a *Agent) do(req *request) *response {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	res := &response{TTL: a.ttl.String(), PID: os.Getpid()}
+	switch req.Op {
+	case opGet:
+		e, ok := a.keys[req.ID]
+		if !ok {
+			res.Err = ErrKeyNotFound.Error()
+			break
+		}
+		e.used = time.Now()
+		res.Key = append([]byte(nil), e.key...)
+	case opAdd:
+		if req.ID == "" || len(req.Key) == 0 {
+			res.Err = ErrRequest.Error()
+			break
+		}
+		if e, ok := a.keys[req.ID]; ok {
+			clear(e.key)
+		}
+		a.keys[req.ID] = &entry{key: append([]byte(nil), req.Key...), used: time.Now()}
+		slog.Debug("agent key added", "id", req.ID)
+	case opLock:
+		a.clearKeys()
+	case opStatus:
+	case opStop:
+		a.stop()
+	default:
+		res.Err = fmt.Sprintf("%s: %q", ErrRequest, req.Op)
+	}
+	res.Keys = len(a.keys)
+
+	return res
+}
+
+// expireLoop removes the keys not used within the TTL.
+func (a *Agent) expireLoop(ctx context.Context) {
+	t := time.NewTicker(max(a.ttl/10, 100*time.Millisecond))
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-t.C:
+			a.expire(now)
+		}
+	}
+}
+
+// expire removes the keys not used since the TTL.
+func (a *Agent) expire(now time.Time) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for id, e := range a.keys {
+		if now.Sub(e.used) < a.ttl {
+			continue
+		}
+		clear(e.key)
+		delete(a.keys, id)
+		slog.Debug("agent key expired", "id", id)
+	}
+}
+
+// lock removes all the keys.
+func (a *Agent) lock() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.clearKeys()
+}
+
+// clearKeys zeroes and removes all the keys, the lock must be held.
+func (a *Agent) clearKeys() {
+	for id, e := range a.keys {
+		clear(e.key)
+		delete(a.keys, id)
+	}
+	slog.Debug("agent keys cleared")
+}
+
+// Get returns the key with the given ID from the running agent.
+func Get(id string) ([]byte, error) {
+	res, err := call(&request{Op: opGet, ID: id})
+	if err != nil {
+		return nil, err
+	}
+
+	return res.Key, nil
+}
+
+// Add adds the key with the given ID to the running agent.
+func Add(id string, key []byte) error {
+	_, err := call(&request{Op: opAdd, ID: id, Key: key})
+	return err
+}
+
+// Lock removes all the keys from the running agent.
+func Lock() error {
+	_, err := call(&request{Op: opLock})
+	return err
+}
+
+// Stop stops the running agent, clearing its keys.
+func Stop() error {
+	_, err := call(&request{Op: opStop})
+	return err
+}
+
+// State returns the status of the running agent.
+func State() (*Status, error) {
+	res, err := call(&request{Op: opStatus})
+	if err != nil {
+		return nil, err
+	}
+
+	return &Status{Socket: SocketPath(), PID: res.PID, Keys: res.Keys, TTL: res.TTL}, nil
+}
+
+// running reports whether an agent answers on the socket.
+func running(p string) bool {
+	conn, err := net.DialTimeout("unix", p, time.Second)
+	if err != nil {
+		return false
+	}
+	_ = conn.Close()
+
+	return true
+}
+
+// call sends the request to the running agent.
+func call(req *request) (*response, error) {
+	p := SocketPath()
+	if err := checkSocket(p); err != nil {
+		return nil, err
+	}
+	conn, err := net.DialTimeout("unix", p, time.Second)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrNotRunning, err)
+	}
+	defer conn.Close()
+	_ = conn.SetDeadline(time.Now().Add(5 * time.Second))
+	if err := json.NewEncoder(conn).Encode(req); err != nil {
+		return nil, fmt.Errorf("agent: %w", err)
+	}
+	var res response
+	if err := json.NewDecoder(conn).Decode(&res); err != nil {
+		return nil, fmt.Errorf("agent: %w", err)
+	}
+	switch {
+	case res.Err == "":
+		return &res, nil
+	case res.Err == ErrKeyNotFound.Error():
+		return nil, ErrKeyNotFound
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrRequest, res.Err)
+	}
+}
@@ -0,0 +1,104 @@
+package agent
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/haaag/gm/internal/config"
+)
+
+// testAgent starts an agent on a temporary socket.
+func testAgent(t *testing.T, ttl time.Duration) *Agent {
+	t.Helper()
+	// the directory of the socket is created private
+	p := filepath.Join(t.TempDir(), "agent", "agent.sock")
+	t.Setenv(config.App.Env.Agent, p)
+	a := New(ttl)
+	done := make(chan error)
+	go func() { done <- a.Serve(context.Background(), p) }()
+	assert.Eventually(t, func() bool { return running(p) }, time.Second, 10*time.Millisecond)
+	t.Cleanup(func() {
+		_ = Stop()
+		assert.NoError(t, <-done)
+		_, err := os.Stat(p)
+		assert.True(t, os.IsNotExist(err), "socket removed")
+	})
+
+	return a
+}
+
+func TestAgent(t *testing.T) {
+	testAgent(t, time.Minute)
+	fi, err := os.Stat(SocketPath())
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(socketPerm), fi.Mode().Perm())
+
+	_, err = Get("id")
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+	assert.NoError(t, Add("id", []byte("secret")))
+	k, err := Get("id")
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("secret"), k)
+	assert.ErrorIs(t, Add("", []byte("secret")), ErrRequest)
+
+	s, err := State()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, s.Keys)
+	assert.Equal(t, os.Getpid(), s.PID)
+
+	assert.NoError(t, Lock())
+	_, err = Get("id")
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+
+	// a second agent on the same socket
+	err = New(time.Minute).Serve(context.Background(), SocketPath())
+	assert.ErrorIs(t, err, ErrRunning)
+}
+
+func TestAgentExpire(t *testing.T) {
+	a := testAgent(t, time.Minute)
+	assert.NoError(t, Add("id", []byte("secret")))
+	a.mu.Lock()
+	key := a.keys["id"].key
+	a.mu.Unlock()
+
+	a.expire(time.Now().Add(30 * time.Second))
+	_, err := Get("id")
+	assert.NoError(t, err, "used within the ttl")
+	a.expire(time.Now().Add(2 * time.Minute))
+	_, err = Get("id")
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+	assert.Equal(t, make([]byte, len(key)), key, "key zeroed")
+}
+
+func TestAgentNotRunning(t *testing.T) {
+	t.Setenv(config.App.Env.Agent, filepath.Join(t.TempDir(), "agent.sock"))
+	_, err := Get("id")
+	assert.ErrorIs(t, err, ErrNotRunning)
+}
+
+func TestAgentInsecure(t *testing.T) {
+	// a directory others can access
+	dir := filepath.Join(t.TempDir(), "agent")
+	assert.NoError(t, os.Mkdir(dir, 0o755))
+	assert.NoError(t, os.Chmod(dir, 0o755))
+	err := New(time.Minute).Serve(context.Background(), filepath.Join(dir, "agent.sock"))
+	assert.ErrorIs(t, err, ErrInsecure)
+
+	// a socket others can connect to
+	assert.NoError(t, os.Chmod(dir, dirPerm))
+	p := filepath.Join(dir, "agent.sock")
+	ln, err := net.Listen("unix", p)
+	assert.NoError(t, err)
+	defer ln.Close()
+	assert.NoError(t, os.Chmod(p, 0o666))
+	t.Setenv(config.App.Env.Agent, p)
+	_, err = Get("id")
+	assert.ErrorIs(t, err, ErrInsecure)
+}
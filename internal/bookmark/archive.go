@@ -0,0 +1,62 @@
+package bookmark
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/haaag/gm/internal/bookmark/wayback"
+	"github.com/haaag/gm/internal/slice"
+)
+
+// maxConArchive is the maximum number of concurrent requests to the archive,
+// kept low to be gentle with the service.
+const maxConArchive = 5
+
+// Archived holds the result of an archive lookup or snapshot request.
+type Archived struct {
+	Bookmark  *Bookmark // Bookmark looked up
+	URL       string    // URL of the archived copy
+	Timestamp string    // Timestamp of the archived copy
+	Found     bool      // Archived copy found
+}
+
+// ClosestSnapshots looks up the closest archived copy for each bookmark.
+func ClosestSnapshots(bs *slice.Slice[Bookmark], wb *wayback.Wayback) (*slice.Slice[Archived], error) {
+	return concurrent(bs, maxConArchive, func(ctx context.Context, b *Bookmark) Archived {
+		ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+		defer cancel()
+
+		res := Archived{Bookmark: b}
+		s, err := wb.Closest(ctx, b.URL)
+		if err != nil {
+			slog.Warn("closest snapshot", "url", b.URL, "error", err)
+			return res
+		}
+		res.URL = s.URL
+		res.Timestamp = s.Timestamp
+		res.Found = true
+
+		return res
+	})
+}
+
+// SaveSnapshots requests a new archived copy for each bookmark.
+func SaveSnapshots(bs *slice.Slice[Bookmark], wb *wayback.Wayback) (*slice.Slice[Archived], error) {
+	return concurrent(bs, maxConArchive, func(ctx context.Context, b *Bookmark) Archived {
+		ctx, cancel := context.WithTimeout(ctx, 2*time.Minute)
+		defer cancel()
+
+		res := Archived{Bookmark: b}
+		u, err := wb.Save(ctx, b.URL)
+		if err != nil {
+			slog.Warn("saving snapshot", "url", b.URL, "error", err)
+			return res
+		}
+		res.URL = u
+		res.Timestamp = time.Now().UTC().Format("20060102150405")
+		res.Found = true
+
+		return res
+	})
+}
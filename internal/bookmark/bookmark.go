@@ -21,17 +21,25 @@ var (
 
 // Bookmark represents a bookmark.
 type Bookmark struct {
-	URL        string `db:"url"         json:"url"         yaml:"url"`
-	Tags       string `db:"tags"        json:"tags"        yaml:"-"`
-	Title      string `db:"title"       json:"title"       yaml:"title"`
-	Desc       string `db:"desc"        json:"desc"        yaml:"desc"`
-	ID         int    `db:"id"          json:"id"          yaml:"id"`
-	CreatedAt  string `db:"created_at"  json:"created_at"  yaml:"created_at"`
-	LastVisit  string `db:"last_visit"  json:"last_visit"  yaml:"last_visit"`
-	UpdatedAt  string `db:"updated_at"  json:"updated_at"  yaml:"updated_at"`
-	VisitCount int    `db:"visit_count" json:"visit_count" yaml:"visit_count"`
-	Favorite   bool   `db:"favorite"    json:"favorite"    yaml:"favorite"`
-	Checksum   string `db:"-"           json:"checksum"    yaml:"checksum"`
+	URL          string   `db:"url"           json:"url"           yaml:"url"`
+	Tags         string   `db:"tags"          json:"tags"          yaml:"-"`
+	Title        string   `db:"title"         json:"title"         yaml:"title"`
+	Desc         string   `db:"desc"          json:"desc"          yaml:"desc"`
+	ID           int      `db:"id"            json:"id"            yaml:"id"`
+	CreatedAt    string   `db:"created_at"    json:"created_at"    yaml:"created_at"`
+	LastVisit    string   `db:"last_visit"    json:"last_visit"    yaml:"last_visit"`
+	UpdatedAt    string   `db:"updated_at"    json:"updated_at"    yaml:"updated_at"`
+	VisitCount   int      `db:"visit_count"   json:"visit_count"   yaml:"visit_count"`
+	Favorite     bool     `db:"favorite"      json:"favorite"      yaml:"favorite"`
+	ArchiveURL   string   `db:"archive_url"   json:"archive_url"   yaml:"archive_url"`
+	ArchiveHash  string   `db:"archive_hash"  json:"archive_hash"  yaml:"archive_hash"`
+	CanonicalURL string   `db:"canonical_url" json:"canonical_url" yaml:"canonical_url"`
+	Meta         Metadata `db:"meta"          json:"meta"          yaml:"meta"`
+	State        string   `db:"state"         json:"state"         yaml:"state"`
+	StateAt      string   `db:"state_at"      json:"state_at"      yaml:"state_at"`
+	RemindAt     string   `db:"remind_at"     json:"remind_at"     yaml:"remind_at"`
+	Notes        string   `db:"notes"         json:"notes"         yaml:"notes"`
+	Checksum     string   `db:"-"             json:"checksum"      yaml:"checksum"`
 }
 
 // Field returns the value of a field.
@@ -54,6 +62,12 @@ func (b *Bookmark) Field(f string) (string, error) {
 	case "desc", "d", "5":
 		field = "desc"
 		s = b.Desc
+	case "archive", "a", "6":
+		field = "archive"
+		s = b.ArchiveURL
+	case "notes", "n", "7":
+		field = "notes"
+		s = b.Notes
 	default:
 		return "", fmt.Errorf("%w: %q", ErrUnknownField, f)
 	}
@@ -63,7 +77,8 @@ func (b *Bookmark) Field(f string) (string, error) {
 	return s, nil
 }
 
-// Equals reports whether b and o have the same URL, Tags, Title and Desc.
+// Equals reports whether b and o have the same URL, Tags, Title, Desc and
+// Notes.
 func (b *Bookmark) Equals(o *Bookmark) bool {
 	if b == nil || o == nil {
 		return b == o
@@ -72,7 +87,8 @@ func (b *Bookmark) Equals(o *Bookmark) bool {
 	return b.URL == o.URL &&
 		b.Tags == o.Tags &&
 		b.Title == o.Title &&
-		b.Desc == o.Desc
+		b.Desc == o.Desc &&
+		b.Notes == o.Notes
 }
 
 func (b *Bookmark) Buffer() []byte {
@@ -84,9 +100,11 @@ func (b *Bookmark) Buffer() []byte {
 %s
 # Description:
 %s
+# Notes: (markdown)
+%s
 
 # end ------------------------------------------------------------------`,
-		b.URL, b.Title, ParseTags(b.Tags), b.Desc)
+		b.URL, b.Title, ParseTags(b.Tags), b.Desc, b.Notes)
 }
 
 // New creates a new bookmark.
@@ -0,0 +1,171 @@
+package bookmark
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/haaag/gm/internal/config"
+	"github.com/haaag/gm/internal/format"
+)
+
+var (
+	// ErrBulkAnchor is returned when a record anchor is invalid.
+	ErrBulkAnchor = errors.New("invalid record anchor, expected `@@ <id> @@` or `@@ new @@`")
+	// ErrBulkDuplicate is returned when a record appears twice.
+	ErrBulkDuplicate = errors.New("duplicate record")
+)
+
+// bulkNew is the anchor of the records to create.
+const bulkNew = "new"
+
+// bulkErrPrefix marks the error lines added to the buffer.
+const bulkErrPrefix = "# error: "
+
+// bulkAnchorRe matches the line that starts a record in the bulk buffer.
+var bulkAnchorRe = regexp.MustCompile(`^@@ (\S+) @@\s*$`)
+
+// BulkRecord is a record parsed from the bulk buffer.
+type BulkRecord struct {
+	ID   int       // ID of the bookmark, 0 for new records
+	Line int       // line of the record anchor, starting at 1
+	B    *Bookmark // parsed bookmark
+}
+
+// BulkError is a parse error of a record in the bulk buffer.
+type BulkError struct {
+	Line int // line of the record anchor, starting at 1
+	Err  error
+}
+
+func (e *BulkError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+func (e *BulkError) Unwrap() error {
+	return e.Err
+}
+
+// BulkBuffer writes the bookmarks into a single buffer, each record starts
+// with its ID as anchor.
+func BulkBuffer(bs []Bookmark) []byte {
+	buf := fmt.Appendf(nil, `# %s: bulk edition of %d bookmarks
+#
+# Keep the '@@ <id> @@' line of a record to update the bookmark, remove the
+# whole record to delete it, or add a record starting with '@@ new @@' to
+# create one.
+`, config.App.Name, len(bs))
+
+	return append(buf, BulkRecords(bs)...)
+}
+
+// BulkRecords writes the records of the bulk buffer, without the header.
+//
+// Bookmarks without ID are anchored as new records.
+func BulkRecords(bs []Bookmark) []byte {
+	var buf []byte
+	for _, b := range bs {
+		id := bulkNew
+		if b.ID != 0 {
+			id = strconv.Itoa(b.ID)
+		}
+		buf = fmt.Appendf(buf, "\n@@ %s @@\n", id)
+		buf = append(buf, b.Buffer()...)
+		buf = append(buf, '\n')
+	}
+
+	return buf
+}
+
+// ParseBulk parses the records of the bulk buffer.
+//
+// The text before the first anchor is ignored.
+func ParseBulk(data []byte) ([]BulkRecord, error) {
+	lines := format.ByteSliceToLines(data)
+	records := make([]BulkRecord, 0)
+	seen := make(map[int]bool)
+	start := -1
+	flush := func(end int) error {
+		if start == -1 {
+			return nil
+		}
+		rec, err := parseBulkRecord(lines[start], lines[start+1:end])
+		if err != nil {
+			return &BulkError{Line: start + 1, Err: err}
+		}
+		if rec.ID != 0 {
+			if seen[rec.ID] {
+				return &BulkError{Line: start + 1, Err: fmt.Errorf("%w: %d", ErrBulkDuplicate, rec.ID)}
+			}
+			seen[rec.ID] = true
+		}
+		rec.Line = start + 1
+		records = append(records, rec)
+
+		return nil
+	}
+	for i, l := range lines {
+		if !strings.HasPrefix(l, "@@") {
+			continue
+		}
+		if err := flush(i); err != nil {
+			return nil, err
+		}
+		start = i
+	}
+	if err := flush(len(lines)); err != nil {
+		return nil, err
+	}
+
+	return records, nil
+}
+
+// parseBulkRecord parses a single record, from its anchor and content.
+func parseBulkRecord(anchor string, lines []string) (BulkRecord, error) {
+	var rec BulkRecord
+	m := bulkAnchorRe.FindStringSubmatch(anchor)
+	if m == nil {
+		return rec, fmt.Errorf("%w: %q", ErrBulkAnchor, anchor)
+	}
+	if m[1] != bulkNew {
+		id, err := strconv.Atoi(m[1])
+		if err != nil || id <= 0 {
+			return rec, fmt.Errorf("%w: %q", ErrBulkAnchor, anchor)
+		}
+		rec.ID = id
+	}
+	if err := validateBookmarkFormat(lines); err != nil {
+		return rec, err
+	}
+	if !hasMarker(lines, "# end") {
+		return rec, fmt.Errorf("%w: end", ErrLineNotFound)
+	}
+	rec.B = parseBookmarkContent(lines)
+	rec.B.ID = rec.ID
+
+	return rec, nil
+}
+
+// MarkBulkError adds the error as a comment above the line, removing the
+// errors of a previous attempt.
+//
+// Returns the new buffer and the line of the error comment.
+func MarkBulkError(data []byte, line int, err error) ([]byte, int) {
+	lines := format.ByteSliceToLines(data)
+	out := make([]string, 0, len(lines)+1)
+	at := 0
+	for i, l := range lines {
+		if i == line-1 {
+			out = append(out, bulkErrPrefix+err.Error())
+			at = len(out)
+		}
+		if strings.HasPrefix(l, bulkErrPrefix) {
+			continue
+		}
+		out = append(out, l)
+	}
+
+	return []byte(strings.Join(out, "\n")), at
+}
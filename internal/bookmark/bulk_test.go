@@ -0,0 +1,67 @@
+package bookmark
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseBulk(t *testing.T) {
+	t.Parallel()
+	a := testSingleBookmark()
+	a.ID, a.Tags = 3, ParseTags(a.Tags)
+	b := testSingleBookmark()
+	b.ID, b.URL, b.Tags, b.Notes = 7, "https://example.org", "go,", "## todo\n\n# end of notes"
+	buf := BulkBuffer([]Bookmark{*a, *b})
+	buf = append(buf, BulkRecords([]Bookmark{{URL: "https://new.org", Tags: "new"}})...)
+
+	records, err := ParseBulk(buf)
+	assert.NoError(t, err)
+	assert.Len(t, records, 3)
+	assert.Equal(t, 3, records[0].ID)
+	assert.True(t, a.Equals(records[0].B))
+	assert.Equal(t, 7, records[1].ID)
+	assert.True(t, b.Equals(records[1].B))
+	assert.Equal(t, 0, records[2].ID)
+	assert.Equal(t, "https://new.org", records[2].B.URL)
+	lines := strings.Split(string(buf), "\n")
+	assert.Equal(t, "@@ 7 @@", lines[records[1].Line-1])
+}
+
+func TestParseBulkErrors(t *testing.T) {
+	t.Parallel()
+	a := testSingleBookmark()
+	a.ID = 3
+	tests := []struct {
+		name string
+		buf  string
+		line int
+		err  error
+	}{
+		{"invalid anchor", "# header\n@@ x @@\n" + string(a.Buffer()), 2, ErrBulkAnchor},
+		{"missing url", "@@ 3 @@\n# URL:\n# Title:\n# Tags:\ngo\n# Description:\n# end", 1, ErrLineNotFound},
+		{"missing end", "@@ 3 @@\n# URL:\nhttps://a.org\n# Title:\n# Tags:\ngo\n# Description:", 1, ErrLineNotFound},
+		{"duplicate", "@@ 3 @@\n" + string(a.Buffer()) + "\n@@ 3 @@\n" + string(a.Buffer()), 14, ErrBulkDuplicate},
+	}
+	for _, tt := range tests {
+		_, err := ParseBulk([]byte(tt.buf))
+		var be *BulkError
+		assert.ErrorAs(t, err, &be, tt.name)
+		assert.ErrorIs(t, err, tt.err, tt.name)
+		assert.Equal(t, tt.line, be.Line, tt.name)
+	}
+}
+
+func TestMarkBulkError(t *testing.T) {
+	t.Parallel()
+	data := []byte("# header\n@@ 1 @@\nfoo\n@@ 2 @@\nbar")
+	out, line := MarkBulkError(data, 4, ErrBulkAnchor)
+	assert.Equal(t, 4, line)
+	assert.Equal(t, "# error: "+ErrBulkAnchor.Error(), strings.Split(string(out), "\n")[3])
+
+	// the previous error is replaced
+	out, line = MarkBulkError(out, 2, ErrLineNotFound)
+	assert.Equal(t, 2, line)
+	assert.Equal(t, "# header\n# error: line not found\n@@ 1 @@\nfoo\n@@ 2 @@\nbar", string(out))
+}
@@ -0,0 +1,204 @@
+// Package canonical normalizes URLs so equivalent URLs compare equal.
+package canonical
+
+import (
+	"net"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// DefaultStripParams are the tracking query parameters removed by default.
+//
+// A trailing `*` matches any parameter with that prefix.
+var DefaultStripParams = []string{"utm_*", "fbclid", "gclid"}
+
+// defaultPorts maps schemes to their default port.
+var defaultPorts = map[string]string{
+	"http":  "80",
+	"https": "443",
+}
+
+type (
+	OptFn func(*Options)
+
+	Options struct {
+		stripParams  []string
+		keepFragment []string
+	}
+
+	// Canonicalizer normalizes URLs.
+	Canonicalizer struct {
+		Options
+	}
+)
+
+func defaults() *Options {
+	return &Options{
+		stripParams: DefaultStripParams,
+	}
+}
+
+// WithStripParams sets the query parameters to remove.
+func WithStripParams(params ...string) OptFn {
+	return func(o *Options) {
+		o.stripParams = params
+	}
+}
+
+// WithKeepFragment sets the domains whose URL fragments are kept.
+func WithKeepFragment(domains ...string) OptFn {
+	return func(o *Options) {
+		o.keepFragment = domains
+	}
+}
+
+// New creates a new Canonicalizer.
+func New(opts ...OptFn) *Canonicalizer {
+	o := defaults()
+	for _, fn := range opts {
+		fn(o)
+	}
+
+	return &Canonicalizer{
+		Options: *o,
+	}
+}
+
+// URL returns the canonical form of the URL, used as the key to compare
+// URLs. It is not meant to be stored, the path and query keep their
+// encoding but their meaning may not be preserved.
+//
+//   - lowercases the scheme and host
+//   - drops the default port
+//   - strips the tracking query parameters and sorts the rest
+//   - removes the fragment, unless it is a client-side route (`#/`, `#!`)
+//     or the domain keeps fragments
+//   - removes the trailing slash
+//
+// If the URL can not be parsed, returns it unchanged.
+func (c *Canonicalizer) URL(s string) string {
+	u, ok := parse(s)
+	if !ok {
+		return strings.TrimSpace(s)
+	}
+	u.RawQuery = c.query(u.RawQuery, true)
+	u.ForceQuery = false
+	if !c.keepFragmentOf(u) {
+		u.Fragment, u.RawFragment = "", ""
+	}
+	p := strings.TrimRight(u.EscapedPath(), "/")
+	if unescaped, err := url.PathUnescape(p); err == nil {
+		u.Path, u.RawPath = unescaped, p
+	}
+
+	return u.String()
+}
+
+// Clean returns the URL as given, only with the changes that keep its
+// meaning.
+//
+//   - lowercases the scheme and host
+//   - drops the default port
+//   - strips the tracking query parameters
+//
+// If the URL can not be parsed, returns it unchanged.
+func (c *Canonicalizer) Clean(s string) string {
+	u, ok := parse(s)
+	if !ok {
+		return strings.TrimSpace(s)
+	}
+	if u.RawQuery != "" {
+		u.RawQuery = c.query(u.RawQuery, false)
+	}
+
+	return u.String()
+}
+
+// Equal checks if two URLs have the same canonical form.
+func (c *Canonicalizer) Equal(a, b string) bool {
+	return c.URL(a) == c.URL(b)
+}
+
+// parse parses the URL, lowercasing the scheme and host and dropping the
+// default port.
+func parse(s string) (*url.URL, bool) {
+	u, err := url.Parse(strings.TrimSpace(s))
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return nil, false
+	}
+	u.Scheme = strings.ToLower(u.Scheme)
+	host := strings.ToLower(u.Hostname())
+	if port := u.Port(); port != "" && port != defaultPorts[u.Scheme] {
+		host = net.JoinHostPort(host, port)
+	} else if strings.Contains(host, ":") {
+		host = "[" + host + "]"
+	}
+	u.Host = host
+
+	return u, true
+}
+
+// query removes the tracking parameters from the raw query, optionally
+// sorting the rest.
+//
+// The pairs are kept as written, so a key without value or a pair with `;`
+// are not changed.
+func (c *Canonicalizer) query(raw string, sorted bool) string {
+	pairs := strings.Split(raw, "&")
+	kept := make([]string, 0, len(pairs))
+	for _, p := range pairs {
+		if p == "" && sorted {
+			continue
+		}
+		k, _, _ := strings.Cut(p, "=")
+		if key, err := url.QueryUnescape(k); err == nil {
+			k = key
+		}
+		if k != "" && c.strip(k) {
+			continue
+		}
+		kept = append(kept, p)
+	}
+	if sorted {
+		sort.Strings(kept)
+	}
+
+	return strings.Join(kept, "&")
+}
+
+// strip checks if the query parameter must be removed.
+func (c *Canonicalizer) strip(k string) bool {
+	k = strings.ToLower(k)
+	for _, p := range c.stripParams {
+		p = strings.ToLower(p)
+		if prefix, ok := strings.CutSuffix(p, "*"); ok {
+			if strings.HasPrefix(k, prefix) {
+				return true
+			}
+
+			continue
+		}
+		if k == p {
+			return true
+		}
+	}
+
+	return false
+}
+
+// keepFragmentOf checks if the fragment of the URL must be kept.
+func (c *Canonicalizer) keepFragmentOf(u *url.URL) bool {
+	if strings.HasPrefix(u.Fragment, "/") || strings.HasPrefix(u.Fragment, "!") {
+		return true
+	}
+	host := strings.TrimPrefix(u.Hostname(), "www.")
+	for _, d := range c.keepFragment {
+		d = strings.ToLower(strings.TrimPrefix(d, "www."))
+		if host == d || strings.HasSuffix(host, "."+d) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,78 @@
+package canonical
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestURL(t *testing.T) {
+	t.Parallel()
+	c := New()
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"tracking params and fragment", "https://Example.com/a?utm_source=x#top", "https://example.com/a"},
+		{"default port", "https://example.com:443/a", "https://example.com/a"},
+		{"non default port", "http://example.com:8080/a", "http://example.com:8080/a"},
+		{"sorted query", "https://example.com/s?b=2&a=1&fbclid=z", "https://example.com/s?a=1&b=2"},
+		{"trailing slash", "https://example.com/", "https://example.com"},
+		{"path trailing slash", "https://example.com/docs/", "https://example.com/docs"},
+		{"client route fragment", "https://app.example.com/#/inbox", "https://app.example.com#/inbox"},
+		{"uppercase tracking param", "https://example.com/?GCLID=1&q=go", "https://example.com?q=go"},
+		{"invalid", "not a url", "not a url"},
+		{"encoded slash", "https://gitlab.com/api/v4/projects/group%2Fproj", "https://gitlab.com/api/v4/projects/group%2Fproj"},
+		{"key without value", "https://example.com/w?edit", "https://example.com/w?edit"},
+		{"semicolon pairs", "https://example.com/q?a=1;b=2", "https://example.com/q?a=1;b=2"},
+		{"dot segments", "https://example.com/a/../b", "https://example.com/a/../b"},
+		{"encoded tracking param", "https://example.com/?utm%5Fsource=x&q=go", "https://example.com?q=go"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.want, c.URL(tt.in))
+		})
+	}
+}
+
+func TestClean(t *testing.T) {
+	t.Parallel()
+	c := New()
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"host case", "https://Example.COM/Docs", "https://example.com/Docs"},
+		{"default port", "http://example.com:80/a", "http://example.com/a"},
+		{"non default port", "https://example.com:8443/a", "https://example.com:8443/a"},
+		{"tracking params", "https://example.com/s?b=2&utm_source=x&a=1", "https://example.com/s?b=2&a=1"},
+		{"only tracking params", "https://example.com/s?fbclid=z", "https://example.com/s"},
+		{"fragment and trailing slash", "https://example.com/docs/#intro", "https://example.com/docs/#intro"},
+		{"encoded slash", "https://gitlab.com/api/v4/projects/group%2Fproj", "https://gitlab.com/api/v4/projects/group%2Fproj"},
+		{"key without value", "https://example.com/w?edit", "https://example.com/w?edit"},
+		{"semicolon pairs", "https://example.com/q?a=1;b=2", "https://example.com/q?a=1;b=2"},
+		{"dot segments", "https://example.com/a/../b", "https://example.com/a/../b"},
+		{"invalid", "not a url", "not a url"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.want, c.Clean(tt.in))
+		})
+	}
+}
+
+func TestOptions(t *testing.T) {
+	t.Parallel()
+	c := New(WithStripParams("ref", "mc_*"), WithKeepFragment("docs.example.com"))
+	assert.Equal(t,
+		"https://example.com/a?utm_source=x",
+		c.URL("https://example.com/a?ref=home&mc_cid=1&utm_source=x"),
+	)
+	assert.Equal(t, "https://docs.example.com/api#section", c.URL("https://docs.example.com/api#section"))
+	assert.Equal(t, "https://example.com/api", c.URL("https://example.com/api#section"))
+	assert.True(t, c.Equal("https://EXAMPLE.com/api/", "https://example.com/api#x"))
+}
@@ -0,0 +1,49 @@
+package bookmark
+
+import "strconv"
+
+// FieldChange is the change of a field of a bookmark.
+type FieldChange struct {
+	Field string `json:"field"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+}
+
+// Diff returns the fields changed from a to b, in order. A nil bookmark has
+// all its fields empty, so the diff of a new bookmark has all its fields.
+func Diff(a, b *Bookmark) []FieldChange {
+	fa, fb := a.historyFields(), b.historyFields()
+	var changes []FieldChange
+	for i := range fa {
+		if fa[i][1] != fb[i][1] {
+			changes = append(changes, FieldChange{Field: fa[i][0], Old: fa[i][1], New: fb[i][1]})
+		}
+	}
+
+	return changes
+}
+
+// historyFields returns the name and value of the fields tracked by the
+// history.
+func (b *Bookmark) historyFields() [][2]string {
+	if b == nil {
+		b = &Bookmark{}
+	}
+	fav := ""
+	if b.Favorite {
+		fav = strconv.FormatBool(b.Favorite)
+	}
+
+	return [][2]string{
+		{"url", b.URL},
+		{"title", b.Title},
+		{"tags", b.Tags},
+		{"desc", b.Desc},
+		{"notes", b.Notes},
+		{"favorite", fav},
+		{"state", b.State},
+		{"remind_at", b.RemindAt},
+		{"archive_url", b.ArchiveURL},
+		{"canonical_url", b.CanonicalURL},
+	}
+}
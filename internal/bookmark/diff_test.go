@@ -0,0 +1,25 @@
+package bookmark
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiff(t *testing.T) {
+	t.Parallel()
+	a := &Bookmark{URL: "https://a.org", Title: "A", Tags: "go,"}
+	b := &Bookmark{URL: "https://a.org", Title: "B", Tags: "go,", Favorite: true}
+	assert.Equal(t, []FieldChange{
+		{Field: "title", Old: "A", New: "B"},
+		{Field: "favorite", Old: "", New: "true"},
+	}, Diff(a, b))
+	assert.Empty(t, Diff(a, a))
+	// a new bookmark has all its fields
+	assert.Equal(t, []FieldChange{
+		{Field: "url", Old: "", New: "https://a.org"},
+		{Field: "title", Old: "", New: "A"},
+		{Field: "tags", Old: "", New: "go,"},
+	}, Diff(nil, a))
+	assert.Len(t, Diff(a, nil), 3)
+}
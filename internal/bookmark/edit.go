@@ -36,11 +36,7 @@ func Edit(te *files.TextEditor, content []byte, b *Bookmark) error {
 		return ErrBufferUnchanged
 	}
 	tb = scrapeBookmark(tb)
-	tb.ID = b.ID
-	tb.CreatedAt = b.CreatedAt
-	tb.Favorite = b.Favorite
-	tb.LastVisit = b.LastVisit
-	tb.VisitCount = b.VisitCount
+	tb.CopyUnedited(b)
 
 	f := frame.New(frame.WithColorBorder(color.BrightBlue))
 	f.Header(color.BrightYellow("Edit Bookmark:\n\n").String()).Flush()
@@ -56,3 +52,22 @@ func Edit(te *files.TextEditor, content []byte, b *Bookmark) error {
 
 	return nil
 }
+
+// CopyUnedited copies from o the fields that are not part of the edit
+// buffer.
+func (b *Bookmark) CopyUnedited(o *Bookmark) {
+	b.ID = o.ID
+	b.CreatedAt = o.CreatedAt
+	b.Favorite = o.Favorite
+	b.LastVisit = o.LastVisit
+	b.VisitCount = o.VisitCount
+	b.ArchiveURL = o.ArchiveURL
+	b.ArchiveHash = o.ArchiveHash
+	b.State = o.State
+	b.StateAt = o.StateAt
+	b.RemindAt = o.RemindAt
+	if b.URL == o.URL && b.Meta.Empty() {
+		b.CanonicalURL = o.CanonicalURL
+		b.Meta = o.Meta
+	}
+}
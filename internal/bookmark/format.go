@@ -3,6 +3,7 @@ package bookmark
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/haaag/gm/internal/format"
 	"github.com/haaag/gm/internal/format/color"
@@ -105,6 +106,48 @@ func Frame(b *Bookmark, cs *color.Scheme) string {
 		desc := color.ApplyMany(descSplit, cs.White)
 		f.Mid(desc...).Ln()
 	}
+	// page metadata
+	if s := b.Meta.Summary(); s != "" {
+		f.Mid(cs.BrightBlack(format.Shorten(s, w)).Italic().String()).Ln()
+	}
+	if b.Meta.Keywords != "" {
+		kw := strings.ReplaceAll(b.Meta.Keywords, ",", ", ")
+		f.Mid(cs.BrightBlack(format.Shorten(kw, w)).Italic().String()).Ln()
+	}
+	if b.CanonicalURL != "" && b.CanonicalURL != b.URL {
+		f.Mid(cs.BrightBlack(format.Shorten(b.CanonicalURL, w)).Italic().String()).Ln()
+	}
+	// archived copy
+	if b.ArchiveURL != "" {
+		f.Mid(cs.BrightBlack(format.Shorten(b.ArchiveURL, w)).Italic().String()).Ln()
+	}
+	// read-later state and reminder
+	status := make([]string, 0, 2)
+	if b.State != StateNone {
+		status = append(status, b.State)
+	}
+	if t, ok := b.remindTime(); ok {
+		when := "remind " + t.Local().Format("2006-01-02 15:04")
+		if !t.After(time.Now()) {
+			when += " (due)"
+		}
+		status = append(status, when)
+	}
+	if len(status) > 0 {
+		f.Mid(cs.BrightBlack(strings.Join(status, " · ")).Italic().String()).Ln()
+	}
+	// notes
+	if b.Notes != "" {
+		notes := make([]string, 0)
+		for _, l := range strings.Split(b.Notes, "\n") {
+			if strings.TrimSpace(l) == "" {
+				notes = append(notes, "")
+				continue
+			}
+			notes = append(notes, format.SplitIntoChunks(l, w)...)
+		}
+		f.Mid(color.ApplyMany(notes, cs.White)...).Ln()
+	}
 	// tags
 	tags := cs.BrightWhite(format.TagsWithPound(b.Tags)).Italic().String()
 	f.Footer(tags).Ln()
@@ -0,0 +1,99 @@
+package bookmark
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/haaag/gm/internal/bookmark/scraper"
+	"github.com/haaag/gm/internal/format"
+)
+
+// Metadata holds the page metadata extracted by the scraper.
+//
+// It is stored as JSON in a single column.
+type Metadata struct {
+	SiteName  string `json:"site_name,omitempty" yaml:"site_name,omitempty"`
+	Image     string `json:"image,omitempty"     yaml:"image,omitempty"`
+	Published string `json:"published,omitempty" yaml:"published,omitempty"`
+	Favicon   string `json:"favicon,omitempty"   yaml:"favicon,omitempty"`
+	Keywords  string `json:"keywords,omitempty"  yaml:"keywords,omitempty"`
+	Author    string `json:"author,omitempty"    yaml:"author,omitempty"`
+	Lang      string `json:"lang,omitempty"      yaml:"lang,omitempty"`
+}
+
+// Empty returns true if no metadata is set.
+func (m Metadata) Empty() bool {
+	return m == Metadata{}
+}
+
+// Summary returns the site name, author, publication date and language
+// in a single line.
+func (m Metadata) Summary() string {
+	published := m.Published
+	if t, err := time.Parse(time.RFC3339, published); err == nil {
+		published = t.Format(time.DateOnly)
+	}
+	parts := make([]string, 0, 4)
+	for _, s := range []string{m.SiteName, m.Author, published, m.Lang} {
+		if s != "" {
+			parts = append(parts, s)
+		}
+	}
+
+	return strings.Join(parts, " "+format.UnicodeMiddleDot+" ")
+}
+
+// Value implements the driver.Valuer interface.
+func (m Metadata) Value() (driver.Value, error) {
+	if m.Empty() {
+		return "", nil
+	}
+	b, err := json.Marshal(m)
+	if err != nil {
+		return nil, fmt.Errorf("marshalling metadata: %w", err)
+	}
+
+	return string(b), nil
+}
+
+// Scan implements the sql.Scanner interface.
+func (m *Metadata) Scan(src any) error {
+	var b []byte
+	switch v := src.(type) {
+	case nil:
+		*m = Metadata{}
+		return nil
+	case string:
+		b = []byte(v)
+	case []byte:
+		b = v
+	default:
+		return fmt.Errorf("%w: metadata type %T", ErrInvalidInput, src)
+	}
+	if strings.TrimSpace(string(b)) == "" {
+		*m = Metadata{}
+		return nil
+	}
+	if err := json.Unmarshal(b, m); err != nil {
+		return fmt.Errorf("unmarshalling metadata: %w", err)
+	}
+
+	return nil
+}
+
+// ScrapeMetadata sets the metadata and canonical URL from the scraped page.
+func ScrapeMetadata(b *Bookmark, sc *scraper.Scraper) {
+	b.CanonicalURL = sc.Canonical()
+	b.Meta = Metadata{
+		SiteName:  sc.SiteName(),
+		Image:     sc.Image(),
+		Published: sc.Published(),
+		Favicon:   sc.Favicon(),
+		Keywords:  sc.Keywords(),
+		Author:    sc.Author(),
+		Lang:      sc.Lang(),
+	}
+}
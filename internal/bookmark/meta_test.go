@@ -0,0 +1,32 @@
+package bookmark
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetadataValueAndScan(t *testing.T) {
+	t.Parallel()
+	m := Metadata{SiteName: "Example", Author: "Jane", Lang: "en"}
+	v, err := m.Value()
+	assert.NoError(t, err)
+
+	var got Metadata
+	assert.NoError(t, got.Scan(v))
+	assert.Equal(t, m, got)
+
+	empty, err := Metadata{}.Value()
+	assert.NoError(t, err)
+	assert.Equal(t, "", empty)
+	assert.NoError(t, got.Scan(nil))
+	assert.True(t, got.Empty())
+	assert.Error(t, got.Scan(42))
+}
+
+func TestMetadataSummary(t *testing.T) {
+	t.Parallel()
+	m := Metadata{SiteName: "Example", Published: "2024-01-02T10:00:00Z", Lang: "en"}
+	assert.Equal(t, "Example · 2024-01-02 · en", m.Summary())
+	assert.Equal(t, "", Metadata{}.Summary())
+}
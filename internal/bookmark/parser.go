@@ -101,11 +101,61 @@ func parseBookmarkContent(lines []string) *Bookmark {
 	b.URL = cleanLines(extractTextBlock(lines, "# URL:", "# Title:"))
 	b.Title = cleanLines(extractTextBlock(lines, "# Title:", "# Tags:"))
 	b.Tags = ParseTags(cleanLines(extractTextBlock(lines, "# Tags:", "# Description:")))
-	b.Desc = cleanLines(extractTextBlock(lines, "# Description:", "# end"))
+	descEnd := "# end"
+	if hasMarker(lines, "# Notes:") {
+		descEnd = "# Notes:"
+	}
+	b.Desc = cleanLines(extractTextBlock(lines, "# Description:", descEnd))
+	b.Notes = trimBlankLines(extractNotesBlock(lines))
 
 	return b
 }
 
+// extractNotesBlock extracts the notes, up to the last end marker, so
+// Markdown headings in the notes are kept.
+func extractNotesBlock(lines []string) string {
+	start, end := -1, -1
+	for i, l := range lines {
+		switch {
+		case start == -1 && strings.HasPrefix(l, "# Notes:"):
+			start = i
+		case start != -1 && strings.HasPrefix(l, "# end"):
+			end = i
+		}
+	}
+	if start == -1 || end == -1 {
+		return ""
+	}
+
+	return strings.Join(lines[start+1:end], "\n")
+}
+
+// hasMarker checks if any line starts with the marker.
+func hasMarker(lines []string, marker string) bool {
+	for _, l := range lines {
+		if strings.HasPrefix(l, marker) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// trimBlankLines removes the leading and trailing blank lines, keeping the
+// indentation and the empty lines in between, as used in Markdown.
+func trimBlankLines(s string) string {
+	lines := strings.Split(s, "\n")
+	start, end := 0, len(lines)
+	for start < end && strings.TrimSpace(lines[start]) == "" {
+		start++
+	}
+	for end > start && strings.TrimSpace(lines[end-1]) == "" {
+		end--
+	}
+
+	return strings.Join(lines[start:end], "\n")
+}
+
 // extractTextBlock extracts a block of text from a string, delimited by the
 // specified start and end markers.
 func extractTextBlock(content []string, startMarker, endMarker string) string {
@@ -189,6 +239,8 @@ func scrapeBookmark(b *Bookmark) *Bookmark {
 		sc := scraper.New(b.URL, scraper.WithContext(ctx))
 		if err := sc.Scrape(); err != nil {
 			slog.Error("scraping error", "error", err)
+		} else if b.Meta.Empty() {
+			ScrapeMetadata(b, sc)
 		}
 
 		if b.Title == "" {
@@ -1,6 +1,7 @@
 package bookmark
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -163,3 +164,21 @@ func TestParseTags(t *testing.T) {
 		})
 	}
 }
+
+func TestParseBookmarkContentNotes(t *testing.T) {
+	t.Parallel()
+	b := testSingleBookmark()
+	b.Tags = ParseTags(b.Tags)
+	b.Notes = "## Summary\n\n- first\n    code block\n\n# end of section"
+	lines := strings.Split(string(b.Buffer()), "\n")
+	got := parseBookmarkContent(lines)
+	assert.Equal(t, b.Desc, got.Desc)
+	assert.Equal(t, b.Notes, got.Notes)
+	assert.True(t, b.Equals(got))
+
+	// buffers without the notes section
+	old := []string{"# URL:", b.URL, "# Title:", b.Title, "# Tags:", b.Tags, "# Description:", b.Desc, "", "# end"}
+	got = parseBookmarkContent(old)
+	assert.Equal(t, b.Desc, got.Desc)
+	assert.Empty(t, got.Notes)
+}
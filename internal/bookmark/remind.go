@@ -0,0 +1,102 @@
+package bookmark
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrDurationInvalid = errors.New("invalid duration")
+	ErrRemindInvalid   = errors.New("invalid reminder time")
+)
+
+// remindTime returns the reminder time of the bookmark, if any.
+func (b *Bookmark) remindTime() (time.Time, bool) {
+	if b.RemindAt == "" {
+		return time.Time{}, false
+	}
+	t, err := time.Parse(time.RFC3339, b.RemindAt)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	return t, true
+}
+
+// IsSnoozed returns true if the bookmark has a reminder after the given
+// time.
+func (b *Bookmark) IsSnoozed(now time.Time) bool {
+	t, ok := b.remindTime()
+	return ok && t.After(now)
+}
+
+// IsDue returns true if the reminder of the bookmark has passed at the given
+// time.
+func (b *Bookmark) IsDue(now time.Time) bool {
+	t, ok := b.remindTime()
+	return ok && !t.After(now)
+}
+
+// ParseRemindAt parses the reminder time, relative to now as a duration like
+// `3d` or `12h`, or as a date `2006-01-02` or datetime `2006-01-02 15:04` in
+// local time.
+func ParseRemindAt(s string, now time.Time) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	for _, layout := range []string{time.DateOnly, "2006-01-02 15:04", time.RFC3339} {
+		if t, err := time.ParseInLocation(layout, s, now.Location()); err == nil {
+			return t, nil
+		}
+	}
+	d, err := ParseDuration(s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: expected duration (3d, 12h) or date (2006-01-02)", ErrRemindInvalid)
+	}
+
+	return now.Add(d), nil
+}
+
+// ParseDuration parses a duration with support for days and weeks, like
+// `3d`, `2w` or `1d12h`.
+func ParseDuration(s string) (time.Duration, error) {
+	s = strings.TrimSpace(strings.ToLower(s))
+	if s == "" {
+		return 0, fmt.Errorf("%w: empty", ErrDurationInvalid)
+	}
+
+	var total time.Duration
+	units := map[byte]time.Duration{'w': 7 * 24 * time.Hour, 'd': 24 * time.Hour}
+	for s != "" {
+		i := 0
+		for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+			i++
+		}
+		if i == 0 || i == len(s) {
+			break
+		}
+		u, ok := units[s[i]]
+		if !ok {
+			break
+		}
+		n, err := strconv.Atoi(s[:i])
+		if err != nil {
+			return 0, fmt.Errorf("%w: %q", ErrDurationInvalid, s)
+		}
+		total += time.Duration(n) * u
+		s = s[i+1:]
+	}
+	if s != "" {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return 0, fmt.Errorf("%w: %q", ErrDurationInvalid, s)
+		}
+		total += d
+	}
+	if total <= 0 {
+		return 0, fmt.Errorf("%w: must be positive", ErrDurationInvalid)
+	}
+
+	return total, nil
+}
@@ -0,0 +1,57 @@
+package bookmark
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseDuration(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		in   string
+		want time.Duration
+	}{
+		{"3d", 72 * time.Hour},
+		{"2w", 14 * 24 * time.Hour},
+		{"1d12h", 36 * time.Hour},
+		{"90m", 90 * time.Minute},
+	}
+	for _, tt := range tests {
+		got, err := ParseDuration(tt.in)
+		assert.NoError(t, err, tt.in)
+		assert.Equal(t, tt.want, got, tt.in)
+	}
+	for _, in := range []string{"", "d", "3x", "-1h", "0d"} {
+		_, err := ParseDuration(in)
+		assert.ErrorIs(t, err, ErrDurationInvalid, in)
+	}
+}
+
+func TestParseRemindAt(t *testing.T) {
+	t.Parallel()
+	now := time.Date(2026, 10, 18, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		in   string
+		want time.Time
+	}{
+		{"3d", now.Add(72 * time.Hour)},
+		{"2026-11-01", time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)},
+		{"2026-11-01 09:30", time.Date(2026, 11, 1, 9, 30, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		got, err := ParseRemindAt(tt.in, now)
+		assert.NoError(t, err, tt.in)
+		assert.Equal(t, tt.want, got, tt.in)
+	}
+	_, err := ParseRemindAt("next week", now)
+	assert.ErrorIs(t, err, ErrRemindInvalid)
+
+	b := New()
+	b.RemindAt = now.Format(time.RFC3339)
+	assert.True(t, b.IsDue(now))
+	assert.False(t, b.IsSnoozed(now))
+	assert.False(t, b.IsDue(now.Add(-time.Minute)))
+	assert.True(t, b.IsSnoozed(now.Add(-time.Minute)))
+}
@@ -0,0 +1,161 @@
+// Package rules provides deterministic rule-based tagging of bookmarks.
+package rules
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+var (
+	ErrRuleInvalid   = errors.New("invalid rule")
+	ErrRuleNoMatcher = errors.New("rule has no url, domain or title")
+	ErrRuleNoTags    = errors.New("rule has no tags")
+)
+
+// Rule tags the bookmarks matching all of its conditions.
+type Rule struct {
+	Name   string `json:"name,omitempty"   yaml:"name,omitempty"`   // Rule name, used in reports
+	URL    string `json:"url,omitempty"    yaml:"url,omitempty"`    // Regexp matched against the URL
+	Domain string `json:"domain,omitempty" yaml:"domain,omitempty"` // Domain, also matches its subdomains
+	Title  string `json:"title,omitempty"  yaml:"title,omitempty"`  // Regexp matched against the title
+	Tags   string `json:"tags"             yaml:"tags"`             // Comma separated tags to add
+}
+
+// String returns the rule name, or a description of its conditions.
+func (r *Rule) String() string {
+	if r.Name != "" {
+		return r.Name
+	}
+	conds := make([]string, 0, 3)
+	if r.URL != "" {
+		conds = append(conds, "url matches "+r.URL)
+	}
+	if r.Domain != "" {
+		conds = append(conds, "domain: "+r.Domain)
+	}
+	if r.Title != "" {
+		conds = append(conds, "title matches "+r.Title)
+	}
+
+	return strings.Join(conds, " and ")
+}
+
+// compiled is a rule ready to be matched.
+type compiled struct {
+	rule  *Rule
+	url   *regexp.Regexp
+	title *regexp.Regexp
+	tags  []string
+}
+
+// Match is a rule that fired, with the tags it adds.
+type Match struct {
+	Rule *Rule
+	Tags []string
+}
+
+// Engine matches bookmarks against a set of rules.
+type Engine struct {
+	rules []compiled
+}
+
+// New compiles the rules and returns an engine.
+func New(rs []Rule) (*Engine, error) {
+	e := &Engine{rules: make([]compiled, 0, len(rs))}
+	for i := range rs {
+		c, err := compile(&rs[i])
+		if err != nil {
+			return nil, fmt.Errorf("rule #%d %q: %w", i+1, rs[i].String(), err)
+		}
+		e.rules = append(e.rules, c)
+	}
+
+	return e, nil
+}
+
+// Len returns the number of rules.
+func (e *Engine) Len() int {
+	return len(e.rules)
+}
+
+// Match returns the rules matching the given URL and title, in order.
+func (e *Engine) Match(bURL, title string) []Match {
+	host := hostname(bURL)
+	var matches []Match
+	for _, c := range e.rules {
+		if c.url != nil && !c.url.MatchString(bURL) {
+			continue
+		}
+		if c.rule.Domain != "" && !matchDomain(host, c.rule.Domain) {
+			continue
+		}
+		if c.title != nil && !c.title.MatchString(title) {
+			continue
+		}
+		matches = append(matches, Match{Rule: c.rule, Tags: c.tags})
+	}
+
+	return matches
+}
+
+// Tags returns the tags added by the given matches.
+func Tags(ms []Match) []string {
+	var tags []string
+	for _, m := range ms {
+		tags = append(tags, m.Tags...)
+	}
+
+	return tags
+}
+
+// compile validates the rule and compiles its regexps.
+func compile(r *Rule) (compiled, error) {
+	c := compiled{rule: r, tags: splitTags(r.Tags)}
+	if r.URL == "" && r.Domain == "" && r.Title == "" {
+		return c, ErrRuleNoMatcher
+	}
+	if len(c.tags) == 0 {
+		return c, ErrRuleNoTags
+	}
+	var err error
+	if r.URL != "" {
+		if c.url, err = regexp.Compile(r.URL); err != nil {
+			return c, fmt.Errorf("%w: url: %w", ErrRuleInvalid, err)
+		}
+	}
+	if r.Title != "" {
+		if c.title, err = regexp.Compile(r.Title); err != nil {
+			return c, fmt.Errorf("%w: title: %w", ErrRuleInvalid, err)
+		}
+	}
+
+	return c, nil
+}
+
+// matchDomain checks if host is the domain or one of its subdomains.
+func matchDomain(host, domain string) bool {
+	domain = strings.ToLower(strings.TrimPrefix(domain, "www."))
+	host = strings.TrimPrefix(host, "www.")
+
+	return host == domain || strings.HasSuffix(host, "."+domain)
+}
+
+// hostname returns the lowercased host of the URL.
+func hostname(s string) string {
+	u, err := url.Parse(s)
+	if err != nil {
+		return ""
+	}
+
+	return strings.ToLower(u.Hostname())
+}
+
+// splitTags splits a comma or space separated list of tags.
+func splitTags(s string) []string {
+	return strings.FieldsFunc(s, func(r rune) bool {
+		return r == ',' || r == ' '
+	})
+}
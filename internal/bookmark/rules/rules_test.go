@@ -0,0 +1,87 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		rule Rule
+		err  error
+	}{
+		{"valid url", Rule{URL: `^https://github\.com/.*/issues`, Tags: "issue,github"}, nil},
+		{"valid domain", Rule{Domain: "arxiv.org", Tags: "paper"}, nil},
+		{"no matcher", Rule{Tags: "paper"}, ErrRuleNoMatcher},
+		{"no tags", Rule{Domain: "arxiv.org", Tags: " , "}, ErrRuleNoTags},
+		{"invalid regexp", Rule{URL: "(", Tags: "x"}, ErrRuleInvalid},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := New([]Rule{tt.rule})
+			if tt.err == nil {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, tt.err)
+		})
+	}
+}
+
+func TestMatch(t *testing.T) {
+	t.Parallel()
+	e, err := New([]Rule{
+		{Name: "github issues", URL: `^https://github\.com/.*/issues`, Tags: "issue,github"},
+		{Domain: "arxiv.org", Tags: "paper"},
+		{Domain: "youtube.com", Title: `(?i)talk`, Tags: "talk video"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, e.Len())
+
+	tests := []struct {
+		name  string
+		url   string
+		title string
+		fired []string
+		tags  []string
+	}{
+		{
+			name:  "url regexp",
+			url:   "https://github.com/haaag/gm/issues/12",
+			fired: []string{"github issues"},
+			tags:  []string{"issue", "github"},
+		},
+		{name: "url regexp no match", url: "https://github.com/haaag/gm"},
+		{
+			name:  "subdomain",
+			url:   "https://export.arxiv.org/abs/1234",
+			fired: []string{"domain: arxiv.org"},
+			tags:  []string{"paper"},
+		},
+		{name: "domain suffix is not a subdomain", url: "https://notarxiv.org/abs/1234"},
+		{
+			name:  "domain and title",
+			url:   "https://www.youtube.com/watch?v=1",
+			title: "A Talk about Go",
+			fired: []string{"domain: youtube.com and title matches (?i)talk"},
+			tags:  []string{"talk", "video"},
+		},
+		{name: "domain without title", url: "https://youtube.com/watch?v=1", title: "music"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ms := e.Match(tt.url, tt.title)
+			fired := make([]string, 0, len(ms))
+			for _, m := range ms {
+				fired = append(fired, m.Rule.String())
+			}
+			assert.ElementsMatch(t, tt.fired, fired)
+			assert.ElementsMatch(t, tt.tags, Tags(ms))
+		})
+	}
+}
@@ -0,0 +1,197 @@
+package scraper
+
+import (
+	"encoding/json"
+	"net/url"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// jsonLD holds the JSON-LD fields we care about.
+type jsonLD struct {
+	Headline      string `json:"headline"`
+	DatePublished string `json:"datePublished"`
+	Author        any    `json:"author"`
+}
+
+// meta returns the content of the first matching meta tag.
+func (s *Scraper) meta(selectors ...string) string {
+	for _, sel := range selectors {
+		if c := strings.TrimSpace(s.doc.Find(sel).AttrOr("content", "")); c != "" {
+			return c
+		}
+	}
+
+	return ""
+}
+
+// resolve returns the absolute URL of the given reference.
+func (s *Scraper) resolve(ref string) string {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return ""
+	}
+	base := s.doc.Url
+	if base == nil {
+		var err error
+		if base, err = url.Parse(normalizeURL(s.uri)); err != nil {
+			return ref
+		}
+	}
+	u, err := base.Parse(ref)
+	if err != nil {
+		return ref
+	}
+
+	return u.String()
+}
+
+// Canonical retrieves the canonical URL of the page.
+func (s *Scraper) Canonical() string {
+	if href := s.doc.Find("link[rel='canonical']").AttrOr("href", ""); href != "" {
+		return s.resolve(href)
+	}
+
+	return s.resolve(s.meta("meta[property='og:url']"))
+}
+
+// SiteName retrieves the site name.
+func (s *Scraper) SiteName() string {
+	return s.meta("meta[property='og:site_name']", "meta[name='application-name']")
+}
+
+// Image retrieves the preview image URL.
+func (s *Scraper) Image() string {
+	return s.resolve(s.meta(
+		"meta[property='og:image']",
+		"meta[property='og:image:url']",
+		"meta[name='twitter:image']",
+		"meta[property='twitter:image']",
+	))
+}
+
+// Published retrieves the publication time.
+func (s *Scraper) Published() string {
+	if p := s.meta("meta[property='article:published_time']", "meta[name='date']"); p != "" {
+		return p
+	}
+
+	return s.jsonLD().DatePublished
+}
+
+// Favicon retrieves the favicon URL, defaults to `/favicon.ico` on fetched
+// pages.
+func (s *Scraper) Favicon() string {
+	for _, sel := range []string{
+		"link[rel='icon']",
+		"link[rel='shortcut icon']",
+		"link[rel='apple-touch-icon']",
+	} {
+		if href := s.doc.Find(sel).AttrOr("href", ""); href != "" {
+			return s.resolve(href)
+		}
+	}
+	if s.doc.Url == nil {
+		return ""
+	}
+
+	return s.resolve("/favicon.ico")
+}
+
+// Keywords retrieves the meta keywords, comma separated.
+func (s *Scraper) Keywords() string {
+	kw := s.meta("meta[name='keywords']", "meta[name='Keywords']")
+	fields := strings.FieldsFunc(kw, func(r rune) bool { return r == ',' || r == ';' })
+	out := make([]string, 0, len(fields))
+	for _, f := range fields {
+		if f = strings.TrimSpace(f); f != "" {
+			out = append(out, f)
+		}
+	}
+
+	return strings.Join(out, ",")
+}
+
+// Author retrieves the author of the page.
+func (s *Scraper) Author() string {
+	if a := s.meta("meta[name='author']", "meta[property='article:author']"); a != "" {
+		return a
+	}
+
+	return jsonLDAuthor(s.jsonLD().Author)
+}
+
+// Lang retrieves the language of the page.
+func (s *Scraper) Lang() string {
+	if l := strings.TrimSpace(s.doc.Find("html").AttrOr("lang", "")); l != "" {
+		return l
+	}
+
+	return s.meta("meta[property='og:locale']", "meta[http-equiv='content-language']")
+}
+
+// jsonLD returns the first JSON-LD object with a headline or author.
+func (s *Scraper) jsonLD() jsonLD {
+	var found jsonLD
+	s.doc.Find("script[type='application/ld+json']").EachWithBreak(
+		func(_ int, sel *goquery.Selection) bool {
+			for _, ld := range parseJSONLD(sel.Text()) {
+				if ld.Headline != "" || ld.Author != nil {
+					found = ld
+					return false
+				}
+			}
+
+			return true
+		},
+	)
+
+	return found
+}
+
+// parseJSONLD parses a JSON-LD script, which may hold an object, a list of
+// objects or a `@graph`.
+func parseJSONLD(s string) []jsonLD {
+	s = strings.TrimSpace(s)
+	var list []jsonLD
+	if err := json.Unmarshal([]byte(s), &list); err == nil {
+		return list
+	}
+	var graph struct {
+		Graph []jsonLD `json:"@graph"`
+	}
+	if err := json.Unmarshal([]byte(s), &graph); err == nil && len(graph.Graph) > 0 {
+		return graph.Graph
+	}
+	var obj jsonLD
+	if err := json.Unmarshal([]byte(s), &obj); err == nil {
+		return []jsonLD{obj}
+	}
+
+	return nil
+}
+
+// jsonLDAuthor returns the author name, which may be a string, an object or
+// a list of them.
+func jsonLDAuthor(v any) string {
+	switch a := v.(type) {
+	case string:
+		return a
+	case map[string]any:
+		if name, ok := a["name"].(string); ok {
+			return name
+		}
+	case []any:
+		names := make([]string, 0, len(a))
+		for _, item := range a {
+			if n := jsonLDAuthor(item); n != "" {
+				names = append(names, n)
+			}
+		}
+
+		return strings.Join(names, ", ")
+	}
+
+	return ""
+}
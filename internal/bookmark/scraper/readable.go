@@ -0,0 +1,125 @@
+package scraper
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/net/html"
+)
+
+// maxTextLen is the maximum length of the extracted text.
+const maxTextLen = 200 * 1024
+
+// boilerplateTags are removed before extracting the readable text.
+const boilerplateTags = "script, style, noscript, template, iframe, svg, canvas, " +
+	"nav, header, footer, aside, form, button, select, dialog, menu"
+
+// boilerplateRe matches class/id names of non-content elements.
+var boilerplateRe = regexp.MustCompile(
+	`(?i)(^|[\s_-])(ad|ads|advert|banner|breadcrumbs?|comments?|cookie|footer|menu|` +
+		`modal|nav|navbar|newsletter|popup|promo|related|share|sharing|sidebar|social|sponsor|subscribe)([\s_-]|$)`,
+)
+
+// spaceRe matches consecutive whitespace.
+var spaceRe = regexp.MustCompile(`[ \t\r\f\v]+`)
+
+// Text retrieves the readable text of the page, without the boilerplate.
+func (s *Scraper) Text() string {
+	return ReadableText(s.doc)
+}
+
+// TextFromHTML extracts the readable text from the given HTML.
+func TextFromHTML(data []byte) string {
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(data))
+	if err != nil {
+		return ""
+	}
+
+	return ReadableText(doc)
+}
+
+// ReadableText extracts the main text of the document, removing navigation,
+// scripts, ads and other boilerplate.
+//
+// The main content is the <article> or <main> element if present, otherwise
+// the element holding most of the paragraph text.
+func ReadableText(doc *goquery.Document) string {
+	if doc == nil {
+		return ""
+	}
+	doc = goquery.CloneDocument(doc)
+	body := doc.Find("body")
+	body.Find(boilerplateTags).Remove()
+	body.Find("[class], [id]").Each(func(_ int, sel *goquery.Selection) {
+		if boilerplateRe.MatchString(sel.AttrOr("class", "") + " " + sel.AttrOr("id", "")) {
+			sel.Remove()
+		}
+	})
+	body.Find("[hidden], [aria-hidden='true']").Remove()
+
+	root := mainContent(body)
+	// keep block boundaries as line breaks.
+	root.Find("p, div, li, h1, h2, h3, h4, h5, h6, pre, blockquote, tr, br").Each(
+		func(_ int, sel *goquery.Selection) {
+			sel.AppendHtml("\n")
+		},
+	)
+
+	return normalizeText(root.Text())
+}
+
+// mainContent returns the element holding the main content of the page.
+func mainContent(body *goquery.Selection) *goquery.Selection {
+	for _, sel := range []string{"article", "main", "[role='main']"} {
+		if found := body.Find(sel); found.Length() > 0 {
+			return found.First()
+		}
+	}
+
+	// score the parents of the paragraphs by their text length.
+	var (
+		best      *goquery.Selection
+		bestScore int
+		scores    = make(map[*html.Node]int)
+	)
+	body.Find("p").Each(func(_ int, p *goquery.Selection) {
+		n := len(strings.TrimSpace(p.Text()))
+		if n < 25 {
+			return
+		}
+		parent := p.Parent()
+		if parent.Length() == 0 {
+			return
+		}
+		node := parent.Get(0)
+		scores[node] += n
+		if scores[node] > bestScore {
+			best, bestScore = parent, scores[node]
+		}
+	})
+	if best != nil {
+		return best
+	}
+
+	return body
+}
+
+// normalizeText collapses whitespace and empty lines.
+func normalizeText(s string) string {
+	lines := strings.Split(s, "\n")
+	out := make([]string, 0, len(lines))
+	for _, l := range lines {
+		l = strings.TrimSpace(spaceRe.ReplaceAllString(l, " "))
+		if l != "" {
+			out = append(out, l)
+		}
+	}
+	text := strings.Join(out, "\n")
+	if len(text) > maxTextLen {
+		text = strings.ToValidUTF8(text[:maxTextLen], "")
+	}
+
+	return text
+}
@@ -45,17 +45,24 @@ func defaults() *Options {
 	}
 }
 
-// Title retrieves the page title from the Scraper's Doc field, falling back
-// to a default value if not found.
+// Title retrieves the page title from the Scraper's Doc field, then from
+// OpenGraph, Twitter card and JSON-LD, falling back to a default value if not
+// found.
 //
 // default: `untitled (unfiled)`
 func (s *Scraper) Title() string {
-	t := s.doc.Find("title").Text()
+	t := strings.TrimSpace(s.doc.Find("title").First().Text())
+	if t == "" {
+		t = s.meta("meta[property='og:title']", "meta[name='twitter:title']", "meta[property='twitter:title']")
+	}
+	if t == "" {
+		t = strings.TrimSpace(s.jsonLD().Headline)
+	}
 	if t == "" {
 		return defaultTitle
 	}
 
-	return strings.TrimSpace(t)
+	return t
 }
 
 // Desc retrieves the page description from the Scraper's Doc field,
@@ -73,6 +80,8 @@ func (s *Scraper) Desc() string {
 		"meta[property='og:Description']",
 		"meta[name='og:description']",
 		"meta[name='og:Description']",
+		"meta[name='twitter:description']",
+		"meta[property='twitter:description']",
 	} {
 		desc = s.doc.Find(selector).AttrOr("content", "")
 		if desc != "" {
@@ -156,6 +165,8 @@ func scrapeURL(s string, ctx context.Context) *goquery.Document {
 		slog.Error("failed to parse HTML", "url", s, "error", err)
 		return emptyDoc()
 	}
+	// final URL after redirects, used to resolve relative links.
+	doc.Url = res.Request.URL
 
 	return doc
 }
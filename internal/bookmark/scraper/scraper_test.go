@@ -154,3 +154,100 @@ func TestDesc(t *testing.T) {
 		return sc.Desc(), nil
 	}, tests)
 }
+
+func TestReadableText(t *testing.T) {
+	t.Parallel()
+	page := `<html><head><title>Title</title><style>body{}</style></head><body>
+<nav><a href="/">Home</a></nav>
+<div class="sidebar">Sidebar links</div>
+<div id="content">
+  <h1>Heading</h1>
+  <p>The first paragraph holds the main content of the page.</p>
+  <p>A second paragraph with    more   readable text.</p>
+  <script>var tracking = true;</script>
+</div>
+<footer>Copyright</footer>
+</body></html>`
+	srv := createTestServer(page)
+	defer srv.Close()
+	sc := New(srv.URL)
+	_ = sc.Scrape()
+
+	want := "Heading\nThe first paragraph holds the main content of the page.\n" +
+		"A second paragraph with more readable text."
+	if got := sc.Text(); got != want {
+		t.Errorf("Text() = %q, want %q", got, want)
+	}
+	if sc.Title() != "Title" {
+		t.Errorf("Title() = %q, extraction must not modify the document", sc.Title())
+	}
+}
+
+func TestMetadata(t *testing.T) {
+	t.Parallel()
+	page := `<html lang="en"><head>
+<meta property="og:title" content="OG Title">
+<meta property="og:site_name" content="Example Site">
+<meta property="og:image" content="/img/cover.png">
+<meta property="article:published_time" content="2024-01-02T10:00:00Z">
+<meta name="keywords" content="go, cli ; bookmarks,">
+<link rel="canonical" href="/post/1">
+<link rel="icon" href="/static/icon.png">
+<script type="application/ld+json">
+{"@graph": [{"headline": "LD Headline", "author": [{"name": "Jane"}, {"name": "John"}]}]}
+</script>
+</head><body><p>content</p></body></html>`
+	srv := createTestServer(page)
+	defer srv.Close()
+	sc := New(srv.URL)
+	if err := sc.Scrape(); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{"Title", sc.Title(), "OG Title"},
+		{"Canonical", sc.Canonical(), srv.URL + "/post/1"},
+		{"SiteName", sc.SiteName(), "Example Site"},
+		{"Image", sc.Image(), srv.URL + "/img/cover.png"},
+		{"Published", sc.Published(), "2024-01-02T10:00:00Z"},
+		{"Favicon", sc.Favicon(), srv.URL + "/static/icon.png"},
+		{"Keywords", sc.Keywords(), "go,cli,bookmarks"},
+		{"Author", sc.Author(), "Jane, John"},
+		{"Lang", sc.Lang(), "en"},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s() = %q, want %q", tt.name, tt.got, tt.want)
+		}
+	}
+}
+
+func TestMetadataFallbacks(t *testing.T) {
+	t.Parallel()
+	page := `<html><head>
+<script type="application/ld+json">{"headline": "LD Headline", "author": "Jane"}</script>
+</head><body></body></html>`
+	srv := createTestServer(page)
+	defer srv.Close()
+	sc := New(srv.URL)
+	if err := sc.Scrape(); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := sc.Title(); got != "LD Headline" {
+		t.Errorf("Title() = %q, want JSON-LD headline", got)
+	}
+	if got := sc.Author(); got != "Jane" {
+		t.Errorf("Author() = %q, want %q", got, "Jane")
+	}
+	if got := sc.Favicon(); got != srv.URL+"/favicon.ico" {
+		t.Errorf("Favicon() = %q, want default favicon", got)
+	}
+	if got := sc.Canonical(); got != "" {
+		t.Errorf("Canonical() = %q, want empty", got)
+	}
+}
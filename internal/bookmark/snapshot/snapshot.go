@@ -0,0 +1,282 @@
+// Package snapshot creates self-contained HTML copies of web pages and keeps
+// them in a content-addressed store.
+package snapshot
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/net/html"
+)
+
+var ErrFetchFailed = errors.New("fetching page failed")
+
+const (
+	maxPageSize  = 10 * 1024 * 1024 // 10MB
+	maxAssetSize = 5 * 1024 * 1024  // 5MB
+	userAgent    = "Mozilla/5.0 (X11; Linux x86_64; rv:124.0) Gecko/20100101 Firefox/124.0"
+)
+
+// cssURLRe matches `url(...)` references in stylesheets.
+var cssURLRe = regexp.MustCompile(`url\(\s*['"]?([^'")]+)['"]?\s*\)`)
+
+type OptFn func(*Options)
+
+type Options struct {
+	ctx    context.Context
+	client *http.Client
+}
+
+// Snapshot builds a single-file copy of a web page, with stylesheets and
+// images inlined.
+type Snapshot struct {
+	Options
+	assets map[string]string
+}
+
+// WithContext sets the context for the requests.
+func WithContext(ctx context.Context) OptFn {
+	return func(o *Options) {
+		o.ctx = ctx
+	}
+}
+
+// WithClient sets the HTTP client.
+func WithClient(c *http.Client) OptFn {
+	return func(o *Options) {
+		o.client = c
+	}
+}
+
+func defaults() *Options {
+	return &Options{
+		ctx:    context.Background(),
+		client: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// New creates a new Snapshot.
+func New(opts ...OptFn) *Snapshot {
+	o := defaults()
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return &Snapshot{
+		Options: *o,
+		assets:  make(map[string]string),
+	}
+}
+
+// Page downloads the page at the given URL and returns a self-contained HTML
+// document.
+func (s *Snapshot) Page(rawURL string) ([]byte, error) {
+	body, _, base, err := s.fetch(rawURL, maxPageSize)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(string(body)))
+	if err != nil {
+		return nil, fmt.Errorf("parsing html: %w", err)
+	}
+	// honor the document <base>, if any.
+	if href, ok := doc.Find("base[href]").First().Attr("href"); ok {
+		if u, err := base.Parse(href); err == nil {
+			base = u
+		}
+	}
+	doc.Find("base").Remove()
+
+	// scripts would load remote code when opened, the snapshot is static.
+	doc.Find("script").Remove()
+	doc.Find("link[rel~=preload][as=script], link[rel~=modulepreload]").Remove()
+	s.inlineStylesheets(doc, base)
+	s.inlineStyleTags(doc, base)
+	s.inlineImages(doc, base)
+	absolutize(doc, base, "a", "href")
+	absolutize(doc, base, "iframe", "src")
+
+	out, err := doc.Html()
+	if err != nil {
+		return nil, fmt.Errorf("rendering html: %w", err)
+	}
+
+	return []byte(out), nil
+}
+
+// inlineStylesheets replaces the linked stylesheets with <style> tags.
+func (s *Snapshot) inlineStylesheets(doc *goquery.Document, base *url.URL) {
+	doc.Find("link[href]").Each(func(_ int, sel *goquery.Selection) {
+		rel := strings.ToLower(sel.AttrOr("rel", ""))
+		if !strings.Contains(rel, "stylesheet") {
+			if strings.Contains(rel, "icon") {
+				s.inlineAttr(sel, base, "href")
+			}
+
+			return
+		}
+		u, err := base.Parse(sel.AttrOr("href", ""))
+		if err != nil {
+			return
+		}
+		css, _, cssBase, err := s.fetch(u.String(), maxAssetSize)
+		if err != nil {
+			slog.Warn("fetching stylesheet", "url", u.String(), "error", err)
+			return
+		}
+		style := "<style"
+		if media, ok := sel.Attr("media"); ok {
+			style += fmt.Sprintf(" media=%q", media)
+		}
+		style += ">" + s.inlineCSS(string(css), cssBase) + "</style>"
+		sel.ReplaceWithHtml(style)
+	})
+}
+
+// inlineStyleTags inlines the assets referenced by <style> tags and style
+// attributes.
+func (s *Snapshot) inlineStyleTags(doc *goquery.Document, base *url.URL) {
+	// set the text nodes directly, `SetText` escapes the content.
+	for _, n := range doc.Find("style").Nodes {
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type == html.TextNode {
+				c.Data = s.inlineCSS(c.Data, base)
+			}
+		}
+	}
+	doc.Find("[style]").Each(func(_ int, sel *goquery.Selection) {
+		sel.SetAttr("style", s.inlineCSS(sel.AttrOr("style", ""), base))
+	})
+}
+
+// inlineImages replaces the image sources with data URIs.
+func (s *Snapshot) inlineImages(doc *goquery.Document, base *url.URL) {
+	doc.Find("img[src], input[type='image'][src]").Each(func(_ int, sel *goquery.Selection) {
+		s.inlineAttr(sel, base, "src")
+		// srcset points to remote candidates, the inlined src is enough.
+		sel.RemoveAttr("srcset")
+		sel.RemoveAttr("loading")
+	})
+	doc.Find("picture source[srcset]").Remove()
+}
+
+// inlineAttr replaces the URL in the given attribute with a data URI.
+func (s *Snapshot) inlineAttr(sel *goquery.Selection, base *url.URL, attr string) {
+	ref, ok := sel.Attr(attr)
+	if !ok || strings.HasPrefix(ref, "data:") {
+		return
+	}
+	u, err := base.Parse(ref)
+	if err != nil {
+		return
+	}
+	if data := s.dataURI(u); data != "" {
+		sel.SetAttr(attr, data)
+		return
+	}
+	sel.SetAttr(attr, u.String())
+}
+
+// inlineCSS replaces the `url(...)` references in the stylesheet with data
+// URIs.
+func (s *Snapshot) inlineCSS(css string, base *url.URL) string {
+	return cssURLRe.ReplaceAllStringFunc(css, func(m string) string {
+		ref := cssURLRe.FindStringSubmatch(m)[1]
+		if strings.HasPrefix(ref, "data:") || strings.HasPrefix(ref, "#") {
+			return m
+		}
+		u, err := base.Parse(strings.TrimSpace(ref))
+		if err != nil {
+			return m
+		}
+		if data := s.dataURI(u); data != "" {
+			return "url('" + data + "')"
+		}
+
+		return "url('" + u.String() + "')"
+	})
+}
+
+// dataURI fetches the asset and encodes it as a data URI.
+func (s *Snapshot) dataURI(u *url.URL) string {
+	key := u.String()
+	if data, ok := s.assets[key]; ok {
+		return data
+	}
+	body, ct, _, err := s.fetch(key, maxAssetSize)
+	if err != nil {
+		slog.Warn("fetching asset", "url", key, "error", err)
+		s.assets[key] = ""
+		return ""
+	}
+	if mt, _, err := mime.ParseMediaType(ct); err == nil {
+		ct = mt
+	}
+	if ct == "" {
+		ct = http.DetectContentType(body)
+	}
+	data := "data:" + ct + ";base64," + base64.StdEncoding.EncodeToString(body)
+	s.assets[key] = data
+
+	return data
+}
+
+// fetch downloads the given URL and returns its body, content type and the
+// final URL after redirects.
+func (s *Snapshot) fetch(rawURL string, limit int64) ([]byte, string, *url.URL, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("%w: %w", ErrFetchFailed, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, "", nil, fmt.Errorf("%w: unsupported scheme %q", ErrFetchFailed, u.Scheme)
+	}
+	req, err := http.NewRequestWithContext(s.ctx, http.MethodGet, u.String(), http.NoBody)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("%w: %w", ErrFetchFailed, err)
+	}
+	req.Header.Set("User-Agent", userAgent)
+	res, err := s.client.Do(req)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("%w: %w", ErrFetchFailed, err)
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			slog.Error("closing response body", "url", rawURL, "error", err)
+		}
+	}()
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return nil, "", nil, fmt.Errorf("%w: %q returned %d", ErrFetchFailed, rawURL, res.StatusCode)
+	}
+	body, err := io.ReadAll(io.LimitReader(res.Body, limit))
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("%w: %w", ErrFetchFailed, err)
+	}
+
+	return body, res.Header.Get("Content-Type"), res.Request.URL, nil
+}
+
+// absolutize rewrites the given attribute as an absolute URL, so links keep
+// working from the local copy.
+func absolutize(doc *goquery.Document, base *url.URL, tag, attr string) {
+	doc.Find(tag + "[" + attr + "]").Each(func(_ int, sel *goquery.Selection) {
+		ref := sel.AttrOr(attr, "")
+		if ref == "" || strings.HasPrefix(ref, "#") || strings.HasPrefix(ref, "data:") {
+			return
+		}
+		if u, err := base.Parse(ref); err == nil {
+			sel.SetAttr(attr, u.String())
+		}
+	})
+}
@@ -0,0 +1,80 @@
+package snapshot
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testPNG is a 1x1 transparent PNG.
+var testPNG = []byte{
+	0x89, 0x50, 0x4e, 0x47, 0x0d, 0x0a, 0x1a, 0x0a, 0x00, 0x00, 0x00, 0x0d,
+	0x49, 0x48, 0x44, 0x52, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x01,
+	0x08, 0x06, 0x00, 0x00, 0x00, 0x1f, 0x15, 0xc4, 0x89, 0x00, 0x00, 0x00,
+	0x0a, 0x49, 0x44, 0x41, 0x54, 0x78, 0x9c, 0x63, 0x00, 0x01, 0x00, 0x00,
+	0x05, 0x00, 0x01, 0x0d, 0x0a, 0x2d, 0xb4, 0x00, 0x00, 0x00, 0x00, 0x49,
+	0x45, 0x4e, 0x44, 0xae, 0x42, 0x60, 0x82,
+}
+
+func testServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/page", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write([]byte(`<!DOCTYPE html>
+<html><head>
+<title>Test page</title>
+<link rel="stylesheet" href="/static/style.css">
+<script src="https://cdn.example.com/app.js"></script>
+</head><body>
+<script>fetch("https://tracker.example.com")</script>
+<img src="img/logo.png" srcset="img/logo@2x.png 2x">
+<img src="/missing.png">
+<a href="/about">about</a>
+</body></html>`))
+	})
+	mux.HandleFunc("/static/style.css", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/css")
+		_, _ = w.Write([]byte(`body { background: url('../img/logo.png'); }`))
+	})
+	mux.HandleFunc("/img/logo.png", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		_, _ = w.Write(testPNG)
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+func TestPage(t *testing.T) {
+	t.Parallel()
+	srv := testServer(t)
+	s := New(WithClient(srv.Client()))
+	data, err := s.Page(srv.URL + "/page")
+	assert.NoError(t, err)
+
+	html := string(data)
+	assert.Contains(t, html, "<title>Test page</title>")
+	assert.NotContains(t, html, "style.css", "stylesheet should be inlined")
+	assert.Contains(t, html, "<style>body { background: url('data:image/png;base64,")
+	assert.Contains(t, html, `<img src="data:image/png;base64,`)
+	assert.NotContains(t, html, "srcset")
+	assert.Contains(t, html, srv.URL+"/missing.png", "unreachable assets keep an absolute URL")
+	assert.Contains(t, html, `href="`+srv.URL+`/about"`)
+	assert.Equal(t, 1, strings.Count(html, "<style>"))
+	assert.NotContains(t, html, "<script", "scripts should be removed")
+}
+
+func TestPageFetchFailed(t *testing.T) {
+	t.Parallel()
+	srv := testServer(t)
+	s := New(WithClient(srv.Client()))
+	_, err := s.Page(srv.URL + "/not-found")
+	assert.ErrorIs(t, err, ErrFetchFailed)
+	_, err = s.Page("ftp://example.com")
+	assert.ErrorIs(t, err, ErrFetchFailed)
+}
@@ -0,0 +1,167 @@
+package snapshot
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"github.com/haaag/gm/internal/sys/files"
+)
+
+var (
+	ErrSnapshotNotFound = errors.New("snapshot not found")
+	ErrInvalidHash      = errors.New("invalid snapshot hash")
+)
+
+// ext is the extension of the stored snapshots.
+const ext = ".html.gz"
+
+// Store is a content-addressed store of compressed snapshots.
+//
+// Snapshots are stored by the SHA-256 hash of their content, so identical
+// pages are saved only once.
+type Store struct {
+	root string
+}
+
+// NewStore creates a new store at the given path.
+func NewStore(root string) *Store {
+	return &Store{root: root}
+}
+
+// Root returns the store path.
+func (s *Store) Root() string {
+	return s.root
+}
+
+// Hash returns the hash used to address the given content.
+func Hash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// Path returns the path of the snapshot with the given hash.
+func (s *Store) Path(hash string) (string, error) {
+	if len(hash) != sha256.Size*2 {
+		return "", fmt.Errorf("%w: %q", ErrInvalidHash, hash)
+	}
+	if _, err := hex.DecodeString(hash); err != nil {
+		return "", fmt.Errorf("%w: %q", ErrInvalidHash, hash)
+	}
+
+	return filepath.Join(s.root, hash[:2], hash[2:]+ext), nil
+}
+
+// Has checks if the snapshot with the given hash is stored.
+func (s *Store) Has(hash string) bool {
+	p, err := s.Path(hash)
+	if err != nil {
+		return false
+	}
+
+	return files.Exists(p)
+}
+
+// Put compresses and stores the given content, returning its hash.
+func (s *Store) Put(data []byte) (string, error) {
+	hash := Hash(data)
+	p, err := s.Path(hash)
+	if err != nil {
+		return "", err
+	}
+	if files.Exists(p) {
+		slog.Debug("snapshot already stored", "hash", hash)
+		return hash, nil
+	}
+	if err := files.MkdirAll(filepath.Dir(p)); err != nil {
+		return "", fmt.Errorf("%w", err)
+	}
+
+	var buf bytes.Buffer
+	zw, err := gzip.NewWriterLevel(&buf, gzip.BestCompression)
+	if err != nil {
+		return "", fmt.Errorf("creating gzip writer: %w", err)
+	}
+	if _, err := zw.Write(data); err != nil {
+		return "", fmt.Errorf("compressing snapshot: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return "", fmt.Errorf("compressing snapshot: %w", err)
+	}
+
+	// write to a temp file and rename, so a partial write never ends up
+	// under a valid hash.
+	tmp := p + ".tmp"
+	if err := os.WriteFile(tmp, buf.Bytes(), files.FilePerm); err != nil {
+		return "", fmt.Errorf("writing snapshot: %w", err)
+	}
+	if err := os.Rename(tmp, p); err != nil {
+		return "", fmt.Errorf("writing snapshot: %w", err)
+	}
+	slog.Info("snapshot stored", "hash", hash, "size", buf.Len())
+
+	return hash, nil
+}
+
+// Get returns the decompressed content of the snapshot with the given hash.
+func (s *Store) Get(hash string) ([]byte, error) {
+	p, err := s.Path(hash)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(p)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("%w: %q", ErrSnapshotNotFound, hash)
+		}
+
+		return nil, fmt.Errorf("opening snapshot: %w", err)
+	}
+	defer func() {
+		if err := f.Close(); err != nil {
+			slog.Error("closing snapshot", "file", p, "error", err)
+		}
+	}()
+
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("reading snapshot: %w", err)
+	}
+	data, err := io.ReadAll(zr)
+	if err != nil {
+		return nil, fmt.Errorf("reading snapshot: %w", err)
+	}
+
+	return data, nil
+}
+
+// Extract writes the decompressed snapshot to a new temporary HTML file,
+// only readable by the user, and returns its path. The caller removes it.
+func (s *Store) Extract(hash string) (string, error) {
+	data, err := s.Get(hash)
+	if err != nil {
+		return "", err
+	}
+	f, err := os.CreateTemp("", "gomarks-*.html")
+	if err != nil {
+		return "", fmt.Errorf("extracting snapshot: %w", err)
+	}
+	if _, err := f.Write(data); err != nil {
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+		return "", fmt.Errorf("extracting snapshot: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		_ = os.Remove(f.Name())
+		return "", fmt.Errorf("extracting snapshot: %w", err)
+	}
+
+	return f.Name(), nil
+}
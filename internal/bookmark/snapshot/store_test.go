@@ -0,0 +1,65 @@
+package snapshot
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStore(t *testing.T) {
+	t.Parallel()
+	st := NewStore(t.TempDir())
+	data := []byte("<html><body>offline copy</body></html>")
+
+	hash, err := st.Put(data)
+	assert.NoError(t, err)
+	assert.Equal(t, Hash(data), hash)
+	assert.True(t, st.Has(hash))
+
+	t.Run("deduplicated by hash", func(t *testing.T) {
+		t.Parallel()
+		again, err := st.Put(data)
+		assert.NoError(t, err)
+		assert.Equal(t, hash, again)
+	})
+	t.Run("stored compressed", func(t *testing.T) {
+		t.Parallel()
+		p, err := st.Path(hash)
+		assert.NoError(t, err)
+		raw, err := os.ReadFile(p)
+		assert.NoError(t, err)
+		assert.NotEqual(t, data, raw)
+		got, err := st.Get(hash)
+		assert.NoError(t, err)
+		assert.Equal(t, data, got)
+	})
+	t.Run("extract", func(t *testing.T) {
+		t.Parallel()
+		p, err := st.Extract(hash)
+		assert.NoError(t, err)
+		t.Cleanup(func() { _ = os.Remove(p) })
+		fi, err := os.Stat(p)
+		assert.NoError(t, err)
+		assert.Equal(t, os.FileMode(0o600), fi.Mode().Perm())
+		got, err := os.ReadFile(p)
+		assert.NoError(t, err)
+		assert.Equal(t, data, got)
+
+		other, err := st.Extract(hash)
+		assert.NoError(t, err)
+		t.Cleanup(func() { _ = os.Remove(other) })
+		assert.NotEqual(t, p, other, "each extraction gets a new file")
+	})
+	t.Run("not found", func(t *testing.T) {
+		t.Parallel()
+		_, err := st.Get(Hash([]byte("nope")))
+		assert.ErrorIs(t, err, ErrSnapshotNotFound)
+	})
+	t.Run("invalid hash", func(t *testing.T) {
+		t.Parallel()
+		_, err := st.Get("../../etc/passwd")
+		assert.ErrorIs(t, err, ErrInvalidHash)
+		assert.False(t, st.Has("zz"))
+	})
+}
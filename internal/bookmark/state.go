@@ -0,0 +1,41 @@
+package bookmark
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrStateInvalid is returned when the read-later state is unknown.
+var ErrStateInvalid = errors.New("invalid state, expected unread|read|archived")
+
+// Read-later states of a bookmark.
+//
+// Bookmarks without state are not part of the read-later queue.
+const (
+	StateNone     = ""
+	StateUnread   = "unread"
+	StateRead     = "read"
+	StateArchived = "archived"
+)
+
+// States holds the valid read-later states.
+var States = []string{StateUnread, StateRead, StateArchived}
+
+// ValidateState checks if the state is valid.
+func ValidateState(s string) error {
+	switch s {
+	case StateUnread, StateRead, StateArchived:
+		return nil
+	}
+
+	return fmt.Errorf("%w: %q", ErrStateInvalid, s)
+}
+
+// SetState sets the read-later state of the bookmark and the time of the
+// change, clearing any snooze.
+func (b *Bookmark) SetState(s string) {
+	b.State = s
+	b.StateAt = time.Now().UTC().Format(time.RFC3339)
+	b.RemindAt = ""
+}
@@ -0,0 +1,25 @@
+package bookmark
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestState(t *testing.T) {
+	t.Parallel()
+	assert.NoError(t, ValidateState(StateArchived))
+	assert.ErrorIs(t, ValidateState("done"), ErrStateInvalid)
+
+	b := New()
+	now := time.Now()
+	b.RemindAt = now.Add(time.Hour).Format(time.RFC3339)
+	assert.True(t, b.IsSnoozed(now))
+	assert.False(t, b.IsSnoozed(now.Add(2*time.Hour)))
+
+	b.SetState(StateRead)
+	assert.Equal(t, StateRead, b.State)
+	assert.Empty(t, b.RemindAt)
+	assert.NotEmpty(t, b.StateAt)
+}
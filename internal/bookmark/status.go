@@ -46,47 +46,85 @@ func (r *Response) String() string {
 	return fmt.Sprintf("%s%s%s%s", id, code, status, url)
 }
 
-// Status checks the status of a slice of bookmarks.
-func Status(bs *slice.Slice[Bookmark]) error {
-	const maxConRequests = 25
+// ID returns the bookmark ID of the response.
+func (r *Response) ID() int {
+	return r.bID
+}
+
+// Dead returns true if the URL is no longer reachable.
+func (r *Response) Dead() bool {
+	switch r.statusCode {
+	case http.StatusNotFound, http.StatusGone:
+		return true
+	}
+
+	return r.statusCode >= http.StatusInternalServerError
+}
+
+// maxConRequests is the maximum number of concurrent requests.
+const maxConRequests = 25
+
+// concurrent applies fn to each bookmark, running at most n calls at the same
+// time, and collects the results.
+func concurrent[T comparable](
+	bs *slice.Slice[Bookmark],
+	n int,
+	fn func(context.Context, *Bookmark) T,
+) (*slice.Slice[T], error) {
 	var (
-		responses = slice.New[Response]()
-		sem       *semaphore.Weighted
-		start     time.Time
-		wg        sync.WaitGroup
+		results = slice.New[T]()
+		sem     = semaphore.NewWeighted(int64(n))
+		wg      sync.WaitGroup
 	)
-
-	sem = semaphore.NewWeighted(int64(maxConRequests))
-	start = time.Now()
 	ctx := context.Background()
 
 	schedule := func(b Bookmark) error {
-		wg.Add(1)
 		if err := sem.Acquire(ctx, 1); err != nil {
 			return fmt.Errorf("error acquiring semaphore: %w", err)
 		}
+		wg.Add(1)
 
 		time.Sleep(50 * time.Millisecond)
 
 		go func(b *Bookmark) {
 			defer wg.Done()
-			res := makeRequest(b, ctx, sem)
-			responses.Append(res)
+			defer sem.Release(1)
+			res := fn(ctx, b)
+			results.Push(&res)
 		}(&b)
 
 		return nil
 	}
 
 	if err := bs.ForEachErr(schedule); err != nil {
-		return fmt.Errorf("%w", err)
+		wg.Wait()
+		return nil, fmt.Errorf("%w", err)
 	}
 
 	wg.Wait()
 
+	return results, nil
+}
+
+// Status checks the status of a slice of bookmarks.
+func Status(bs *slice.Slice[Bookmark]) error {
+	_, err := StatusResponses(bs)
+	return err
+}
+
+// StatusResponses checks the status of a slice of bookmarks, prints a summary
+// and returns the responses.
+func StatusResponses(bs *slice.Slice[Bookmark]) (*slice.Slice[Response], error) {
+	start := time.Now()
+	responses, err := concurrent(bs, maxConRequests, makeRequest)
+	if err != nil {
+		return nil, err
+	}
+
 	duration := time.Since(start)
 	printSummaryStatus(responses, duration)
 
-	return nil
+	return responses, nil
 }
 
 // prettifyURLStatus formats HTTP status codes into colored.
@@ -200,13 +238,7 @@ func handleRequestError(b *Bookmark, err error) Response {
 
 // makeRequest sends an HTTP GET request to the URL of the given bookmark and
 // returns a response.
-//
-// The function uses a weighted semaphore to limit the number of concurrent
-// requests.
-func makeRequest(b *Bookmark, ctx context.Context, sem *semaphore.Weighted) Response {
-	// FIX: Split this function
-	defer sem.Release(1)
-
+func makeRequest(ctx context.Context, b *Bookmark) Response {
 	timeout := 5 * time.Second
 	ctx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
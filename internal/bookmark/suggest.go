@@ -0,0 +1,113 @@
+package bookmark
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Weights of each source of tag suggestions.
+const (
+	weightDomain  = 3.0 // tag used by bookmarks of the same domain
+	weightKeyword = 2.0 // page keyword matching a known tag
+	weightNewTag  = 1.0 // page keyword not yet used as a tag
+	weightTitle   = 1.5 // known tag found in title, description or site name
+	weightText    = 0.5 // known tag found in the page text
+	minScore      = 1.0 // minimum score for a tag to be suggested
+)
+
+// TagHints holds the existing tags used to rank suggestions.
+type TagHints struct {
+	Domain map[string]int // tags used by bookmarks of the same domain
+	Corpus map[string]int // all tags and the number of bookmarks using them
+}
+
+// Domain returns the host of the URL without the `www.` prefix.
+func Domain(s string) string {
+	u, err := url.Parse(s)
+	if err != nil {
+		return ""
+	}
+
+	return strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+}
+
+// SuggestTags ranks tags for the bookmark, using the tags of bookmarks from
+// the same domain, the page keywords and the overlap between the known tags
+// and the page title, description and text. It returns at most n tags.
+func SuggestTags(b *Bookmark, text string, h *TagHints, n int) []string {
+	scores := make(map[string]float64)
+	known := func(t string) bool {
+		_, ok := h.Corpus[t]
+		return ok
+	}
+	// domain past tags
+	var maxCount int
+	for _, c := range h.Domain {
+		maxCount = max(maxCount, c)
+	}
+	for t, c := range h.Domain {
+		scores[t] += weightDomain * float64(c) / float64(maxCount)
+	}
+	// page keywords
+	for _, kw := range strings.Split(b.Meta.Keywords, ",") {
+		t := normalizeTag(kw)
+		if t == "" {
+			continue
+		}
+		if known(t) {
+			scores[t] += weightKeyword
+		} else {
+			scores[t] += weightNewTag
+		}
+	}
+	// term overlap with the tag vocabulary
+	head := termSet(b.Title + " " + b.Desc + " " + b.Meta.SiteName)
+	body := termSet(text)
+	for t := range h.Corpus {
+		if head[t] {
+			scores[t] += weightTitle
+		}
+		if body[t] {
+			scores[t] += weightText
+		}
+	}
+	delete(scores, "notag")
+
+	tags := make([]string, 0, len(scores))
+	for t, s := range scores {
+		if s >= minScore {
+			tags = append(tags, t)
+		}
+	}
+	sort.Slice(tags, func(i, j int) bool {
+		if scores[tags[i]] != scores[tags[j]] {
+			return scores[tags[i]] > scores[tags[j]]
+		}
+
+		return tags[i] < tags[j]
+	})
+	if len(tags) > n {
+		tags = tags[:n]
+	}
+
+	return tags
+}
+
+// normalizeTag lowercases the tag and joins its words with a dash.
+func normalizeTag(s string) string {
+	return strings.Join(strings.Fields(strings.ToLower(s)), "-")
+}
+
+// termSet returns the set of lowercased words found in s.
+func termSet(s string) map[string]bool {
+	terms := make(map[string]bool)
+	for _, w := range strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '-' && r != '+' && r != '#'
+	}) {
+		terms[w] = true
+	}
+
+	return terms
+}
@@ -0,0 +1,33 @@
+package bookmark
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDomain(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, "github.com", Domain("https://www.GitHub.com/haaag/gm"))
+	assert.Equal(t, "example.org", Domain("http://example.org:8080"))
+	assert.Empty(t, Domain("::invalid"))
+}
+
+func TestSuggestTags(t *testing.T) {
+	t.Parallel()
+	b := &Bookmark{
+		URL:   "https://github.com/haaag/gm",
+		Title: "gm: a simple bookmark manager written in Go",
+		Meta:  Metadata{Keywords: "cli,Bookmark Manager"},
+	}
+	h := &TagHints{
+		Domain: map[string]int{"code": 4, "git": 1},
+		Corpus: map[string]int{"code": 4, "git": 1, "go": 3, "cli": 2, "python": 5, "notag": 9},
+	}
+	got := SuggestTags(b, "runs in the terminal, unlike python scripts", h, 4)
+	assert.Equal(t, []string{"code", "cli", "go", "bookmark-manager"}, got)
+
+	got = SuggestTags(b, "", h, 10)
+	assert.NotContains(t, got, "python", "text-only matches are below threshold")
+	assert.NotContains(t, got, "notag")
+}
@@ -0,0 +1,183 @@
+// Package wayback provides a client for the Internet Archive Wayback Machine.
+package wayback
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultAPIURL is the Wayback Machine availability API endpoint.
+	DefaultAPIURL = "https://archive.org/wayback/available"
+	// DefaultSaveURL is the Wayback Machine "save page now" endpoint.
+	DefaultSaveURL = "https://web.archive.org/save/"
+)
+
+var (
+	ErrSnapshotNotFound = errors.New("snapshot not found")
+	ErrSnapshotRequest  = errors.New("snapshot request failed")
+)
+
+// Snapshot represents an archived copy of a page.
+type Snapshot struct {
+	URL       string `json:"url"`       // URL of the archived copy
+	Timestamp string `json:"timestamp"` // YYYYMMDDhhmmss
+	Status    string `json:"status"`    // HTTP status of the archived copy
+	Available bool   `json:"available"` // Snapshot is available
+}
+
+// availability is the response from the availability API.
+type availability struct {
+	URL               string `json:"url"`
+	ArchivedSnapshots struct {
+		Closest *Snapshot `json:"closest"`
+	} `json:"archived_snapshots"`
+}
+
+type OptFn func(*Options)
+
+type Options struct {
+	apiURL  string
+	saveURL string
+	client  *http.Client
+}
+
+// Wayback is a client for the Wayback Machine.
+type Wayback struct {
+	Options
+}
+
+// WithAPIURL sets the availability API endpoint.
+func WithAPIURL(s string) OptFn {
+	return func(o *Options) {
+		if s != "" {
+			o.apiURL = s
+		}
+	}
+}
+
+// WithSaveURL sets the "save page now" endpoint.
+func WithSaveURL(s string) OptFn {
+	return func(o *Options) {
+		if s != "" {
+			o.saveURL = s
+		}
+	}
+}
+
+// WithClient sets the HTTP client.
+func WithClient(c *http.Client) OptFn {
+	return func(o *Options) {
+		o.client = c
+	}
+}
+
+func defaults() *Options {
+	return &Options{
+		apiURL:  DefaultAPIURL,
+		saveURL: DefaultSaveURL,
+		client:  &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// New creates a new Wayback client.
+func New(opts ...OptFn) *Wayback {
+	o := defaults()
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return &Wayback{
+		Options: *o,
+	}
+}
+
+// Closest queries the availability API for the closest snapshot of the given
+// URL.
+func (w *Wayback) Closest(ctx context.Context, s string) (*Snapshot, error) {
+	u, err := url.Parse(w.apiURL)
+	if err != nil {
+		return nil, fmt.Errorf("parsing api url: %w", err)
+	}
+	q := u.Query()
+	q.Set("url", s)
+	u.RawQuery = q.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), http.NoBody)
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+	res, err := w.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrSnapshotRequest, err)
+	}
+	defer closeBody(res)
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: availability api returned %d", ErrSnapshotRequest, res.StatusCode)
+	}
+	var a availability
+	if err := json.NewDecoder(io.LimitReader(res.Body, 1<<20)).Decode(&a); err != nil {
+		return nil, fmt.Errorf("decoding availability response: %w", err)
+	}
+	c := a.ArchivedSnapshots.Closest
+	if c == nil || !c.Available || c.URL == "" {
+		return nil, fmt.Errorf("%w: %q", ErrSnapshotNotFound, s)
+	}
+	slog.Debug("closest snapshot found", "url", s, "snapshot", c.URL, "timestamp", c.Timestamp)
+
+	return c, nil
+}
+
+// Save requests a new snapshot of the given URL and returns the URL of the
+// archived copy.
+func (w *Wayback) Save(ctx context.Context, s string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, w.saveURL+s, http.NoBody)
+	if err != nil {
+		return "", fmt.Errorf("creating request: %w", err)
+	}
+	res, err := w.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrSnapshotRequest, err)
+	}
+	defer closeBody(res)
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return "", fmt.Errorf("%w: save returned %d", ErrSnapshotRequest, res.StatusCode)
+	}
+	// the archived copy location is sent in the `Content-Location` header, if
+	// not, the request was redirected to it.
+	loc := res.Header.Get("Content-Location")
+	if loc == "" {
+		if res.Request.URL.String() == req.URL.String() {
+			return "", fmt.Errorf("%w: no snapshot location for %q", ErrSnapshotRequest, s)
+		}
+
+		return res.Request.URL.String(), nil
+	}
+	ref, err := url.Parse(loc)
+	if err != nil {
+		return "", fmt.Errorf("parsing snapshot location: %w", err)
+	}
+
+	return res.Request.URL.ResolveReference(ref).String(), nil
+}
+
+// IsSnapshot reports whether the given URL points to an archived copy.
+func IsSnapshot(s string) bool {
+	return strings.Contains(s, "web.archive.org/web/")
+}
+
+func closeBody(res *http.Response) {
+	if err := res.Body.Close(); err != nil {
+		slog.Error("closing response body", "error", err)
+	}
+}
@@ -0,0 +1,89 @@
+package wayback
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClosest(t *testing.T) {
+	t.Parallel()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/available", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("url") != "https://example.com" {
+			_, _ = w.Write([]byte(`{"url": "x", "archived_snapshots": {}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{
+			"url": "https://example.com",
+			"archived_snapshots": {
+				"closest": {
+					"status": "200",
+					"available": true,
+					"url": "http://web.archive.org/web/20240101000000/https://example.com",
+					"timestamp": "20240101000000"
+				}
+			}
+		}`))
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	wb := New(WithAPIURL(srv.URL+"/available"), WithClient(srv.Client()))
+	t.Run("snapshot found", func(t *testing.T) {
+		t.Parallel()
+		s, err := wb.Closest(context.Background(), "https://example.com")
+		assert.NoError(t, err)
+		assert.Equal(t, "20240101000000", s.Timestamp)
+		assert.True(t, IsSnapshot(s.URL))
+	})
+	t.Run("snapshot not found", func(t *testing.T) {
+		t.Parallel()
+		s, err := wb.Closest(context.Background(), "https://missing.example.com")
+		assert.Nil(t, s)
+		assert.ErrorIs(t, err, ErrSnapshotNotFound)
+	})
+}
+
+func TestSave(t *testing.T) {
+	t.Parallel()
+	// plain handler, `ServeMux` and `http.Redirect` clean the double slash from
+	// the saved URL.
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/save/https://example.com":
+			w.Header().Set("Content-Location", "/web/20240101000000/https://example.com")
+		case "/save/https://redirect.example.com":
+			w.Header().Set("Location", "/web/20240101000000/https://redirect.example.com")
+			w.WriteHeader(http.StatusFound)
+		case "/web/20240101000000/https://redirect.example.com":
+			w.WriteHeader(http.StatusOK)
+		default:
+			w.WriteHeader(http.StatusTooManyRequests)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	wb := New(WithSaveURL(srv.URL+"/save/"), WithClient(srv.Client()))
+	t.Run("content location", func(t *testing.T) {
+		t.Parallel()
+		u, err := wb.Save(context.Background(), "https://example.com")
+		assert.NoError(t, err)
+		assert.Equal(t, srv.URL+"/web/20240101000000/https://example.com", u)
+	})
+	t.Run("redirect", func(t *testing.T) {
+		t.Parallel()
+		u, err := wb.Save(context.Background(), "https://redirect.example.com")
+		assert.NoError(t, err)
+		assert.Equal(t, srv.URL+"/web/20240101000000/https://redirect.example.com", u)
+	})
+	t.Run("request failed", func(t *testing.T) {
+		t.Parallel()
+		u, err := wb.Save(context.Background(), "https://busy.example.com")
+		assert.Empty(t, u)
+		assert.ErrorIs(t, err, ErrSnapshotRequest)
+	})
+}
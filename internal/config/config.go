@@ -38,6 +38,7 @@ type (
 		ConfigFile   string `json:"config"`       // Path to config file
 		Backup       string `json:"backup"`       // Path to store backups
 		Colorschemes string `json:"colorschemes"` // Path to store colorschemes
+		Archive      string `json:"archive"`      // Path to store offline snapshots
 	}
 
 	information struct {
@@ -50,6 +51,7 @@ type (
 	environment struct {
 		Home   string `json:"home"`   // Environment variable for the home directory
 		Editor string `json:"editor"` // Environment variable for the preferred editor
+		Agent  string `json:"agent"`  // Environment variable for the agent socket
 	}
 )
 
@@ -83,6 +85,7 @@ func SetDataPath(p string) {
 	App.Path.Data = p
 	App.Path.ConfigFile = filepath.Join(p, configFilename)
 	App.Path.Backup = filepath.Join(p, "backup")
+	App.Path.Archive = filepath.Join(p, "archive")
 }
 
 func SetVerbosity(verbose int) {
@@ -3,14 +3,153 @@ package config
 import (
 	"fmt"
 	"log/slog"
+	"time"
 
+	"github.com/haaag/gm/internal/bookmark/canonical"
+	"github.com/haaag/gm/internal/bookmark/rules"
+	"github.com/haaag/gm/internal/bookmark/wayback"
 	"github.com/haaag/gm/internal/menu"
 )
 
 // ConfigFile represents the configuration file.
 type ConfigFile struct {
-	Colorscheme string       `json:"colorscheme" yaml:"colorscheme"` // App colorscheme
-	Menu        *menu.Config `json:"menu"        yaml:"menu"`        // Menu configuration
+	Colorscheme string           `json:"colorscheme" yaml:"colorscheme"` // App colorscheme
+	Menu        *menu.Config     `json:"menu"        yaml:"menu"`        // Menu configuration
+	Wayback     *WaybackConfig   `json:"wayback"     yaml:"wayback"`     // Wayback Machine configuration
+	Archive     *ArchiveConfig   `json:"archive"     yaml:"archive"`     // Offline archive configuration
+	Rules       []rules.Rule     `json:"rules"       yaml:"rules"`       // Auto-tagging rules
+	Canonical   *CanonicalConfig `json:"canonical"   yaml:"canonical"`   // URL canonicalization
+	Watch       *WatchConfig     `json:"watch"       yaml:"watch"`       // Clipboard watcher
+	Remind      *RemindConfig    `json:"remind"      yaml:"remind"`      // Reminders
+	Journal     *JournalConfig   `json:"journal"     yaml:"journal"`     // Undo journal
+	History     *HistoryConfig   `json:"history"     yaml:"history"`     // Bookmarks history
+	Age         *AgeConfig       `json:"age"         yaml:"age"`         // Public-key encryption
+	Backup      *BackupConfig    `json:"backup"      yaml:"backup"`      // Backups retention
+}
+
+// BackupConfig holds the backups retention and automatic snapshots settings.
+//
+// A backup is kept if any rule keeps it. Retention is opt-in, with every
+// rule at 0, the default, all backups are kept and none is pruned.
+type BackupConfig struct {
+	KeepLast    int `json:"keep_last"    yaml:"keep_last"`    // Newest backups kept
+	KeepDaily   int `json:"keep_daily"   yaml:"keep_daily"`   // Days with their newest backup kept
+	KeepWeekly  int `json:"keep_weekly"  yaml:"keep_weekly"`  // Weeks with their newest backup kept
+	KeepMonthly int `json:"keep_monthly" yaml:"keep_monthly"` // Months with their newest backup kept
+	// Auto creates a backup before destructive operations, like drop,
+	// import, bulk remove, reordering IDs and migrations.
+	Auto bool `json:"auto" yaml:"auto"`
+	// Interval is the minimum time between automatic backups.
+	Interval time.Duration `json:"interval" yaml:"interval"`
+	// Compress compresses the new backups, with gzip or zstd. Encrypted
+	// backups are not compressed.
+	Compress string `json:"compress" yaml:"compress"`
+}
+
+// Backup holds the default backups configuration.
+var Backup = &BackupConfig{
+	Auto:     true,
+	Interval: 10 * time.Minute,
+}
+
+// AgeConfig holds the public-key encryption settings, using the age format.
+type AgeConfig struct {
+	// Recipients are the public keys (age1...) new backups are encrypted to.
+	Recipients []string `json:"recipients" yaml:"recipients"`
+	// RecipientsFile is a file with one recipient per line.
+	RecipientsFile string `json:"recipients_file" yaml:"recipients_file"`
+	// Identity is the identity file used to decrypt.
+	Identity string `json:"identity" yaml:"identity"`
+}
+
+// Age holds the default public-key encryption configuration.
+var Age = &AgeConfig{
+	Recipients: []string{},
+}
+
+// HistoryConfig holds the bookmarks history settings.
+type HistoryConfig struct {
+	// Revisions is the number of revisions kept per bookmark, 0 disables the
+	// history.
+	Revisions int `json:"revisions" yaml:"revisions"`
+}
+
+// History holds the default bookmarks history configuration.
+var History = &HistoryConfig{
+	Revisions: 50,
+}
+
+// JournalConfig holds the undo journal settings.
+type JournalConfig struct {
+	// Depth is the number of operations kept to undo, 0 disables the
+	// journal.
+	Depth int `json:"depth" yaml:"depth"`
+}
+
+// Journal holds the default undo journal configuration.
+var Journal = &JournalConfig{
+	Depth: 100,
+}
+
+// RemindConfig holds the reminders settings.
+type RemindConfig struct {
+	// NotifyCmd is the command run for each due bookmark, with the
+	// placeholders {id}, {url} and {title}. Uses notify-send if empty.
+	NotifyCmd []string `json:"notify_cmd" yaml:"notify_cmd"`
+}
+
+// Remind holds the default reminders configuration.
+var Remind = &RemindConfig{
+	NotifyCmd: []string{},
+}
+
+// WatchConfig holds the clipboard watcher settings.
+type WatchConfig struct {
+	Tag    string   `json:"tag"    yaml:"tag"`    // Tag of the queued URLs
+	Ignore []string `json:"ignore" yaml:"ignore"` // Domains never saved
+}
+
+// Watch holds the default clipboard watcher configuration.
+var Watch = &WatchConfig{
+	Tag:    "inbox",
+	Ignore: []string{"localhost", "127.0.0.1"},
+}
+
+// CanonicalConfig holds the URL canonicalization settings.
+type CanonicalConfig struct {
+	StripParams  []string `json:"strip_params"  yaml:"strip_params"`  // Query params to remove, `*` suffix matches a prefix
+	KeepFragment []string `json:"keep_fragment" yaml:"keep_fragment"` // Domains whose URL fragments are kept
+}
+
+// Canonical holds the default URL canonicalization configuration.
+var Canonical = &CanonicalConfig{
+	StripParams:  canonical.DefaultStripParams,
+	KeepFragment: []string{},
+}
+
+// Rules holds the auto-tagging rules.
+var Rules = []rules.Rule{}
+
+// ArchiveConfig holds the offline archive settings.
+type ArchiveConfig struct {
+	OnAdd bool `json:"on_add" yaml:"on_add"` // Save a snapshot when adding a bookmark
+}
+
+// Archive holds the default offline archive configuration.
+var Archive = &ArchiveConfig{
+	OnAdd: false,
+}
+
+// WaybackConfig holds the Wayback Machine endpoints.
+type WaybackConfig struct {
+	APIURL  string `json:"api_url"  yaml:"api_url"`  // Availability API endpoint
+	SaveURL string `json:"save_url" yaml:"save_url"` // Save page now endpoint
+}
+
+// Wayback holds the default Wayback Machine configuration.
+var Wayback = &WaybackConfig{
+	APIURL:  wayback.DefaultAPIURL,
+	SaveURL: wayback.DefaultSaveURL,
 }
 
 // fzfSettings are the options for FZF.
@@ -47,6 +186,13 @@ var Fzf = &menu.Config{
 		Yank:      menu.Keymap{Bind: "ctrl-y", Desc: "yank", Enabled: true, Hidden: false},
 		Preview:   menu.Keymap{Bind: "ctrl-/", Desc: "toggle-preview", Enabled: true, Hidden: false},
 		ToggleAll: menu.Keymap{Bind: "ctrl-a", Desc: "toggle-all", Enabled: true, Hidden: false},
+		Read:      menu.Keymap{Bind: "ctrl-r", Desc: "read", Enabled: true, Hidden: false},
+		Archive:   menu.Keymap{Bind: "ctrl-x", Desc: "archive", Enabled: true, Hidden: false},
+		Snooze:    menu.Keymap{Bind: "alt-s", Desc: "snooze", Enabled: true, Hidden: false},
+		MoveUp:    menu.Keymap{Bind: "alt-up", Desc: "move-up", Enabled: true, Hidden: false},
+		MoveDown:  menu.Keymap{Bind: "alt-down", Desc: "move-down", Enabled: true, Hidden: false},
+		AddTag:    menu.Keymap{Bind: "alt-t", Desc: "add-tag", Enabled: true, Hidden: false},
+		RemoveTag: menu.Keymap{Bind: "alt-r", Desc: "remove-tag", Enabled: true, Hidden: false},
 	},
 	Settings: fzfSettings,
 }
@@ -55,6 +201,82 @@ func fmtKeybindCmd(s string) string {
 	return fmt.Sprintf("execute(%s --name=%s records %s", App.Cmd, App.DBName, s)
 }
 
+// fmtSilentKeybindCmd runs the records command without leaving the menu.
+func fmtSilentKeybindCmd(s string) string {
+	return fmt.Sprintf("execute-silent(%s --name=%s records %s", App.Cmd, App.DBName, s)
+}
+
+// FzfKeybindRead keybind to mark the selected records as read.
+func FzfKeybindRead() menu.Keymap {
+	k := Fzf.Keymaps.Read
+	k.Action = fmtSilentKeybindCmd("--mark read {+1})")
+
+	return k
+}
+
+// FzfKeybindArchive keybind to mark the selected records as archived.
+func FzfKeybindArchive() menu.Keymap {
+	k := Fzf.Keymaps.Archive
+	k.Action = fmtSilentKeybindCmd("--mark archived {+1})")
+
+	return k
+}
+
+// FzfKeybindSnooze keybind to snooze the selected records, setting a
+// reminder for the next day.
+func FzfKeybindSnooze() menu.Keymap {
+	k := Fzf.Keymaps.Snooze
+	k.Action = fmtSilentKeybindCmd("--snooze 1d {+1})")
+
+	return k
+}
+
+// FzfKeybindMoveUp keybind to move the current record up in the collection.
+func FzfKeybindMoveUp(collection string) menu.Keymap {
+	k := Fzf.Keymaps.MoveUp
+	k.Action = fmt.Sprintf("execute-silent(%s --name=%s collection move %s {1} up)",
+		App.Cmd, App.DBName, collection)
+
+	return k
+}
+
+// FzfKeybindMoveDown keybind to move the current record down in the
+// collection.
+func FzfKeybindMoveDown(collection string) menu.Keymap {
+	k := Fzf.Keymaps.MoveDown
+	k.Action = fmt.Sprintf("execute-silent(%s --name=%s collection move %s {1} down)",
+		App.Cmd, App.DBName, collection)
+
+	return k
+}
+
+// FzfKeybindAddTag keybind to prompt for tags to add to the selected
+// records.
+func FzfKeybindAddTag() menu.Keymap {
+	k := Fzf.Keymaps.AddTag
+	k.Action = fmtKeybindCmd("--tag-prompt add {+1})")
+
+	return k
+}
+
+// FzfKeybindRemoveTag keybind to prompt for tags to remove from the selected
+// records.
+func FzfKeybindRemoveTag() menu.Keymap {
+	k := Fzf.Keymaps.RemoveTag
+	k.Action = fmtKeybindCmd("--tag-prompt remove {+1})")
+
+	return k
+}
+
+// FzfKeybindOpenAndRead keybind to open the selected records in the default
+// browser and mark them as read.
+func FzfKeybindOpenAndRead() menu.Keymap {
+	k := Fzf.Keymaps.Open
+	k.Action = fmtSilentKeybindCmd("--open --mark read {+1})")
+
+	return k
+}
+
 // FzfKeybindEdit keybind to edit the selected record.
 func FzfKeybindEdit() menu.Keymap {
 	return menu.Keymap{
@@ -129,6 +351,7 @@ var App = &AppConfig{
 	Env: environment{
 		Home:   "GOMARKS_HOME",
 		Editor: "GOMARKS_EDITOR",
+		Agent:  "GOMARKS_AGENT_SOCK",
 	},
 }
 
@@ -136,6 +359,16 @@ var App = &AppConfig{
 var Defaults = &ConfigFile{
 	Colorscheme: "default",
 	Menu:        Fzf,
+	Wayback:     Wayback,
+	Archive:     Archive,
+	Rules:       Rules,
+	Canonical:   Canonical,
+	Watch:       Watch,
+	Remind:      Remind,
+	Journal:     Journal,
+	History:     History,
+	Age:         Age,
+	Backup:      Backup,
 }
 
 // Validate validates the configuration file.
@@ -145,9 +378,92 @@ func Validate(cfg *ConfigFile) error {
 		cfg.Colorscheme = "default"
 	}
 
+	if cfg.Wayback == nil {
+		cfg.Wayback = Wayback
+	}
+	if cfg.Wayback.APIURL == "" {
+		cfg.Wayback.APIURL = wayback.DefaultAPIURL
+	}
+	if cfg.Wayback.SaveURL == "" {
+		cfg.Wayback.SaveURL = wayback.DefaultSaveURL
+	}
+	if cfg.Archive == nil {
+		cfg.Archive = Archive
+	}
+	if cfg.Canonical == nil {
+		cfg.Canonical = Canonical
+	}
+	if cfg.Canonical.StripParams == nil {
+		cfg.Canonical.StripParams = canonical.DefaultStripParams
+	}
+	if cfg.Menu != nil {
+		setDefaultKeymap(&cfg.Menu.Keymaps.Read, Fzf.Keymaps.Read)
+		setDefaultKeymap(&cfg.Menu.Keymaps.Archive, Fzf.Keymaps.Archive)
+		setDefaultKeymap(&cfg.Menu.Keymaps.Snooze, Fzf.Keymaps.Snooze)
+		setDefaultKeymap(&cfg.Menu.Keymaps.MoveUp, Fzf.Keymaps.MoveUp)
+		setDefaultKeymap(&cfg.Menu.Keymaps.MoveDown, Fzf.Keymaps.MoveDown)
+		setDefaultKeymap(&cfg.Menu.Keymaps.AddTag, Fzf.Keymaps.AddTag)
+		setDefaultKeymap(&cfg.Menu.Keymaps.RemoveTag, Fzf.Keymaps.RemoveTag)
+	}
+	if cfg.Watch == nil {
+		cfg.Watch = Watch
+	}
+	if cfg.Watch.Tag == "" {
+		cfg.Watch.Tag = Watch.Tag
+	}
+	if cfg.Remind == nil {
+		cfg.Remind = Remind
+	}
+	if cfg.Journal == nil {
+		cfg.Journal = Journal
+	}
+	if cfg.Journal.Depth < 0 {
+		slog.Warn("negative journal depth, loading default depth")
+		cfg.Journal.Depth = Journal.Depth
+	}
+	if cfg.History == nil {
+		cfg.History = History
+	}
+	if cfg.History.Revisions < 0 {
+		slog.Warn("negative history revisions, loading default revisions")
+		cfg.History.Revisions = History.Revisions
+	}
+	if cfg.Age == nil {
+		cfg.Age = Age
+	}
+	if cfg.Backup == nil {
+		cfg.Backup = Backup
+	}
+	if b := cfg.Backup; b.KeepLast < 0 || b.KeepDaily < 0 || b.KeepWeekly < 0 || b.KeepMonthly < 0 {
+		slog.Warn("negative backup retention, keeping all backups")
+		b.KeepLast, b.KeepDaily = Backup.KeepLast, Backup.KeepDaily
+		b.KeepWeekly, b.KeepMonthly = Backup.KeepWeekly, Backup.KeepMonthly
+	}
+	if cfg.Backup.Interval < 0 {
+		slog.Warn("negative backup interval, loading default interval")
+		cfg.Backup.Interval = Backup.Interval
+	}
+	switch cfg.Backup.Compress {
+	case "", "none", "gzip", "zstd":
+	default:
+		slog.Warn("unknown backup compression, backups not compressed", "compress", cfg.Backup.Compress)
+		cfg.Backup.Compress = ""
+	}
+	if _, err := rules.New(cfg.Rules); err != nil {
+		return fmt.Errorf("%w", err)
+	}
+
 	if err := cfg.Menu.Validate(); err != nil {
 		return fmt.Errorf("%w", err)
 	}
 
 	return nil
 }
+
+// setDefaultKeymap sets the default keymap if it is missing from the config
+// file, created with an older version.
+func setDefaultKeymap(k *menu.Keymap, def menu.Keymap) {
+	if k.Bind == "" && !k.Enabled {
+		*k = def
+	}
+}
@@ -335,3 +335,20 @@ func StripSuffixes(p string) string {
 
 	return p
 }
+
+// Size returns the size in bytes in a human readable form.
+//
+//	512 B, 1.5 KiB, 12.0 MiB
+func Size(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -146,3 +146,17 @@ func TestStripSuffixes(t *testing.T) {
 		assert.Equal(t, want, got)
 	})
 }
+
+func TestSize(t *testing.T) {
+	t.Parallel()
+	tests := map[int64]string{
+		0:                "0 B",
+		512:              "512 B",
+		1024:             "1.0 KiB",
+		1536:             "1.5 KiB",
+		12 * 1024 * 1024: "12.0 MiB",
+	}
+	for n, want := range tests {
+		assert.Equal(t, want, Size(n))
+	}
+}
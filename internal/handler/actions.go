@@ -168,9 +168,18 @@ func LockRepo(t *terminal.Term, rToLock string) error {
 	if err != nil {
 		return err
 	}
-	if err := locker.Lock(rToLock, pass); err != nil {
+	if pass == "" {
+		return locker.ErrPassphraseEmpty
+	}
+	k, err := locker.NewKey(pass)
+	if err != nil {
 		return fmt.Errorf("%w", err)
 	}
+	if err := locker.LockKey(rToLock, k); err != nil {
+		return fmt.Errorf("%w", err)
+	}
+	// the agent, if running, opens the database without asking again.
+	addAgentKey(k)
 	success := color.BrightGreen("Successfully").Italic().String()
 	fmt.Println(success + " database locked")
 
@@ -196,21 +205,80 @@ func UnlockRepo(t *terminal.Term, rToUnlock string) error {
 	if err := t.ConfirmErr(f.Question(q).String(), "y"); err != nil {
 		return fmt.Errorf("%w", err)
 	}
-	f.Clear().Question("Password: ").Flush()
-	s, err := t.InputPassword()
+	ciphertext, err := locker.ReadFile(rToUnlock)
 	if err != nil {
 		return fmt.Errorf("%w", err)
 	}
-	if err := locker.Unlock(rToUnlock, s); err != nil {
-		fmt.Println()
+	// the key comes from the agent, if running.
+	k, err := UnlockKey(rToUnlock, ciphertext)
+	if err != nil {
+		return err
+	}
+	if err := locker.UnlockKey(rToUnlock, k); err != nil {
 		return fmt.Errorf("%w", err)
 	}
-	success := color.BrightGreen("\nSuccessfully").Italic().String()
+	success := color.BrightGreen("Successfully").Italic().String()
 	fmt.Println(success + " database unlocked")
 
 	return nil
 }
 
+// RekeyRepo encrypts again a locked database created by an older version,
+// upgrading its key derivation.
+func RekeyRepo(t *terminal.Term, rToRekey string) error {
+	if err := locker.IsLocked(rToRekey); err == nil {
+		return fmt.Errorf("%w: %q", locker.ErrFileNotEncrypted, filepath.Base(rToRekey))
+	}
+	if !strings.HasSuffix(rToRekey, ".enc") {
+		rToRekey += ".enc"
+	}
+	slog.Debug("rekeying database", "name", rToRekey)
+	f := frame.New(frame.WithColorBorder(color.Gray))
+	f.Question("Password: ").Flush()
+	s, err := t.InputPassword()
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+	fmt.Println()
+	if err := locker.Rekey(rToRekey, s); err != nil {
+		return fmt.Errorf("%w", err)
+	}
+	success := color.BrightGreen("Successfully").Italic().String()
+	fmt.Println(success + " database rekeyed")
+
+	return nil
+}
+
+// PasswdRepo changes the password of a locked database, decrypting it in
+// memory.
+func PasswdRepo(t *terminal.Term, rToPasswd string) error {
+	if err := locker.IsLocked(rToPasswd); err == nil {
+		return fmt.Errorf("%w: %q", locker.ErrFileNotEncrypted, filepath.Base(rToPasswd))
+	}
+	if !strings.HasSuffix(rToPasswd, ".enc") {
+		rToPasswd += ".enc"
+	}
+	slog.Debug("changing database password", "name", rToPasswd)
+	f := frame.New(frame.WithColorBorder(color.Gray))
+	f.Question("Current Password: ").Flush()
+	old, err := t.InputPassword()
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+	fmt.Println()
+	pass, err := passwordConfirm(t, f.Clear().Mid(color.Gray("new password").Italic().String()).Ln())
+	if err != nil {
+		return err
+	}
+	if err := locker.Passwd(rToPasswd, old, pass); err != nil {
+		return fmt.Errorf("%w", err)
+	}
+	success := color.BrightGreen("Successfully").Italic().String()
+	fmt.Println(success + " password changed")
+
+	return nil
+}
+
 // openQR opens a QR-Code image in the system default image viewer.
 func openQR(qrcode *qr.QRCode, b *Bookmark) error {
 	const maxLabelLen = 55
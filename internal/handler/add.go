@@ -0,0 +1,401 @@
+package handler
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/haaag/rotato"
+	"golang.org/x/sync/semaphore"
+
+	"github.com/haaag/gm/internal/bookmark"
+	"github.com/haaag/gm/internal/bookmark/scraper"
+	"github.com/haaag/gm/internal/format"
+	"github.com/haaag/gm/internal/format/color"
+	"github.com/haaag/gm/internal/format/frame"
+	"github.com/haaag/gm/internal/repo"
+	"github.com/haaag/gm/internal/sys/terminal"
+)
+
+var (
+	ErrAddFailed       = errors.New("could not add URLs")
+	ErrIfExistsInvalid = errors.New("invalid --if-exists value, expected skip|update|error")
+	ErrSingleURLOnly   = errors.New("--title and --desc require a single URL")
+)
+
+// Policies for URLs already bookmarked.
+const (
+	IfExistsSkip   = "skip"
+	IfExistsUpdate = "update"
+	IfExistsError  = "error"
+)
+
+// Status of an added URL.
+const (
+	AddStatusAdded   = "added"
+	AddStatusUpdated = "updated"
+	AddStatusSkipped = "skipped"
+	AddStatusError   = "error"
+)
+
+// maxConFetch is the maximum number of concurrent page fetches.
+const maxConFetch = 10
+
+// AddOptions holds the options to add bookmarks without prompting.
+type AddOptions struct {
+	Title    string // Title of the bookmark, single URL only
+	Desc     string // Description of the bookmark, single URL only
+	Tags     string // Tags added to every bookmark
+	IfExists string // Policy for URLs already bookmarked
+	NoFetch  bool   // Do not scrape the page
+	Later    bool   // Add to the read-later inbox
+	Archive  bool   // Save an offline copy
+	AutoTag  bool   // Add the suggested tags
+}
+
+// AddEntry is a URL to add with its optional tags.
+type AddEntry struct {
+	URL  string
+	Tags string
+}
+
+// AddResult is the result of adding a URL.
+type AddResult struct {
+	URL    string `json:"url"`
+	ID     int    `json:"id,omitempty"`
+	Status string `json:"status"`
+	Title  string `json:"title,omitempty"`
+	Tags   string `json:"tags,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// fetched holds a bookmark ready to be stored.
+type fetched struct {
+	b      *Bookmark
+	text   string
+	exists *Bookmark
+	err    error
+	dup    int // position+1 of the first entry with the same URL, if repeated
+}
+
+// ValidateIfExists checks the policy for URLs already bookmarked.
+func ValidateIfExists(s string) error {
+	switch s {
+	case IfExistsSkip, IfExistsUpdate, IfExistsError:
+		return nil
+	}
+
+	return fmt.Errorf("%w: %q", ErrIfExistsInvalid, s)
+}
+
+// ParseAddEntries reads one URL per line, optionally followed by its tags
+// separated by spaces or commas. Empty lines and lines starting with `#` are
+// ignored.
+func ParseAddEntries(rd io.Reader) ([]AddEntry, error) {
+	var entries []AddEntry
+	sc := bufio.NewScanner(rd)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		entries = append(entries, AddEntry{
+			URL:  fields[0],
+			Tags: strings.Join(fields[1:], ","),
+		})
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("reading URLs: %w", err)
+	}
+
+	return entries, nil
+}
+
+// ReadAddEntries reads the entries from the file, or from stdin if the
+// path is `-`.
+func ReadAddEntries(p string) ([]AddEntry, error) {
+	if p == "-" {
+		return ParseAddEntries(os.Stdin)
+	}
+	f, err := os.Open(p)
+	if err != nil {
+		return nil, fmt.Errorf("%w", err)
+	}
+	defer func() {
+		if err := f.Close(); err != nil {
+			slog.Error("closing file", "file", p, "error", err)
+		}
+	}()
+
+	return ParseAddEntries(f)
+}
+
+// AddMany adds the entries without prompting, fetching the pages
+// concurrently, and returns a result for each entry.
+func AddMany(r *repo.SQLiteRepository, entries []AddEntry, o *AddOptions) ([]AddResult, error) {
+	if err := ValidateIfExists(o.IfExists); err != nil {
+		return nil, err
+	}
+	if len(entries) > 1 && (o.Title != "" || o.Desc != "") {
+		return nil, ErrSingleURLOnly
+	}
+
+	items := fetchEntries(r, entries, o)
+	results := make([]AddResult, 0, len(items))
+	for i := range items {
+		it := &items[i]
+		if it.dup > 0 {
+			// repeated in the batch, the first entry stored it already.
+			if first := results[it.dup-1]; first.Status == AddStatusError {
+				results = append(results, AddResult{URL: it.b.URL, Status: AddStatusError, Error: first.Error})
+				continue
+			}
+			it.exists, _ = r.HasCanonical(items[it.dup-1].b.URL)
+		}
+		results = append(results, storeFetched(r, it, o))
+	}
+
+	return results, nil
+}
+
+// fetchEntries builds the bookmarks of the entries, scraping the pages
+// concurrently while showing the progress.
+func fetchEntries(r *repo.SQLiteRepository, entries []AddEntry, o *AddOptions) []fetched {
+	items := make([]fetched, len(entries))
+	sp := rotato.New(
+		rotato.WithWriter(os.Stderr),
+		rotato.WithMesg(fmt.Sprintf("fetching 0/%d...", len(entries))),
+		rotato.WithMesgColor(rotato.ColorYellow),
+		rotato.WithSpinnerColor(rotato.ColorBrightMagenta),
+	)
+	if !o.NoFetch {
+		sp.Start()
+		defer sp.Done()
+	}
+
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		done int
+		sem  = semaphore.NewWeighted(maxConFetch)
+		ctx  = context.Background()
+		seen = make(map[string]int, len(entries))
+		idx  = newCanonicalIndex(r)
+	)
+	for i, e := range entries {
+		b, err := newEntryBookmark(&e, o)
+		if err != nil {
+			items[i] = fetched{b: b, err: err}
+			continue
+		}
+		// repeated canonical URLs are fetched once, stored by the first.
+		k := CanonicalURL(b.URL)
+		if first, ok := seen[k]; ok {
+			items[i] = fetched{b: b, dup: first + 1}
+			continue
+		}
+		seen[k] = i
+		items[i] = fetched{b: b}
+		if existing, ok := idx.find(r, b.URL); ok {
+			items[i].exists = existing
+			if o.IfExists != IfExistsUpdate {
+				continue
+			}
+		}
+		if o.NoFetch {
+			continue
+		}
+		if err := sem.Acquire(ctx, 1); err != nil {
+			items[i].err = fmt.Errorf("%w", err)
+			continue
+		}
+		wg.Add(1)
+		go func(it *fetched) {
+			defer wg.Done()
+			defer sem.Release(1)
+			it.text = scrapeEntry(ctx, it.b, o)
+			mu.Lock()
+			done++
+			sp.UpdateMesg(fmt.Sprintf("fetching %d/%d...", done, len(entries)))
+			mu.Unlock()
+		}(&items[i])
+	}
+	wg.Wait()
+
+	return items
+}
+
+// newEntryBookmark creates the bookmark of the entry with its cleaned URL.
+func newEntryBookmark(e *AddEntry, o *AddOptions) (*Bookmark, error) {
+	b := bookmark.New()
+	if !URLValid(e.URL) {
+		b.URL = e.URL
+		return b, fmt.Errorf("%w: %q", bookmark.ErrInvalid, e.URL)
+	}
+	b.URL = strings.TrimRight(CleanURL(e.URL), "/")
+	b.Title = o.Title
+	b.Desc = o.Desc
+	b.Tags = joinTags(e.Tags, o.Tags)
+	if o.Later {
+		b.SetState(bookmark.StateUnread)
+	}
+
+	return b, nil
+}
+
+// scrapeEntry fills the missing title, description and metadata of the
+// bookmark, and returns the readable text of the page.
+func scrapeEntry(ctx context.Context, b *Bookmark, o *AddOptions) string {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	sc := scraper.New(b.URL, scraper.WithContext(ctx))
+	if err := sc.Scrape(); err != nil {
+		slog.Warn("scraping", "url", b.URL, "error", err)
+		return ""
+	}
+	if o.Title == "" {
+		b.Title = sc.Title()
+	}
+	if o.Desc == "" {
+		b.Desc = strings.Join(format.SplitIntoChunks(sc.Desc(), terminal.MinWidth), "\n")
+	}
+	bookmark.ScrapeMetadata(b, sc)
+
+	return sc.Text()
+}
+
+// storeFetched inserts or updates the fetched bookmark according to the
+// options, and returns the result.
+func storeFetched(r *repo.SQLiteRepository, it *fetched, o *AddOptions) AddResult {
+	res := AddResult{URL: it.b.URL}
+	fail := func(err error) AddResult {
+		res.Status = AddStatusError
+		res.Error = err.Error()
+		return res
+	}
+	if it.err != nil {
+		return fail(it.err)
+	}
+	if it.exists != nil {
+		res.ID = it.exists.ID
+		switch o.IfExists {
+		case IfExistsSkip:
+			res.Status = AddStatusSkipped
+			return res
+		case IfExistsError:
+			return fail(fmt.Errorf("%w with id=%d", bookmark.ErrDuplicate, it.exists.ID))
+		}
+	}
+
+	b := it.b
+	if o.AutoTag {
+		b.Tags = joinTags(b.Tags, strings.Join(SuggestTags(r, b, it.text), ","))
+	}
+	ApplyRules(b)
+	if o.Archive {
+		if text, err := ArchivePage(b); err != nil {
+			slog.Warn("archiving webpage", "url", b.URL, "error", err)
+		} else if text != "" {
+			it.text = text
+		}
+	}
+
+	ctx := context.Background()
+	if it.exists != nil {
+		updated := mergeExisting(it.exists, b)
+		if _, err := r.UpdateOne(ctx, &updated, it.exists); err != nil {
+			return fail(err)
+		}
+		b = &updated
+		res.Status = AddStatusUpdated
+	} else {
+		if b.Title == "" {
+			b.Title = b.URL
+		}
+		if err := bookmark.Validate(b); err != nil {
+			return fail(err)
+		}
+		if err := r.InsertOne(ctx, b); err != nil {
+			// repeated URL in the same batch
+			if errors.Is(err, repo.ErrRecordDuplicate) && o.IfExists == IfExistsSkip {
+				res.Status = AddStatusSkipped
+				return res
+			}
+
+			return fail(err)
+		}
+		res.Status = AddStatusAdded
+	}
+	IndexBookmarkContent(r, b, it.text)
+	res.ID = b.ID
+	res.Title = b.Title
+	res.Tags = b.Tags
+
+	return res
+}
+
+// mergeExisting returns the existing bookmark updated with the fetched
+// data and the union of the tags.
+func mergeExisting(old, b *Bookmark) Bookmark {
+	m := *old
+	m.Tags = joinTags(old.Tags, b.Tags)
+	m.Title = firstNonEmpty(b.Title, old.Title)
+	m.Desc = firstNonEmpty(b.Desc, old.Desc)
+	if !b.Meta.Empty() {
+		m.Meta = b.Meta
+		m.CanonicalURL = b.CanonicalURL
+	}
+	m.ArchiveHash = firstNonEmpty(b.ArchiveHash, old.ArchiveHash)
+
+	return m
+}
+
+// joinTags joins the lists of tags, dropping the `notag` placeholder when
+// there are other tags.
+func joinTags(tags ...string) string {
+	var all []string
+	for _, t := range tags {
+		all = append(all, strings.FieldsFunc(t, func(r rune) bool {
+			return r == ',' || r == ' '
+		})...)
+	}
+
+	return bookmark.ParseTags(strings.Join(removeNoTag(all), ","))
+}
+
+// PrintAddResults prints a summary of the added URLs.
+func PrintAddResults(results []AddResult) {
+	f := frame.New(frame.WithColorBorder(color.Gray))
+	counts := make(map[string]int)
+	for _, res := range results {
+		counts[res.Status]++
+		var status string
+		switch res.Status {
+		case AddStatusAdded:
+			status = color.BrightGreen(fmt.Sprintf("%-7s", res.Status)).Bold().String()
+		case AddStatusUpdated:
+			status = color.BrightBlue(fmt.Sprintf("%-7s", res.Status)).Bold().String()
+		case AddStatusSkipped:
+			status = color.Yellow(fmt.Sprintf("%-7s", res.Status)).Bold().String()
+		default:
+			status = color.Red(fmt.Sprintf("%-7s", res.Status)).Bold().String()
+		}
+		bid := fmt.Sprintf(color.BrightGray("%-3d").String(), res.ID)
+		url := color.Gray(format.Shorten(res.URL, terminal.MinWidth)).Italic().String()
+		f.Row(fmt.Sprintf(" %s %s %s", status, bid, url)).Ln()
+		if res.Error != "" {
+			f.Row("     " + color.Red(res.Error).Italic().String()).Ln()
+		}
+	}
+	total := fmt.Sprintf("%d added, %d updated, %d skipped, %d failed",
+		counts[AddStatusAdded], counts[AddStatusUpdated], counts[AddStatusSkipped], counts[AddStatusError])
+	f.Footer(total + "\n").Flush()
+}
@@ -0,0 +1,123 @@
+package handler
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/haaag/gm/internal/repo"
+)
+
+func testRepo(t *testing.T) *repo.SQLiteRepository {
+	t.Helper()
+	p := filepath.Join(t.TempDir(), "test.db")
+	f, err := os.Create(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = f.Close()
+	r, err := repo.New(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Init(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(r.Close)
+
+	return r
+}
+
+func TestParseAddEntries(t *testing.T) {
+	t.Parallel()
+	in := `# reading list
+https://example.com/a go cli
+
+https://example.com/b  news,tech
+`
+	entries, err := ParseAddEntries(strings.NewReader(in))
+	assert.NoError(t, err)
+	assert.Equal(t, []AddEntry{
+		{URL: "https://example.com/a", Tags: "go,cli"},
+		{URL: "https://example.com/b", Tags: "news,tech"},
+	}, entries)
+}
+
+func TestJoinTags(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, "cli,go,", joinTags("go,notag,", "cli go"))
+	assert.Equal(t, "notag", joinTags("", ""))
+}
+
+func TestAddMany(t *testing.T) {
+	t.Parallel()
+	r := testRepo(t)
+	entries := []AddEntry{
+		{URL: "https://Example.com/a?utm_source=x", Tags: "go"},
+		{URL: "not a url"},
+		{URL: "https://example.com/a", Tags: "cli"},
+	}
+	o := &AddOptions{Tags: "batch", IfExists: IfExistsSkip, NoFetch: true}
+	results, err := AddMany(r, entries, o)
+	assert.NoError(t, err)
+	assert.Len(t, results, 3)
+	assert.Equal(t, AddStatusAdded, results[0].Status)
+	assert.Equal(t, "https://example.com/a", results[0].URL)
+	assert.Equal(t, "batch,go,", results[0].Tags)
+	assert.Equal(t, 1, results[0].ID)
+	assert.Equal(t, AddStatusError, results[1].Status)
+	assert.Equal(t, AddStatusSkipped, results[2].Status)
+
+	o.IfExists = IfExistsUpdate
+	results, err = AddMany(r, entries[2:], o)
+	assert.NoError(t, err)
+	assert.Equal(t, AddStatusUpdated, results[0].Status)
+	assert.Equal(t, "batch,cli,go,", results[0].Tags)
+
+	o.IfExists = IfExistsError
+	results, err = AddMany(r, entries[2:], o)
+	assert.NoError(t, err)
+	assert.Equal(t, AddStatusError, results[0].Status)
+
+	o.IfExists = "replace"
+	_, err = AddMany(r, entries, o)
+	assert.ErrorIs(t, err, ErrIfExistsInvalid)
+
+	_, err = AddMany(r, entries, &AddOptions{Title: "t", IfExists: IfExistsSkip})
+	assert.ErrorIs(t, err, ErrSingleURLOnly)
+}
+
+func TestAddManyRepeated(t *testing.T) {
+	t.Parallel()
+	entries := []AddEntry{
+		{URL: "https://example.com/a", Tags: "go"},
+		{URL: "https://example.com/a/?utm_source=x", Tags: "cli"},
+	}
+	tests := []struct {
+		ifExists string
+		want     string
+		tags     string
+	}{
+		{ifExists: IfExistsSkip, want: AddStatusSkipped, tags: "go,"},
+		{ifExists: IfExistsUpdate, want: AddStatusUpdated, tags: "cli,go,"},
+		{ifExists: IfExistsError, want: AddStatusError},
+	}
+	for _, tt := range tests {
+		r := testRepo(t)
+		o := &AddOptions{IfExists: tt.ifExists, NoFetch: true}
+		results, err := AddMany(r, entries, o)
+		assert.NoError(t, err)
+		assert.Equal(t, AddStatusAdded, results[0].Status, tt.ifExists)
+		assert.Equal(t, tt.want, results[1].Status, tt.ifExists)
+		assert.NotContains(t, results[1].Error, "UNIQUE", tt.ifExists)
+		assert.Equal(t, 1, repo.CountMainRecords(r))
+		if tt.tags != "" {
+			b, err := r.ByID(results[0].ID)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.tags, b.Tags, tt.ifExists)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"path/filepath"
+
+	"filippo.io/age"
+
+	"github.com/haaag/gm/internal/config"
+	"github.com/haaag/gm/internal/format/color"
+	"github.com/haaag/gm/internal/format/frame"
+	"github.com/haaag/gm/internal/locker"
+	"github.com/haaag/gm/internal/repo"
+	"github.com/haaag/gm/internal/sys/files"
+	"github.com/haaag/gm/internal/sys/terminal"
+)
+
+// ageKey returns the key of the file at p, encrypted to age recipients,
+// decrypting with the configured identity file.
+//
+// The file is encrypted back to the recipients recorded when locked. Without
+// them the key only decrypts and the database is opened read-only, so it is
+// never encrypted to fewer recipients than it was.
+func ageKey(p string) (*locker.Key, error) {
+	ids, err := locker.ParseIdentities(files.ExpandHomeDir(config.Age.Identity))
+	if err != nil {
+		return nil, fmt.Errorf("%w", err)
+	}
+	rs, err := locker.ReadRecipients(p)
+	if err == nil {
+		return locker.NewAgeKey(ids, rs), nil
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%w", err)
+	}
+	slog.Warn("no recipients recorded, opening read-only; lock it again with --recipient to edit", "path", p)
+
+	return locker.NewAgeKey(ids, nil), nil
+}
+
+// configRecipients returns the recipients of the configuration.
+func configRecipients() ([]age.Recipient, error) {
+	rs, err := locker.ParseRecipients(config.Age.Recipients, files.ExpandHomeDir(config.Age.RecipientsFile))
+	if err != nil {
+		return nil, fmt.Errorf("%w", err)
+	}
+
+	return rs, nil
+}
+
+// LockRepoTo locks the database to the age recipients, any of their
+// identities can unlock it.
+func LockRepoTo(t *terminal.Term, rToLock string, keys []string, file string) error {
+	slog.Debug("locking database to recipients", "name", rToLock, "recipients", len(keys))
+	if err := locker.IsLocked(rToLock); err != nil {
+		return fmt.Errorf("%w", err)
+	}
+	if !files.Exists(rToLock) {
+		return fmt.Errorf("%w: %q", files.ErrFileNotFound, filepath.Base(rToLock))
+	}
+	rs, err := locker.ParseRecipients(keys, files.ExpandHomeDir(file))
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+	if len(rs) == 0 {
+		return fmt.Errorf("%w", locker.ErrAgeNoRecipients)
+	}
+	f := frame.New(frame.WithColorBorder(color.Gray))
+	q := fmt.Sprintf("Lock %q to %d recipients?", filepath.Base(rToLock), len(rs))
+	if err := t.ConfirmErr(f.Question(q).String(), "y"); err != nil {
+		if errors.Is(err, terminal.ErrActionAborted) {
+			return nil
+		}
+
+		return fmt.Errorf("%w", err)
+	}
+	if err := locker.LockKey(rToLock, locker.NewAgeKey(nil, rs)); err != nil {
+		return fmt.Errorf("%w", err)
+	}
+	if err := locker.WriteRecipients(rToLock+".enc", rs); err != nil {
+		return fmt.Errorf("%w", err)
+	}
+	success := color.BrightGreen("Successfully").Italic().String()
+	fmt.Println(success + " database locked")
+
+	return nil
+}
+
+// LockBackup locks the new backup to the configured recipients, without
+// asking. Reports false if there are no recipients configured.
+func LockBackup(bkPath string) (bool, error) {
+	if repo.IsEncrypted(bkPath) {
+		// encrypted already, like the backups of encrypted databases.
+		return true, nil
+	}
+	rs, err := configRecipients()
+	if err != nil {
+		return false, err
+	}
+	if len(rs) == 0 {
+		return false, nil
+	}
+	if err := locker.LockKey(bkPath, locker.NewAgeKey(nil, rs)); err != nil {
+		return false, fmt.Errorf("%w", err)
+	}
+	if err := locker.WriteRecipients(bkPath+".enc", rs); err != nil {
+		return false, fmt.Errorf("%w", err)
+	}
+	if err := repo.MoveBackupEntry(bkPath, bkPath+".enc"); err != nil {
+		slog.Warn("updating backups manifest", "error", err)
+	}
+	slog.Info("backup encrypted to recipients", "path", bkPath, "recipients", len(rs))
+
+	return true, nil
+}
@@ -0,0 +1,161 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"github.com/haaag/gm/internal/agent"
+	"github.com/haaag/gm/internal/config"
+	"github.com/haaag/gm/internal/format/color"
+	"github.com/haaag/gm/internal/format/frame"
+	"github.com/haaag/gm/internal/locker"
+	"github.com/haaag/gm/internal/menu"
+	"github.com/haaag/gm/internal/repo"
+	"github.com/haaag/gm/internal/sys/terminal"
+)
+
+// keys holds the keys derived in the process, by ID.
+var keys = make(map[string]*locker.Key)
+
+// UnlockKey returns the key of the encrypted file, from the agent if it is
+// running, otherwise prompting for the passphrase once. A new key is added
+// to the agent.
+//
+// Files encrypted to age recipients use the configured identity instead.
+func UnlockKey(p string, ciphertext []byte) (*locker.Key, error) {
+	if locker.IsAge(ciphertext) {
+		return ageKey(p)
+	}
+	id, err := locker.KeyID(ciphertext)
+	if err != nil {
+		return nil, fmt.Errorf("%w", err)
+	}
+	if k, ok := keys[id]; ok && id != "" {
+		return k, nil
+	}
+	if k := agentKey(id); k != nil {
+		if _, err := k.Decrypt(ciphertext); err == nil {
+			keys[id] = k
+			return k, nil
+		}
+		k.Zero()
+	}
+	pass, err := promptPassphrase(p)
+	if err != nil {
+		return nil, err
+	}
+	k, err := locker.DeriveKey(ciphertext, pass)
+	if err != nil {
+		return nil, fmt.Errorf("%w", err)
+	}
+	if _, err := k.Decrypt(ciphertext); err != nil {
+		k.Zero()
+		return nil, fmt.Errorf("%w", err)
+	}
+	if id == "" {
+		slog.Warn("legacy encrypted file, not cached by the agent", "path", p)
+		return k, nil
+	}
+	keys[id] = k
+	addAgentKey(k)
+
+	return k, nil
+}
+
+// agentKey returns the key with the given ID from the agent, if any.
+func agentKey(id string) *locker.Key {
+	if id == "" {
+		return nil
+	}
+	b, err := agent.Get(id)
+	if err != nil {
+		if !errors.Is(err, agent.ErrNotRunning) && !errors.Is(err, agent.ErrKeyNotFound) {
+			slog.Warn("agent", "error", err)
+		}
+
+		return nil
+	}
+	defer clear(b)
+	k := &locker.Key{}
+	if err := k.UnmarshalBinary(b); err != nil {
+		slog.Warn("agent", "error", err)
+		return nil
+	}
+
+	return k
+}
+
+// addAgentKey adds the key to the agent, if it is running.
+func addAgentKey(k *locker.Key) {
+	if k.ID() == "" {
+		return
+	}
+	b, err := k.MarshalBinary()
+	if err != nil {
+		return
+	}
+	defer clear(b)
+	if err := agent.Add(k.ID(), b); err != nil && !errors.Is(err, agent.ErrNotRunning) {
+		slog.Warn("agent", "error", err)
+	}
+}
+
+// promptPassphrase prompts for the passphrase of the encrypted file. The
+// prompt goes to stderr, keeping the output clean.
+func promptPassphrase(p string) (string, error) {
+	f := frame.New(frame.WithColorBorder(color.Gray))
+	q := fmt.Sprintf("Password for %q: ", filepath.Base(p))
+	fmt.Fprint(os.Stderr, f.Question(q).String())
+	s, err := terminal.New().InputPassword()
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", fmt.Errorf("%w", err)
+	}
+	if s == "" {
+		return "", locker.ErrPassphraseEmpty
+	}
+
+	return s, nil
+}
+
+// childUnlocks reports whether a child process, like the menu preview and
+// keybinds, opens the database at p without a prompt: a plain or age
+// database, or one whose key is held by the agent.
+func childUnlocks(p string) bool {
+	if !repo.IsEncrypted(p) {
+		return true
+	}
+	ciphertext, err := locker.ReadFile(p)
+	if err != nil {
+		return false
+	}
+	if locker.IsAge(ciphertext) {
+		return true
+	}
+	id, err := locker.KeyID(ciphertext)
+	if err != nil || id == "" {
+		return false
+	}
+	b, err := agent.Get(id)
+	if err != nil {
+		return false
+	}
+	clear(b)
+
+	return true
+}
+
+// childOpts returns the menu options that run commands on the database in a
+// child process, if it can open it. A child process has no terminal to
+// prompt for the passphrase.
+func childOpts(opts ...menu.OptFn) []menu.OptFn {
+	if childUnlocks(config.App.DBPath) {
+		return opts
+	}
+	slog.Warn("menu preview and keybinds disabled, start the agent to enable them", "path", config.App.DBPath)
+
+	return nil
+}
@@ -0,0 +1,180 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/haaag/gm/internal/bookmark"
+	"github.com/haaag/gm/internal/config"
+	"github.com/haaag/gm/internal/format"
+	"github.com/haaag/gm/internal/format/color"
+	"github.com/haaag/gm/internal/format/frame"
+	"github.com/haaag/gm/internal/repo"
+	"github.com/haaag/gm/internal/sys/terminal"
+)
+
+// ErrBatchTagAction is returned when the tag prompt action is unknown.
+var ErrBatchTagAction = errors.New("invalid tag action, expected add|remove")
+
+// noTag is the tag of the bookmarks without tags.
+const noTag = "notag"
+
+// BatchOp holds the field operations applied to a selection of bookmarks.
+type BatchOp struct {
+	AddTags    []string // tags to add
+	RemoveTags []string // tags to remove
+	Title      *string  // new title, if set
+	Desc       *string  // new description, if set
+	Favorite   *bool    // new favorite status, if set
+}
+
+// Empty reports whether the operation changes nothing.
+func (o *BatchOp) Empty() bool {
+	return len(o.AddTags) == 0 && len(o.RemoveTags) == 0 &&
+		o.Title == nil && o.Desc == nil && o.Favorite == nil
+}
+
+// apply applies the operation to the bookmark, returning a description of
+// each field changed.
+func (o *BatchOp) apply(b *Bookmark) []string {
+	var changes []string
+	if tags := o.tags(b.Tags); tags != b.Tags {
+		changes = append(changes, tagsDiff(b.Tags, tags))
+		b.Tags = tags
+	}
+	if o.Title != nil && *o.Title != b.Title {
+		changes = append(changes, fmt.Sprintf("title %q → %q", b.Title, *o.Title))
+		b.Title = *o.Title
+	}
+	if o.Desc != nil && *o.Desc != b.Desc {
+		changes = append(changes, fmt.Sprintf("desc %q → %q", format.Shorten(b.Desc, 20), format.Shorten(*o.Desc, 20)))
+		b.Desc = *o.Desc
+	}
+	if o.Favorite != nil && *o.Favorite != b.Favorite {
+		changes = append(changes, "favorite → "+strconv.FormatBool(*o.Favorite))
+		b.Favorite = *o.Favorite
+	}
+
+	return changes
+}
+
+// tags returns the tags after removing and adding the operation tags.
+func (o *BatchOp) tags(s string) string {
+	tags := splitTags(s)
+	rm := splitTags(strings.Join(o.RemoveTags, ","))
+	tags = slices.DeleteFunc(tags, func(t string) bool {
+		return t == noTag || slices.Contains(rm, t)
+	})
+	tags = append(tags, splitTags(strings.Join(o.AddTags, ","))...)
+
+	return bookmark.ParseTags(strings.Join(tags, ","))
+}
+
+// splitTags splits the comma or space separated tags.
+func splitTags(s string) []string {
+	return strings.FieldsFunc(s, func(r rune) bool {
+		return r == ',' || r == ' '
+	})
+}
+
+// tagsDiff describes the added and removed tags.
+func tagsDiff(before, after string) string {
+	old, cur := splitTags(before), splitTags(after)
+	var d []string
+	for _, t := range cur {
+		if !slices.Contains(old, t) {
+			d = append(d, "+"+t)
+		}
+	}
+	for _, t := range old {
+		if !slices.Contains(cur, t) {
+			d = append(d, "-"+t)
+		}
+	}
+
+	return "tags " + strings.Join(d, " ")
+}
+
+// BatchUpdate prints the changes of the operation to the bookmarks and,
+// once confirmed, applies them in a single transaction.
+func BatchUpdate(t *terminal.Term, r *repo.SQLiteRepository, bs *Slice, op *BatchOp) error {
+	c, changes := batchChanges(bs, op)
+	f := frame.New(frame.WithColorBorder(color.BrightBlue))
+	if c.Len() == 0 {
+		f.Warning("no changes\n").Flush()
+		return nil
+	}
+	f.Header(color.BrightYellow("Batch Update:").String()).Ln().Row().Ln()
+	pad := len(strconv.Itoa(c.Update[len(c.Update)-1].New.ID))
+	for i, u := range c.Update {
+		id := color.BrightWhite(fmt.Sprintf("%*d", pad, u.New.ID)).Bold().String()
+		url := color.Gray(format.Shorten(u.New.URL, terminal.MinWidth)).Italic().String()
+		f.Mid(fmt.Sprintf("%s %s", id, url)).Ln()
+		for _, ch := range changes[i] {
+			f.Row(fmt.Sprintf("%*s %s", pad, "", color.BrightGreen(ch))).Ln()
+		}
+	}
+	f.Flush()
+	if !config.App.Force {
+		q := fmt.Sprintf("%s %d bookmarks?", color.BrightYellow("update").Bold(), c.Len())
+		if err := t.ConfirmErr(f.Clear().Row("\n").Question(q).String(), "n"); err != nil {
+			return fmt.Errorf("%w", err)
+		}
+	}
+	if err := r.ApplyChanges(repo.WithSource(context.Background(), "batch"), c); err != nil {
+		return fmt.Errorf("%w", err)
+	}
+	fmt.Printf("%s: %s%s\n", config.App.Name, color.Blue(fmt.Sprintf("%d updated", c.Len())).Bold(), oplogHint())
+
+	return nil
+}
+
+// batchChanges returns the updates of the bookmarks changed by the
+// operation, and the description of each update.
+func batchChanges(bs *Slice, op *BatchOp) (*repo.Changes, [][]string) {
+	c := &repo.Changes{}
+	var changes [][]string
+	bs.ForEach(func(b Bookmark) {
+		old, cur := b, b
+		d := op.apply(&cur)
+		if len(d) == 0 {
+			return
+		}
+		c.Update = append(c.Update, repo.Change{New: &cur, Old: &old})
+		changes = append(changes, d)
+	})
+
+	return c, changes
+}
+
+// BatchTagPrompt prompts for the tags to add to or remove from the
+// bookmarks, with completion of the existing tags.
+func BatchTagPrompt(t *terminal.Term, r *repo.SQLiteRepository, bs *Slice, action string) (*BatchOp, error) {
+	if action != "add" && action != "remove" {
+		return nil, fmt.Errorf("%w: %q", ErrBatchTagAction, action)
+	}
+	mTags, err := repo.CounterTags(r)
+	if err != nil {
+		return nil, fmt.Errorf("%w", err)
+	}
+	f := frame.New(frame.WithColorBorder(color.BrightBlue))
+	q := fmt.Sprintf("%s tags to %d bookmarks", action, bs.Len())
+	if action == "remove" {
+		q = fmt.Sprintf("remove tags from %d bookmarks", bs.Len())
+	}
+	f.Header(color.BrightYellow(q).String()).
+		Text(color.Gray(" (spaces|comma separated)").Italic().String()).Ln().Flush()
+	tags := splitTags(t.ChooseTags(f.Border.Mid, mTags))
+	op := &BatchOp{}
+	if action == "add" {
+		op.AddTags = tags
+	} else {
+		op.RemoveTags = tags
+	}
+
+	return op, nil
+}
@@ -0,0 +1,77 @@
+package handler
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/haaag/gm/internal/slice"
+)
+
+func TestBatchOpApply(t *testing.T) {
+	t.Parallel()
+	title, fav := "new title", true
+	tests := []struct {
+		name    string
+		op      BatchOp
+		b       Bookmark
+		want    Bookmark
+		changes []string
+	}{
+		{
+			name:    "add and remove tags",
+			op:      BatchOp{AddTags: []string{"x", "go"}, RemoveTags: []string{"y"}},
+			b:       Bookmark{Tags: "go,y,"},
+			want:    Bookmark{Tags: "go,x,"},
+			changes: []string{"tags +x -y"},
+		},
+		{
+			name:    "remove last tag",
+			op:      BatchOp{RemoveTags: []string{"go"}},
+			b:       Bookmark{Tags: "go,"},
+			want:    Bookmark{Tags: "notag"},
+			changes: []string{"tags +notag -go"},
+		},
+		{
+			name:    "add to untagged",
+			op:      BatchOp{AddTags: []string{"go"}},
+			b:       Bookmark{Tags: "notag,"},
+			want:    Bookmark{Tags: "go,"},
+			changes: []string{"tags +go -notag"},
+		},
+		{
+			name:    "set fields",
+			op:      BatchOp{Title: &title, Favorite: &fav},
+			b:       Bookmark{Title: "old", Tags: "go,"},
+			want:    Bookmark{Title: "new title", Tags: "go,", Favorite: true},
+			changes: []string{`title "old" → "new title"`, "favorite → true"},
+		},
+		{
+			name: "unchanged",
+			op:   BatchOp{AddTags: []string{"go"}, Title: &title},
+			b:    Bookmark{Title: "new title", Tags: "go,"},
+			want: Bookmark{Title: "new title", Tags: "go,"},
+		},
+	}
+	for _, tt := range tests {
+		b := tt.b
+		assert.Equal(t, tt.changes, tt.op.apply(&b), tt.name)
+		assert.Equal(t, tt.want, b, tt.name)
+	}
+}
+
+func TestBatchChanges(t *testing.T) {
+	t.Parallel()
+	bs := slice.New[Bookmark]()
+	bs.Append(
+		Bookmark{ID: 1, URL: "https://example.com/a", Tags: "go,"},
+		Bookmark{ID: 2, URL: "https://example.com/b", Tags: "go,x,"},
+	)
+	c, changes := batchChanges(bs, &BatchOp{AddTags: []string{"x"}})
+	assert.Equal(t, 1, c.Len())
+	assert.Equal(t, 1, c.Update[0].New.ID)
+	assert.Equal(t, "go,x,", c.Update[0].New.Tags)
+	assert.Equal(t, "go,", c.Update[0].Old.Tags)
+	assert.Equal(t, [][]string{{"tags +x"}}, changes)
+	assert.True(t, (&BatchOp{}).Empty())
+}
@@ -0,0 +1,166 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/haaag/gm/internal/bookmark"
+	"github.com/haaag/gm/internal/config"
+	"github.com/haaag/gm/internal/format"
+	"github.com/haaag/gm/internal/format/color"
+	"github.com/haaag/gm/internal/format/frame"
+	"github.com/haaag/gm/internal/repo"
+	"github.com/haaag/gm/internal/sys/files"
+	"github.com/haaag/gm/internal/sys/terminal"
+)
+
+// ErrBulkUnknownID is returned when a record of the bulk buffer is not one
+// of the edited bookmarks.
+var ErrBulkUnknownID = errors.New("id not in the edited bookmarks")
+
+// BulkEdition edits the bookmarks in a single buffer, then applies the
+// creates, updates and deletes in one transaction.
+//
+// If the buffer has errors, the editor is reopened at the offending record,
+// keeping the changes.
+func BulkEdition(r *repo.SQLiteRepository, bs *Slice) error {
+	if bs.Empty() {
+		return repo.ErrRecordQueryNotProvided
+	}
+	te, err := files.NewEditor(config.App.Env.Editor)
+	if err != nil {
+		return fmt.Errorf("getting editor: %w", err)
+	}
+	c, err := editBulk(te, bs)
+	if err != nil {
+		if errors.Is(err, bookmark.ErrBufferUnchanged) {
+			return nil
+		}
+
+		return err
+	}
+	f := frame.New(frame.WithColorBorder(color.BrightBlue))
+	if c.Len() == 0 {
+		f.Warning("no changes\n").Flush()
+		return nil
+	}
+	f.Header(color.BrightYellow("Bulk Edition:\n\n").String()).Flush()
+	fmt.Println(format.ColorDiff(te.Diff(bulkDiffBuffers(c))))
+
+	f.Clear()
+	q := color.Text(fmt.Sprintf("apply %s?", bulkSummary(c))).Bold().String()
+	if !config.App.Force && !terminal.Confirm(f.Question(q).String(), "y") {
+		return nil
+	}
+	if err := r.ApplyChanges(repo.WithSource(context.Background(), "bulk edit"), c); err != nil {
+		return fmt.Errorf("%w", err)
+	}
+	fmt.Printf("%s: %s%s\n", config.App.Name, color.Blue(bulkSummary(c)).Bold(), oplogHint())
+
+	return nil
+}
+
+// editBulk opens the bulk buffer in the editor until it is parsed without
+// errors or the user gives up.
+func editBulk(te *files.TextEditor, bs *Slice) (*repo.Changes, error) {
+	original := bookmark.BulkBuffer(*bs.Items())
+	content, line := original, 0
+	for {
+		data, err := te.EditBytesAt(content, config.App.Name, line)
+		if err != nil {
+			return nil, fmt.Errorf("failed to edit content: %w", err)
+		}
+		if bytes.Equal(data, original) {
+			return nil, bookmark.ErrBufferUnchanged
+		}
+		c, err := bulkChanges(data, bs)
+		if err == nil {
+			return c, nil
+		}
+		var be *bookmark.BulkError
+		if !errors.As(err, &be) {
+			return nil, err
+		}
+		f := frame.New(frame.WithColorBorder(color.BrightRed))
+		f.Error(err.Error() + "\n")
+		q := f.Question("reopen the editor?").String()
+		if !terminal.Confirm(q, "y") {
+			return nil, fmt.Errorf("%w", err)
+		}
+		content, line = bookmark.MarkBulkError(data, be.Line, be.Err)
+	}
+}
+
+// bulkChanges compares the records of the buffer with the edited bookmarks.
+//
+// Records without ID are created, and the bookmarks missing from the
+// buffer are deleted.
+func bulkChanges(data []byte, bs *Slice) (*repo.Changes, error) {
+	records, err := bookmark.ParseBulk(data)
+	if err != nil {
+		return nil, fmt.Errorf("%w", err)
+	}
+	originals := make(map[int]*Bookmark, bs.Len())
+	for _, b := range *bs.Items() {
+		originals[b.ID] = &b
+	}
+	c := &repo.Changes{}
+	kept := make(map[int]bool, len(records))
+	urls := make(map[string]bool, len(records))
+	for _, rec := range records {
+		b := rec.B
+		if !URLValid(b.URL) {
+			return nil, &bookmark.BulkError{Line: rec.Line, Err: fmt.Errorf("%w: %q", bookmark.ErrInvalid, b.URL)}
+		}
+		if urls[b.URL] {
+			return nil, &bookmark.BulkError{Line: rec.Line, Err: fmt.Errorf("%w: %q", bookmark.ErrBulkDuplicate, b.URL)}
+		}
+		urls[b.URL] = true
+		if rec.ID == 0 {
+			c.Create = append(c.Create, b)
+			continue
+		}
+		o, ok := originals[rec.ID]
+		if !ok {
+			return nil, &bookmark.BulkError{Line: rec.Line, Err: fmt.Errorf("%w: %d", ErrBulkUnknownID, rec.ID)}
+		}
+		kept[rec.ID] = true
+		if o.Equals(b) {
+			continue
+		}
+		b.CopyUnedited(o)
+		c.Update = append(c.Update, repo.Change{New: b, Old: o})
+	}
+	for _, b := range *bs.Items() {
+		if !kept[b.ID] {
+			c.Delete = append(c.Delete, originals[b.ID])
+		}
+	}
+
+	return c, nil
+}
+
+// bulkDiffBuffers returns the buffers of the changed records, before and
+// after the edition.
+func bulkDiffBuffers(c *repo.Changes) (before, after []byte) {
+	var old, cur []Bookmark
+	for _, u := range c.Update {
+		old = append(old, *u.Old)
+		cur = append(cur, *u.New)
+	}
+	for _, b := range c.Delete {
+		old = append(old, *b)
+	}
+	for _, b := range c.Create {
+		cur = append(cur, *b)
+	}
+
+	return bookmark.BulkRecords(old), bookmark.BulkRecords(cur)
+}
+
+// bulkSummary returns the number of changes by kind.
+func bulkSummary(c *repo.Changes) string {
+	return fmt.Sprintf("%d updated, %d created, %d deleted", len(c.Update), len(c.Create), len(c.Delete))
+}
@@ -0,0 +1,66 @@
+package handler
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/haaag/gm/internal/bookmark"
+	"github.com/haaag/gm/internal/slice"
+)
+
+func testBulkSlice() *Slice {
+	bs := slice.New[Bookmark]()
+	bs.Append(
+		Bookmark{ID: 1, URL: "https://example.com/a", Title: "A", Tags: "go,"},
+		Bookmark{ID: 2, URL: "https://example.com/b", Title: "B", Tags: "go,"},
+		Bookmark{ID: 3, URL: "https://example.com/c", Title: "C", Tags: "go,", Favorite: true},
+	)
+
+	return bs
+}
+
+func TestBulkChanges(t *testing.T) {
+	t.Parallel()
+	bs := testBulkSlice()
+	buf := string(bookmark.BulkBuffer(*bs.Items()))
+	// update the first one, delete the second one and create a new one
+	buf = strings.Replace(buf, "https://example.com/a", "https://example.com/a2", 1)
+	start := strings.Index(buf, "@@ 2 @@")
+	end := strings.Index(buf, "@@ 3 @@")
+	buf = buf[:start] + buf[end:]
+	buf += string(bookmark.BulkRecords([]Bookmark{{URL: "https://example.com/d", Tags: "new,"}}))
+
+	c, err := bulkChanges([]byte(buf), bs)
+	assert.NoError(t, err)
+	assert.Len(t, c.Update, 1)
+	assert.Equal(t, "https://example.com/a2", c.Update[0].New.URL)
+	assert.Equal(t, "https://example.com/a", c.Update[0].Old.URL)
+	assert.Len(t, c.Create, 1)
+	assert.Equal(t, "https://example.com/d", c.Create[0].URL)
+	assert.Len(t, c.Delete, 1)
+	assert.Equal(t, 2, c.Delete[0].ID)
+	assert.Equal(t, 3, c.Len())
+}
+
+func TestBulkChangesErrors(t *testing.T) {
+	t.Parallel()
+	bs := testBulkSlice()
+	buf := string(bookmark.BulkBuffer(*bs.Items()))
+	tests := []struct {
+		name string
+		buf  string
+		err  error
+	}{
+		{"unknown id", strings.Replace(buf, "@@ 3 @@", "@@ 9 @@", 1), ErrBulkUnknownID},
+		{"invalid url", strings.Replace(buf, "https://example.com/c", "not a url", 1), bookmark.ErrInvalid},
+		{"duplicate url", strings.Replace(buf, "https://example.com/c", "https://example.com/b", 1), bookmark.ErrBulkDuplicate},
+	}
+	for _, tt := range tests {
+		_, err := bulkChanges([]byte(tt.buf), bs)
+		var be *bookmark.BulkError
+		assert.ErrorAs(t, err, &be, tt.name)
+		assert.ErrorIs(t, err, tt.err, tt.name)
+	}
+}
@@ -0,0 +1,331 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+
+	"github.com/haaag/gm/internal/config"
+	"github.com/haaag/gm/internal/format"
+	"github.com/haaag/gm/internal/format/color"
+	"github.com/haaag/gm/internal/format/frame"
+	"github.com/haaag/gm/internal/menu"
+	"github.com/haaag/gm/internal/repo"
+	"github.com/haaag/gm/internal/sys/terminal"
+)
+
+var (
+	// ErrCollectionEmpty is returned when the collection has no bookmarks.
+	ErrCollectionEmpty = errors.New("collection is empty")
+	// ErrCollectionsNotFound is returned when there are no collections.
+	ErrCollectionsNotFound = errors.New("no collections found")
+	// ErrExportFormat is returned when the export format is unknown.
+	ErrExportFormat = errors.New("invalid export format, expected md|html")
+)
+
+// NewCollection creates a new collection.
+func NewCollection(r *repo.SQLiteRepository, name, desc string) error {
+	c, err := r.NewCollection(context.Background(), name, desc)
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+	f := frame.New(frame.WithColorBorder(color.Gray))
+	n := color.BrightGreen(c.Name).Italic().String()
+	f.Success(fmt.Sprintf("collection %s created\n", n)).Flush()
+
+	return nil
+}
+
+// ListCollections prints the collections and their number of bookmarks.
+func ListCollections(r *repo.SQLiteRepository, j bool) error {
+	cs, err := r.Collections()
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+	if len(cs) == 0 {
+		if j {
+			fmt.Println("[]")
+			return nil
+		}
+
+		return ErrCollectionsNotFound
+	}
+	if j {
+		fmt.Println(string(format.ToJSON(cs)))
+		return nil
+	}
+	f := frame.New(frame.WithColorBorder(color.Gray))
+	for _, c := range cs {
+		n := color.BrightCyan(c.Name).Bold().String()
+		count := color.Gray(fmt.Sprintf("(%d)", c.Count)).Italic().String()
+		f.Mid(fmt.Sprintf("%s %s", n, count))
+		if c.Desc != "" {
+			f.Text(" " + color.White(c.Desc).Italic().String())
+		}
+		f.Ln()
+	}
+	f.Flush()
+
+	return nil
+}
+
+// CollectionRecords loads the bookmarks of the collection, in order.
+func CollectionRecords(r *repo.SQLiteRepository, name string, bs *Slice) (*repo.Collection, error) {
+	c, err := r.Collection(name)
+	if err != nil {
+		return nil, fmt.Errorf("%w", err)
+	}
+	if err := r.ByCollection(c, bs); err != nil {
+		return nil, fmt.Errorf("%w", err)
+	}
+
+	return c, nil
+}
+
+// OrderByIDs sorts the bookmarks in the order their IDs appear in the
+// arguments, the ones not found in the arguments go last.
+func OrderByIDs(bs *Slice, args []string) {
+	idx := make(map[int]int, len(args))
+	for i, a := range args {
+		if id, err := strconv.Atoi(a); err == nil {
+			if _, ok := idx[id]; !ok {
+				idx[id] = i
+			}
+		}
+	}
+	pos := func(b Bookmark) int {
+		if i, ok := idx[b.ID]; ok {
+			return i
+		}
+
+		return len(args) + b.ID
+	}
+	bs.Sort(func(a, b Bookmark) bool {
+		return pos(a) < pos(b)
+	})
+}
+
+// CollectionAdd appends the bookmarks to the collection.
+func CollectionAdd(r *repo.SQLiteRepository, name string, bs *Slice) error {
+	c, err := r.Collection(name)
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+	before := c.Count
+	if err := r.AddToCollection(context.Background(), c, bs); err != nil {
+		return fmt.Errorf("%w", err)
+	}
+	f := frame.New(frame.WithColorBorder(color.Gray))
+	n := color.BrightGreen(c.Name).Italic().String()
+	f.Success(fmt.Sprintf("added %d bookmarks to %s\n", c.Count-before, n)).Flush()
+
+	return nil
+}
+
+// CollectionRemove removes the bookmarks from the collection.
+func CollectionRemove(r *repo.SQLiteRepository, name string, bs *Slice) error {
+	c, err := r.Collection(name)
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+	before := c.Count
+	if err := r.RemoveFromCollection(context.Background(), c, bs); err != nil {
+		return fmt.Errorf("%w", err)
+	}
+	f := frame.New(frame.WithColorBorder(color.Gray))
+	n := color.BrightGreen(c.Name).Italic().String()
+	f.Success(fmt.Sprintf("removed %d bookmarks from %s\n", before-c.Count, n)).Flush()
+
+	return nil
+}
+
+// RemoveCollection removes the collection after confirmation, the bookmarks
+// are kept.
+func RemoveCollection(t *terminal.Term, r *repo.SQLiteRepository, name string) error {
+	c, err := r.Collection(name)
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+	f := frame.New(frame.WithColorBorder(color.Gray))
+	n := color.BrightRed(c.Name).Italic().String()
+	if !config.App.Force {
+		q := f.Question(fmt.Sprintf("remove collection %s with %d bookmarks?", n, c.Count)).String()
+		if err := t.ConfirmErr(q, "n"); err != nil {
+			return fmt.Errorf("%w", err)
+		}
+		f.Clear()
+	}
+	if err := r.RemoveCollection(context.Background(), c); err != nil {
+		return fmt.Errorf("%w", err)
+	}
+	f.Success(fmt.Sprintf("collection %s removed\n", n)).Flush()
+
+	return nil
+}
+
+// CollectionMove moves the bookmark in the collection to a position, or
+// `up`, `down`, `top` and `bottom`.
+func CollectionMove(r *repo.SQLiteRepository, name string, b *Bookmark, to string) error {
+	c, err := r.Collection(name)
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+	cur, err := r.CollectionPosition(c, b)
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+	pos, err := collectionTarget(to, cur, c.Count)
+	if err != nil {
+		return err
+	}
+	if pos == cur {
+		return nil
+	}
+
+	return r.MoveInCollection(context.Background(), c, b, pos)
+}
+
+// collectionTarget returns the position to move an item at the current
+// position, clamped to the collection size.
+func collectionTarget(to string, cur, n int) (int, error) {
+	var pos int
+	switch to {
+	case "up":
+		pos = cur - 1
+	case "down":
+		pos = cur + 1
+	case "top":
+		pos = 1
+	case "bottom":
+		pos = n
+	default:
+		p, err := strconv.Atoi(to)
+		if err != nil {
+			return 0, fmt.Errorf("%w: %q", repo.ErrCollectionPosition, to)
+		}
+		pos = p
+	}
+
+	return max(1, min(pos, n)), nil
+}
+
+// PrintCollection prints the bookmarks of the collection, numbered in
+// order.
+func PrintCollection(c *repo.Collection, bs *Slice) error {
+	if bs.Empty() {
+		return fmt.Errorf("%w: %q", ErrCollectionEmpty, c.Name)
+	}
+	w := terminal.MinWidth
+	f := frame.New(frame.WithColorBorder(color.Gray))
+	f.Header(color.BrightCyan(c.Name).Bold().String())
+	if c.Desc != "" {
+		f.Text(" " + color.White(c.Desc).Italic().String())
+	}
+	f.Ln().Row().Ln()
+	pad := len(strconv.Itoa(bs.Len()))
+	bs.ForEachIdx(func(i int, b Bookmark) {
+		n := color.BrightYellow(fmt.Sprintf("%*d.", pad, i+1)).Bold().String()
+		title := firstNonEmpty(b.Title, b.URL)
+		f.Mid(fmt.Sprintf("%s %s", n, color.BrightWhite(format.Shorten(title, w)))).Ln()
+		u := format.Shorten(b.URL, w)
+		f.Row(fmt.Sprintf("%*s %s", pad+1, "", color.Gray(u).Italic())).Ln()
+	})
+	f.Flush()
+
+	return nil
+}
+
+// SelectFromCollection lets the user select the bookmarks from the
+// collection menu, where they can be reordered.
+func SelectFromCollection(c *repo.Collection, bs *Slice) error {
+	items, err := SelectionWithMenu(menuForCollection(c.Name), *bs.Items(), fzfFormatter(false))
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+	bs.Set(&items)
+
+	return nil
+}
+
+// menuForCollection returns a FZF menu for the collection.
+//
+// The items are reloaded synchronously after moving them, so the cursor
+// can follow the moved item.
+func menuForCollection(name string) *menu.Menu[Bookmark] {
+	reload := fmt.Sprintf("%s --name=%s collection show %s --oneline --width=%d",
+		config.App.Cmd, config.App.DBName, name, terminal.MaxWidth)
+	up := config.FzfKeybindMoveUp(name)
+	up.Action += "+reload-sync(" + reload + ")+up"
+	down := config.FzfKeybindMoveDown(name)
+	down.Action += "+reload-sync(" + reload + ")+down"
+
+	mo := []menu.OptFn{
+		menu.WithUseDefaults(),
+		menu.WithSettings(config.Fzf.Settings),
+		menu.WithMultiSelection(),
+		menu.WithHeader("collection: "+name, false),
+	}
+	mo = append(mo, childOpts(
+		menu.WithPreview(config.App.Cmd+" --name "+config.App.DBName+" records {1}"),
+		menu.WithKeybinds(
+			up,
+			down,
+			config.FzfKeybindEdit(),
+			config.FzfKeybindOpen(),
+			config.FzfKeybindYank(),
+		),
+	)...)
+
+	return menu.New[Bookmark](mo...)
+}
+
+// ExportCollection renders the collection, in order, as Markdown or HTML.
+func ExportCollection(c *repo.Collection, bs *Slice, f string) (string, error) {
+	switch f {
+	case "md", "markdown":
+		return collectionMarkdown(c, bs), nil
+	case "html":
+		return collectionHTML(c, bs), nil
+	}
+
+	return "", fmt.Errorf("%w: %q", ErrExportFormat, f)
+}
+
+// collectionMarkdown renders the collection as a Markdown ordered list.
+func collectionMarkdown(c *repo.Collection, bs *Slice) string {
+	var sb strings.Builder
+	sb.WriteString("# " + c.Name + "\n\n")
+	if c.Desc != "" {
+		sb.WriteString(c.Desc + "\n\n")
+	}
+	esc := strings.NewReplacer(`\`, `\\`, "[", `\[`, "]", `\]`)
+	bs.ForEachIdx(func(i int, b Bookmark) {
+		title := esc.Replace(firstNonEmpty(b.Title, b.URL))
+		fmt.Fprintf(&sb, "%d. [%s](<%s>)\n", i+1, title, b.URL)
+	})
+
+	return sb.String()
+}
+
+// collectionHTML renders the collection as an HTML page with an ordered
+// list.
+func collectionHTML(c *repo.Collection, bs *Slice) string {
+	var sb strings.Builder
+	name := html.EscapeString(c.Name)
+	sb.WriteString("<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n")
+	fmt.Fprintf(&sb, "<title>%s</title>\n</head>\n<body>\n<h1>%s</h1>\n", name, name)
+	if c.Desc != "" {
+		fmt.Fprintf(&sb, "<p>%s</p>\n", html.EscapeString(c.Desc))
+	}
+	sb.WriteString("<ol>\n")
+	bs.ForEach(func(b Bookmark) {
+		title := html.EscapeString(firstNonEmpty(b.Title, b.URL))
+		fmt.Fprintf(&sb, "  <li><a href=\"%s\">%s</a></li>\n", html.EscapeString(b.URL), title)
+	})
+	sb.WriteString("</ol>\n</body>\n</html>\n")
+
+	return sb.String()
+}
@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/haaag/gm/internal/repo"
+	"github.com/haaag/gm/internal/slice"
+)
+
+func testCollection() (*repo.Collection, *Slice) {
+	c := &repo.Collection{Name: "onboarding", Desc: "first <week>"}
+	bs := slice.New[Bookmark]()
+	bs.Append(
+		Bookmark{ID: 3, URL: "https://example.com/setup", Title: "Setup [dev]"},
+		Bookmark{ID: 1, URL: "https://example.com/?a=1&b=2"},
+	)
+
+	return c, bs
+}
+
+func TestExportCollection(t *testing.T) {
+	t.Parallel()
+	c, bs := testCollection()
+
+	md, err := ExportCollection(c, bs, "md")
+	assert.NoError(t, err)
+	want := "# onboarding\n\nfirst <week>\n\n" +
+		"1. [Setup \\[dev\\]](<https://example.com/setup>)\n" +
+		"2. [https://example.com/?a=1&b=2](<https://example.com/?a=1&b=2>)\n"
+	assert.Equal(t, want, md)
+
+	h, err := ExportCollection(c, bs, "html")
+	assert.NoError(t, err)
+	assert.Contains(t, h, "<p>first &lt;week&gt;</p>")
+	assert.Contains(t, h, "<ol>\n  <li><a href=\"https://example.com/setup\">Setup [dev]</a></li>\n"+
+		"  <li><a href=\"https://example.com/?a=1&amp;b=2\">https://example.com/?a=1&amp;b=2</a></li>\n</ol>")
+
+	_, err = ExportCollection(c, bs, "pdf")
+	assert.ErrorIs(t, err, ErrExportFormat)
+}
+
+func TestCollectionTarget(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		to   string
+		cur  int
+		want int
+	}{
+		{"up", 3, 2},
+		{"up", 1, 1},
+		{"down", 5, 5},
+		{"top", 4, 1},
+		{"bottom", 1, 5},
+		{"2", 4, 2},
+		{"9", 1, 5},
+	}
+	for _, tt := range tests {
+		got, err := collectionTarget(tt.to, tt.cur, 5)
+		assert.NoError(t, err)
+		assert.Equal(t, tt.want, got, tt.to)
+	}
+	_, err := collectionTarget("first", 1, 5)
+	assert.ErrorIs(t, err, repo.ErrCollectionPosition)
+}
+
+func TestOrderByIDs(t *testing.T) {
+	t.Parallel()
+	bs := slice.New[Bookmark]()
+	for _, id := range []int{1, 2, 3, 4} {
+		bs.Append(Bookmark{ID: id})
+	}
+	OrderByIDs(bs, []string{"3", "1", "4"})
+	ids := make([]int, 0, bs.Len())
+	bs.ForEach(func(b Bookmark) {
+		ids = append(ids, b.ID)
+	})
+	assert.Equal(t, []int{3, 1, 4, 2}, ids)
+}
@@ -0,0 +1,209 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/haaag/rotato"
+
+	"github.com/haaag/gm/internal/bookmark/scraper"
+	"github.com/haaag/gm/internal/bookmark/snapshot"
+	"github.com/haaag/gm/internal/format"
+	"github.com/haaag/gm/internal/format/color"
+	"github.com/haaag/gm/internal/format/frame"
+	"github.com/haaag/gm/internal/repo"
+	"github.com/haaag/gm/internal/sys/terminal"
+)
+
+// contentPrefix is the query prefix to search the indexed page content.
+const contentPrefix = "content:"
+
+// splitContentQuery splits the args into the page content terms and the
+// rest of the query. Only the args starting with the content prefix are
+// taken, the rest are passed through untouched.
+//
+//	"content:go generics content:types" -> "go generics", "types"
+func splitContentQuery(args []string) (terms, rest []string) {
+	for _, arg := range args {
+		if !strings.HasPrefix(arg, contentPrefix) {
+			rest = append(rest, arg)
+			continue
+		}
+		for _, t := range strings.Split(arg, contentPrefix) {
+			if t = strings.TrimSpace(t); t != "" {
+				terms = append(terms, t)
+			}
+		}
+	}
+
+	return terms, rest
+}
+
+// contentQuery builds the full-text query from the terms, all terms must
+// match. Each term is a quoted phrase, with its inner quotes doubled.
+func contentQuery(terms []string) string {
+	q := make([]string, 0, len(terms))
+	for _, t := range terms {
+		t = strings.Trim(t, `"`)
+		if t != "" {
+			q = append(q, `"`+strings.ReplaceAll(t, `"`, `""`)+`"`)
+		}
+	}
+
+	return strings.Join(q, " ")
+}
+
+// ByContent keeps the bookmarks whose page content matches the terms.
+func ByContent(r *repo.SQLiteRepository, bs *Slice, terms []string) error {
+	q := contentQuery(terms)
+	matches, err := r.ContentMatches(q)
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+	urls := make(map[string]bool, len(matches))
+	for _, m := range matches {
+		urls[m.URL] = true
+	}
+	bs.FilterInPlace(func(b *Bookmark) bool {
+		return urls[b.URL]
+	})
+	if bs.Empty() {
+		return fmt.Errorf("%w by content: %q", repo.ErrRecordNoMatch, strings.Join(terms, " "))
+	}
+
+	return nil
+}
+
+// PrintWithSnippet prints the bookmarks with the page content matching the
+// query.
+func PrintWithSnippet(r *repo.SQLiteRepository, bs *Slice, q string) error {
+	if err := Print(bs); err != nil {
+		return err
+	}
+	terms, _ := splitContentQuery([]string{q})
+	if len(terms) == 0 {
+		return nil
+	}
+	matches, err := r.ContentMatches(contentQuery(terms))
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+	snippets := make(map[string]string, len(matches))
+	for _, m := range matches {
+		snippets[m.URL] = m.Snippet
+	}
+
+	w := terminal.MinWidth
+	f := frame.New(frame.WithColorBorder(color.Gray))
+	w -= len(f.Border.Row)
+	bs.ForEach(func(b Bookmark) {
+		s, ok := snippets[b.URL]
+		if !ok {
+			return
+		}
+		f.Ln().Header(color.BrightGreen("content:").Italic().String()).Ln()
+		s = strings.Join(strings.Fields(s), " ")
+		f.Mid(color.ApplyMany(format.SplitIntoChunks(s, w), color.Gray)...).Ln()
+	})
+	f.Flush()
+
+	return nil
+}
+
+// previewSnippet returns the preview flag to show the matching content
+// snippet, if the args contain a content query.
+func previewSnippet(args []string) string {
+	terms, _ := splitContentQuery(args)
+	if len(terms) == 0 {
+		return ""
+	}
+	q := contentPrefix + strings.Join(terms, " "+contentPrefix)
+
+	return " --snippet '" + strings.ReplaceAll(q, "'", `'\''`) + "'"
+}
+
+// IndexBookmarkContent indexes the readable text of the bookmark page.
+func IndexBookmarkContent(r *repo.SQLiteRepository, b *Bookmark, text string) {
+	if text == "" {
+		return
+	}
+	if _, err := r.IndexContent(context.Background(), b.URL, text); err != nil {
+		slog.Error("indexing content", "url", b.URL, "error", err)
+	}
+}
+
+// Index indexes the readable text of the bookmarked pages.
+//
+// The offline copy is used when available, only changed pages are
+// re-indexed.
+func Index(r *repo.SQLiteRepository, bs *Slice) error {
+	n := bs.Len()
+	if n == 0 {
+		return repo.ErrRecordQueryNotProvided
+	}
+
+	const maxItems = 15
+	q := fmt.Sprintf("%s %d bookmarks, continue?", color.BrightGreen("indexing").Bold(), n)
+	if err := confirmUserLimit(n, maxItems, q); err != nil {
+		return err
+	}
+
+	var indexed, unchanged, failed int
+	st := snapshotStore()
+	sp := rotato.New(
+		rotato.WithMesg("indexing content..."),
+		rotato.WithMesgColor(rotato.ColorGray),
+		rotato.WithSpinnerColor(rotato.ColorBrightGreen),
+	)
+	sp.Start()
+	bs.ForEach(func(b Bookmark) {
+		sp.UpdateMesg("indexing " + format.Shorten(b.URL, terminal.MinWidth) + "...")
+		text := pageText(st, &b)
+		if text == "" {
+			failed++
+			return
+		}
+		ok, err := r.IndexContent(context.Background(), b.URL, text)
+		switch {
+		case err != nil:
+			slog.Error("indexing content", "url", b.URL, "error", err)
+			failed++
+		case ok:
+			indexed++
+		default:
+			unchanged++
+		}
+	})
+	sp.Done()
+
+	f := frame.New(frame.WithColorBorder(color.Gray))
+	f.Header(color.BrightGreen("Indexed content:\n").Bold().String())
+	f.Mid(fmt.Sprintf("%-3d updated\n", indexed))
+	f.Mid(fmt.Sprintf("%-3d unchanged\n", unchanged))
+	if failed > 0 {
+		f.Mid(color.Red(fmt.Sprintf("%-3d failed\n", failed)).String())
+	}
+	f.Footer(fmt.Sprintf("Total %s checked\n", color.Blue(n).Bold())).Flush()
+
+	return nil
+}
+
+// pageText returns the readable text of the bookmark page, from the offline
+// copy if available.
+func pageText(st *snapshot.Store, b *Bookmark) string {
+	if b.ArchiveHash != "" {
+		data, err := st.Get(b.ArchiveHash)
+		if err == nil {
+			return scraper.TextFromHTML(data)
+		}
+		slog.Warn("reading offline copy", "url", b.URL, "error", err)
+	}
+	sc := scraper.New(b.URL)
+	if err := sc.Scrape(); err != nil {
+		return ""
+	}
+
+	return sc.Text()
+}
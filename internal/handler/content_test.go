@@ -0,0 +1,56 @@
+package handler
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/haaag/gm/internal/bookmark"
+	"github.com/haaag/gm/internal/slice"
+)
+
+func TestSplitContentQuery(t *testing.T) {
+	t.Parallel()
+	terms, rest := splitContentQuery([]string{"golang", "content:generics", "content:go tutorial content:type"})
+	assert.Equal(t, []string{"generics", "go tutorial", "type"}, terms)
+	assert.Equal(t, []string{"golang"}, rest)
+
+	// args without the prefix are left untouched, like quoted phrases
+	terms, rest = splitContentQuery([]string{"go generics", "rust"})
+	assert.Empty(t, terms)
+	assert.Equal(t, []string{"go generics", "rust"}, rest)
+
+	terms, rest = splitContentQuery(nil)
+	assert.Empty(t, terms)
+	assert.Empty(t, rest)
+}
+
+func TestContentQuery(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, `"generics" "type"`, contentQuery([]string{"generics", `"type"`}))
+	assert.Empty(t, contentQuery([]string{`""`}))
+	assert.Equal(t, `"say ""hi"" now"`, contentQuery([]string{`say "hi" now`}))
+}
+
+func TestByContentQuote(t *testing.T) {
+	t.Parallel()
+	r := testRepo(t)
+	ctx := context.Background()
+	b := bookmark.New()
+	b.URL = "https://example.com/quotes"
+	assert.NoError(t, r.InsertOne(ctx, b))
+	_, err := r.IndexContent(ctx, b.URL, `the a"b quoting rules`)
+	assert.NoError(t, err)
+
+	bs := slice.New[Bookmark]()
+	bs.Push(b)
+	assert.NoError(t, ByContent(r, bs, []string{`a"b`}))
+	assert.Equal(t, 1, bs.Len())
+}
+
+func TestPreviewSnippet(t *testing.T) {
+	t.Parallel()
+	assert.Empty(t, previewSnippet([]string{"golang"}))
+	assert.Equal(t, ` --snippet 'content:it'\''s content:go'`, previewSnippet([]string{"content:it's", "content:go"}))
+}
@@ -31,6 +31,7 @@ type (
 // Records gets records based on user input and filtering criteria.
 func Records(r *repo.SQLiteRepository, bs *Slice, args []string) error {
 	slog.Debug("records", "args", args)
+	terms, args := splitContentQuery(args)
 	if err := ByIDs(r, bs, args); err != nil {
 		return fmt.Errorf("%w", err)
 	}
@@ -46,6 +47,10 @@ func Records(r *repo.SQLiteRepository, bs *Slice, args []string) error {
 		}
 	}
 
+	if len(terms) > 0 {
+		return ByContent(r, bs, terms)
+	}
+
 	return nil
 }
 
@@ -95,6 +100,12 @@ func Data(
 			return nil, fmt.Errorf("%w", err)
 		}
 	}
+	// filter by read-later state, if the command supports it
+	if f := cmd.Flags().Lookup("state"); f != nil && f.Value.String() != "" {
+		if err := ByState(bs, f.Value.String()); err != nil {
+			return nil, err
+		}
+	}
 	// filter by head and tail
 	head, err := cmd.Flags().GetInt("head")
 	if err != nil {
@@ -174,7 +185,7 @@ func prepareBuffer(r *repo.SQLiteRepository, b *bookmark.Bookmark, idx, total in
 // updateBookmark updates the repository with the modified bookmark.
 // It calls UpdateURL if the bookmark's URL changed, otherwise it calls Update.
 func updateBookmark(r *repo.SQLiteRepository, b, original *bookmark.Bookmark) error {
-	ctx := context.Background()
+	ctx := repo.WithSource(context.Background(), "edit")
 	if _, err := r.UpdateOne(ctx, b, original); err != nil {
 		return fmt.Errorf("updating record: %w", err)
 	}
@@ -211,13 +222,14 @@ func removeRecords(r *repo.SQLiteRepository, bs *Slice) error {
 	}
 	success := color.BrightGreen("Successfully").Italic().String()
 	f := frame.New(frame.WithColorBorder(color.Gray))
-	f.Success(success + " bookmark/s removed\n").Flush()
+	f.Success(success + " bookmark/s removed" + oplogHint() + "\n").Flush()
 
 	return nil
 }
 
-// insertRecordsToRepo inserts records into the database.
-func insertRecordsToRepo(t *terminal.Term, r *repo.SQLiteRepository, records *Slice) error {
+// insertRecordsToRepo inserts records into the database, recording the
+// source of the import in their history.
+func insertRecordsToRepo(t *terminal.Term, r *repo.SQLiteRepository, records *Slice, src string) error {
 	f := frame.New(frame.WithColorBorder(color.BrightGray))
 	if !config.App.Force {
 		report := fmt.Sprintf("import %d records?", records.Len())
@@ -230,7 +242,7 @@ func insertRecordsToRepo(t *terminal.Term, r *repo.SQLiteRepository, records *Sl
 		rotato.WithMesgColor(rotato.ColorYellow),
 	)
 	sp.Start()
-	if err := r.InsertMany(context.Background(), records); err != nil {
+	if err := r.InsertMany(repo.WithSource(context.Background(), "import "+src), records); err != nil {
 		return fmt.Errorf("%w", err)
 	}
 	sp.Done()
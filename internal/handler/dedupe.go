@@ -0,0 +1,258 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+
+	"github.com/haaag/gm/internal/bookmark"
+	"github.com/haaag/gm/internal/bookmark/canonical"
+	"github.com/haaag/gm/internal/config"
+	"github.com/haaag/gm/internal/format"
+	"github.com/haaag/gm/internal/format/color"
+	"github.com/haaag/gm/internal/format/frame"
+	"github.com/haaag/gm/internal/repo"
+	"github.com/haaag/gm/internal/slice"
+	"github.com/haaag/gm/internal/sys"
+	"github.com/haaag/gm/internal/sys/terminal"
+)
+
+// ErrNoDuplicates is returned when no near-duplicate bookmarks are found.
+var ErrNoDuplicates = errors.New("no duplicate bookmarks found")
+
+// canonicalizer returns the URL canonicalizer from the app configuration.
+func canonicalizer() *canonical.Canonicalizer {
+	return canonical.New(
+		canonical.WithStripParams(config.Canonical.StripParams...),
+		canonical.WithKeepFragment(config.Canonical.KeepFragment...),
+	)
+}
+
+// CanonicalURL returns the canonical form of the URL, the key used to find
+// duplicates.
+func CanonicalURL(s string) string {
+	return canonicalizer().URL(s)
+}
+
+// CleanURL returns the URL without tracking params, with the host lowercased
+// and without the default port.
+func CleanURL(s string) string {
+	return canonicalizer().Clean(s)
+}
+
+// cleanURLs cleans the URL of the bookmarks.
+func cleanURLs(bs *Slice) {
+	c := canonicalizer()
+	bs.ForEachMut(func(b *Bookmark) {
+		b.URL = c.Clean(b.URL)
+	})
+}
+
+// canonicalIndex maps the canonical form of the stored URLs to their
+// bookmarks.
+type canonicalIndex map[string]*Bookmark
+
+// newCanonicalIndex indexes the bookmarks of the repository by the canonical
+// form of their URLs.
+func newCanonicalIndex(r *repo.SQLiteRepository) canonicalIndex {
+	bs := slice.New[Bookmark]()
+	if err := r.All(bs); err != nil && !errors.Is(err, repo.ErrRecordNotFound) {
+		slog.Error("indexing canonical URLs", "error", err)
+	}
+	c := canonicalizer()
+	idx := make(canonicalIndex, bs.Len())
+	for _, b := range *bs.Items() {
+		k := c.URL(b.URL)
+		if _, ok := idx[k]; !ok {
+			idx[k] = &b
+		}
+	}
+
+	return idx
+}
+
+// find returns the stored bookmark with the same URL or canonical URL as u,
+// or with the same canonical form.
+func (idx canonicalIndex) find(r *repo.SQLiteRepository, u string) (*Bookmark, bool) {
+	if b, ok := r.HasCanonical(u); ok {
+		return b, true
+	}
+	b, ok := idx[CanonicalURL(u)]
+
+	return b, ok
+}
+
+// Duplicate returns the stored bookmark that is a duplicate of the URL.
+func Duplicate(r *repo.SQLiteRepository, u string) (*Bookmark, bool) {
+	return newCanonicalIndex(r).find(r, u)
+}
+
+// duplicateGroups groups the bookmarks by canonical URL, returning only the
+// groups with more than one bookmark, ordered by ID.
+func duplicateGroups(bs *Slice, canon func(string) string) [][]Bookmark {
+	groups := make(map[string][]Bookmark)
+	keys := make([]string, 0)
+	bs.ForEach(func(b Bookmark) {
+		k := canon(b.URL)
+		if _, ok := groups[k]; !ok {
+			keys = append(keys, k)
+		}
+		groups[k] = append(groups[k], b)
+	})
+
+	dups := make([][]Bookmark, 0)
+	for _, k := range keys {
+		g := groups[k]
+		if len(g) < 2 {
+			continue
+		}
+		sort.Slice(g, func(i, j int) bool { return g[i].ID < g[j].ID })
+		dups = append(dups, g)
+	}
+	sort.Slice(dups, func(i, j int) bool { return dups[i][0].ID < dups[j][0].ID })
+
+	return dups
+}
+
+// mergeBookmarks merges the group into its first bookmark, with the given
+// URL and the union of the tags.
+func mergeBookmarks(u string, g []Bookmark) Bookmark {
+	m := g[0]
+	m.URL = u
+	m.VisitCount = 0
+	tags := make([]string, 0)
+	for _, b := range g {
+		tags = append(tags, strings.Split(b.Tags, ",")...)
+		m.Title = firstNonEmpty(m.Title, b.Title)
+		m.Desc = firstNonEmpty(m.Desc, b.Desc)
+		m.Notes = joinNotes(m.Notes, b.Notes)
+		m.ArchiveURL = firstNonEmpty(m.ArchiveURL, b.ArchiveURL)
+		m.ArchiveHash = firstNonEmpty(m.ArchiveHash, b.ArchiveHash)
+		m.CanonicalURL = firstNonEmpty(m.CanonicalURL, b.CanonicalURL)
+		if m.Meta.Empty() {
+			m.Meta = b.Meta
+		}
+		if m.State == "" {
+			m.State, m.StateAt = b.State, b.StateAt
+		}
+		if b.CreatedAt != "" && b.CreatedAt < m.CreatedAt {
+			m.CreatedAt = b.CreatedAt
+		}
+		if b.LastVisit > m.LastVisit {
+			m.LastVisit = b.LastVisit
+		}
+		m.Favorite = m.Favorite || b.Favorite
+		m.VisitCount += b.VisitCount
+	}
+	m.Tags = bookmark.ParseTags(strings.Join(removeNoTag(tags), ","))
+
+	return m
+}
+
+// firstNonEmpty returns a if not empty, otherwise b.
+func firstNonEmpty(a, b string) string {
+	if a != "" {
+		return a
+	}
+
+	return b
+}
+
+// joinNotes appends the notes b to a, unless they are already included.
+func joinNotes(a, b string) string {
+	if b == "" || strings.Contains(a, b) {
+		return a
+	}
+	if a == "" {
+		return b
+	}
+
+	return a + "\n\n" + b
+}
+
+// Dedupe finds the bookmarks with the same canonical URL and merges each
+// group into its oldest bookmark, after confirmation.
+func Dedupe(t *terminal.Term, r *repo.SQLiteRepository, bs *Slice) error {
+	c := canonicalizer()
+	groups := duplicateGroups(bs, c.URL)
+	if len(groups) == 0 {
+		return ErrNoDuplicates
+	}
+
+	f := frame.New(frame.WithColorBorder(color.Gray))
+	f.Header(fmt.Sprintf("found %s groups of duplicates\n", color.BrightYellow(len(groups)).Bold())).Flush()
+	var merged int
+	for _, g := range groups {
+		m := mergeBookmarks(c.Clean(g[0].URL), g)
+		printDuplicates(g, &m)
+		if !config.App.Force {
+			opt, err := t.Choose(f.Clear().Question("merge?").String(), []string{"yes", "no", "quit"}, "y")
+			if err != nil {
+				return fmt.Errorf("%w", err)
+			}
+			switch strings.ToLower(opt) {
+			case "n", "no":
+				continue
+			case "q", "quit":
+				return finishDedupe(r, merged)
+			}
+		}
+		if err := mergeDuplicates(r, g, &m); err != nil {
+			return err
+		}
+		merged++
+	}
+
+	return finishDedupe(r, merged)
+}
+
+// mergeDuplicates removes the duplicates and updates the first bookmark of
+// the group with the merged one.
+func mergeDuplicates(r *repo.SQLiteRepository, g []Bookmark, m *Bookmark) error {
+	ctx := repo.WithSource(context.Background(), "dedupe")
+	rm := slice.New[Bookmark]()
+	for i := range g[1:] {
+		rm.Push(&g[i+1])
+	}
+	if err := r.DeleteMany(ctx, rm); err != nil {
+		return fmt.Errorf("deleting duplicates: %w", err)
+	}
+
+	return updateBookmark(r, m, &g[0])
+}
+
+// finishDedupe reorders the IDs after merging and prints a summary.
+func finishDedupe(r *repo.SQLiteRepository, merged int) error {
+	if merged == 0 {
+		return sys.ErrActionAborted
+	}
+	ctx := context.Background()
+	if err := r.ReorderIDs(ctx); err != nil {
+		return fmt.Errorf("reordering IDs: %w", err)
+	}
+	if err := r.Vacuum(); err != nil {
+		return fmt.Errorf("%w", err)
+	}
+	f := frame.New(frame.WithColorBorder(color.Gray))
+	success := color.BrightGreen("Successfully").Italic().String()
+	f.Success(fmt.Sprintf("%s merged %d groups of duplicates\n", success, merged)).Flush()
+
+	return nil
+}
+
+// printDuplicates prints a group of duplicates and the merged result.
+func printDuplicates(g []Bookmark, m *Bookmark) {
+	f := frame.New(frame.WithColorBorder(color.Gray)).Ln()
+	for _, b := range g {
+		bid := fmt.Sprintf(color.BrightGray("%-3d").String(), b.ID)
+		url := color.Gray(format.Shorten(b.URL, terminal.MinWidth)).Italic().String()
+		tags := color.Blue(format.TagsWithPound(b.Tags)).Italic().String()
+		f.Row(fmt.Sprintf(" %s %s %s", bid, url, tags)).Ln()
+	}
+	url := color.BrightMagenta(format.Shorten(m.URL, terminal.MinWidth)).String()
+	tags := color.BrightBlue(format.TagsWithPound(m.Tags)).Italic().String()
+	f.Footer(fmt.Sprintf("%s %s %s\n", color.BrightGreen("→").Bold(), url, tags)).Flush()
+}
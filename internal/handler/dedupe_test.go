@@ -0,0 +1,87 @@
+package handler
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/haaag/gm/internal/bookmark"
+	"github.com/haaag/gm/internal/bookmark/canonical"
+	"github.com/haaag/gm/internal/slice"
+)
+
+func TestDuplicateGroups(t *testing.T) {
+	t.Parallel()
+	bs := slice.New[Bookmark]()
+	for _, b := range []Bookmark{
+		{ID: 3, URL: "https://example.com/a"},
+		{ID: 1, URL: "https://Example.com/a?utm_source=x#top"},
+		{ID: 2, URL: "https://example.com/b"},
+		{ID: 4, URL: "https://example.com/b/"},
+		{ID: 5, URL: "https://example.com/c"},
+	} {
+		bs.Push(&b)
+	}
+
+	groups := duplicateGroups(bs, canonical.New().URL)
+	assert.Len(t, groups, 2)
+	assert.Equal(t, []int{1, 3}, []int{groups[0][0].ID, groups[0][1].ID})
+	assert.Equal(t, []int{2, 4}, []int{groups[1][0].ID, groups[1][1].ID})
+}
+
+func TestMergeBookmarks(t *testing.T) {
+	t.Parallel()
+	g := []Bookmark{
+		{
+			ID: 1, URL: "https://Example.com/a?utm_source=x", Tags: "go,", Title: "",
+			CreatedAt: "2024-02-01T00:00:00Z", LastVisit: "2024-02-01T00:00:00Z", VisitCount: 2,
+		},
+		{
+			ID: 3, URL: "https://example.com/a", Tags: "notag,", Title: "Title", Desc: "Desc",
+			CreatedAt: "2024-01-01T00:00:00Z", LastVisit: "2024-03-01T00:00:00Z", VisitCount: 1,
+			Favorite: true,
+		},
+		{ID: 5, URL: "https://example.com/a#x", Tags: "cli,go,", Title: "Other"},
+	}
+	m := mergeBookmarks("https://example.com/a", g)
+	assert.Equal(t, 1, m.ID)
+	assert.Equal(t, "https://example.com/a", m.URL)
+	assert.Equal(t, "cli,go,", m.Tags)
+	assert.Equal(t, "Title", m.Title)
+	assert.Equal(t, "Desc", m.Desc)
+	assert.Equal(t, "2024-01-01T00:00:00Z", m.CreatedAt)
+	assert.Equal(t, "2024-03-01T00:00:00Z", m.LastVisit)
+	assert.Equal(t, 3, m.VisitCount)
+	assert.True(t, m.Favorite)
+}
+
+func TestCleanDuplicates(t *testing.T) {
+	t.Parallel()
+	r := testRepo(t)
+	b := bookmark.New()
+	b.URL = "https://example.com/w?edit"
+	b.Tags = "wiki,"
+	assert.NoError(t, r.InsertOne(context.Background(), b))
+
+	bs := slice.New[Bookmark]()
+	for _, u := range []string{
+		"https://Example.com/w?edit#top",
+		"https://gitlab.com/api/v4/projects/group%2Fproj?utm_source=x",
+		"https://gitlab.com/api/v4/projects/group%2Fproj/",
+		"https://example.com/q?a=1;b=2",
+	} {
+		bs.Push(&Bookmark{URL: u, Tags: "new,"})
+	}
+	assert.NoError(t, cleanDuplicates(r, bs))
+	urls := make([]string, 0, bs.Len())
+	bs.ForEach(func(b Bookmark) { urls = append(urls, b.URL) })
+	assert.Equal(t, []string{
+		"https://gitlab.com/api/v4/projects/group%2Fproj",
+		"https://example.com/q?a=1;b=2",
+	}, urls)
+
+	got, ok := Duplicate(r, "https://EXAMPLE.com:443/w?edit")
+	assert.True(t, ok)
+	assert.Equal(t, b.URL, got.URL)
+}
@@ -208,15 +208,22 @@ func MenuForRecords[T comparable](cmd *cobra.Command) *menu.Menu[T] {
 		menu.WithUseDefaults(),
 		menu.WithSettings(config.Fzf.Settings),
 		menu.WithMultiSelection(),
-		menu.WithPreview(config.App.Cmd + " --name " + config.App.DBName + " records {1}"),
+	}
+	mo = append(mo, childOpts(
+		menu.WithPreview(
+			config.App.Cmd+" --name "+config.App.DBName+" records {1}"+previewSnippet(cmd.Flags().Args()),
+		),
 		menu.WithKeybinds(
 			config.FzfKeybindEdit(),
 			config.FzfKeybindOpen(),
 			config.FzfKeybindQR(),
 			config.FzfKeybindOpenQR(),
 			config.FzfKeybindYank(),
+			config.FzfKeybindSnooze(),
+			config.FzfKeybindAddTag(),
+			config.FzfKeybindRemoveTag(),
 		),
-	}
+	)...)
 	multi, err := cmd.Flags().GetBool("multiline")
 	if err != nil {
 		slog.Debug("getting 'Multiline' flag", "error", err.Error())
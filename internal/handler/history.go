@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/haaag/gm/internal/format"
+	"github.com/haaag/gm/internal/format/color"
+	"github.com/haaag/gm/internal/format/frame"
+	"github.com/haaag/gm/internal/repo"
+	"github.com/haaag/gm/internal/sys/terminal"
+)
+
+// ErrNoHistory is returned when a bookmark has no revisions.
+var ErrNoHistory = errors.New("no history found")
+
+// PrintHistory prints the revisions of the bookmarks, oldest first, with the
+// old and new value of each changed field.
+func PrintHistory(r *repo.SQLiteRepository, bs *Slice, j bool) error {
+	var all []repo.Revision
+	if err := bs.ForEachErr(func(b Bookmark) error {
+		rs, err := r.History(b.URL)
+		if err != nil {
+			return fmt.Errorf("%w", err)
+		}
+		all = append(all, rs...)
+
+		return nil
+	}); err != nil {
+		return err
+	}
+	if j {
+		if len(all) == 0 {
+			fmt.Println("[]")
+			return nil
+		}
+		fmt.Println(string(format.ToJSON(all)))
+
+		return nil
+	}
+	if len(all) == 0 {
+		return ErrNoHistory
+	}
+	f := frame.New(frame.WithColorBorder(color.Gray))
+	url := ""
+	for _, rev := range all {
+		if rev.URL != url {
+			url = rev.URL
+			u := color.BrightMagenta(format.Shorten(url, terminal.MinWidth)).Bold().String()
+			f.Header(u).Ln()
+		}
+		when := color.Gray(oplogTime(rev.CreatedAt)).Italic().String()
+		op := color.BrightCyan(rev.Op).Bold().String()
+		if rev.Source != "" && rev.Source != rev.Op {
+			op += color.Gray(" (" + rev.Source + ")").Italic().String()
+		}
+		f.Mid(when + " " + op).Ln()
+		for _, l := range strings.Split(format.ColorDiff(revisionDiff(rev)), "\n") {
+			f.Row(l).Ln()
+		}
+	}
+	f.Flush()
+
+	return nil
+}
+
+// revisionDiff returns the changes of the revision in diff format, each
+// line of a value prefixed with its field.
+func revisionDiff(rev repo.Revision) string {
+	var lines []string
+	add := func(sign, field, v string) {
+		if v == "" {
+			return
+		}
+		for _, l := range strings.Split(v, "\n") {
+			lines = append(lines, fmt.Sprintf("%s%s: %s", sign, field, l))
+		}
+	}
+	for _, c := range rev.Changes {
+		add("-", c.Field, c.Old)
+		add("+", c.Field, c.New)
+	}
+
+	return strings.Join(lines, "\n")
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"path/filepath"
+	"strings"
 
 	"github.com/spf13/cobra"
 
@@ -56,7 +57,8 @@ func ImportFromDB(
 
 		return err
 	}
-	if err := insertRecordsToRepo(t, destDB, records); err != nil {
+	applyRulesToImported(records)
+	if err := insertRecordsToRepo(t, destDB, records, srcDB.Name()); err != nil {
 		return err
 	}
 	// remove prompt
@@ -98,7 +100,7 @@ func ImportFromBrowser(cmd *cobra.Command, args []string) error {
 		return nil
 	}
 
-	return insertRecordsToRepo(t, r, bs)
+	return insertRecordsToRepo(t, r, bs, strings.ToLower(br.Name()))
 }
 
 // ImportFromBackup imports bookmarks from a backup.
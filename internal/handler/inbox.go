@@ -0,0 +1,120 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/haaag/gm/internal/bookmark"
+	"github.com/haaag/gm/internal/config"
+	"github.com/haaag/gm/internal/format/color"
+	"github.com/haaag/gm/internal/format/frame"
+	"github.com/haaag/gm/internal/menu"
+	"github.com/haaag/gm/internal/repo"
+	"github.com/haaag/gm/internal/sys/terminal"
+)
+
+// ErrInboxEmpty is returned when there are no unread bookmarks.
+var ErrInboxEmpty = errors.New("inbox is empty")
+
+// Inbox gets the unread bookmarks that are not snoozed, oldest first.
+func Inbox(r *repo.SQLiteRepository, bs *Slice) error {
+	if err := r.ByState(bookmark.StateUnread, bs); err != nil {
+		return fmt.Errorf("%w", err)
+	}
+	now := time.Now()
+	bs.FilterInPlace(func(b *Bookmark) bool {
+		return !b.IsSnoozed(now)
+	})
+	if bs.Empty() {
+		return ErrInboxEmpty
+	}
+
+	return nil
+}
+
+// ByState filters the bookmarks by read-later state.
+func ByState(bs *Slice, state string) error {
+	if err := bookmark.ValidateState(state); err != nil {
+		return fmt.Errorf("%w", err)
+	}
+	bs.FilterInPlace(func(b *Bookmark) bool {
+		return b.State == state
+	})
+
+	return nil
+}
+
+// MarkState sets the read-later state of the bookmarks, `none` removes them
+// from the read-later queue.
+func MarkState(r *repo.SQLiteRepository, bs *Slice, state string) error {
+	if state == "none" {
+		state = bookmark.StateNone
+	}
+	if err := r.SetState(context.Background(), bs, state); err != nil {
+		return fmt.Errorf("%w", err)
+	}
+	f := frame.New(frame.WithColorBorder(color.Gray))
+	s := color.BrightGreen(state).Italic().String()
+	f.Success(fmt.Sprintf("marked %d bookmarks as %s\n", bs.Len(), s)).Flush()
+
+	return nil
+}
+
+// OpenAndMarkRead opens the bookmarks in the default browser and marks them
+// as read.
+func OpenAndMarkRead(r *repo.SQLiteRepository, bs *Slice) error {
+	if err := Open(bs); err != nil {
+		return err
+	}
+
+	return MarkState(r, bs, bookmark.StateRead)
+}
+
+// SelectFromInbox lets the user select the bookmarks from the inbox menu.
+func SelectFromInbox(bs *Slice) error {
+	items, err := SelectionWithMenu(menuForInbox(), *bs.Items(), fzfFormatter(false))
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+	bs.Set(&items)
+
+	return nil
+}
+
+// menuForInbox returns a FZF menu for the read-later inbox.
+//
+// The items are reloaded after marking or snoozing them.
+func menuForInbox() *menu.Menu[Bookmark] {
+	reload := fmt.Sprintf("%s --name=%s inbox --oneline --width=%d",
+		config.App.Cmd, config.App.DBName, terminal.MaxWidth)
+
+	mo := []menu.OptFn{
+		menu.WithUseDefaults(),
+		menu.WithSettings(config.Fzf.Settings),
+		menu.WithMultiSelection(),
+		menu.WithHeader("enter: open and mark as read", false),
+	}
+	mo = append(mo, childOpts(
+		menu.WithPreview(config.App.Cmd+" --name "+config.App.DBName+" records {1}"),
+		menu.WithKeybinds(
+			withReload(config.FzfKeybindOpenAndRead(), reload),
+			withReload(config.FzfKeybindRead(), reload),
+			withReload(config.FzfKeybindArchive(), reload),
+			withReload(config.FzfKeybindSnooze(), reload),
+			config.FzfKeybindEdit(),
+			config.FzfKeybindYank(),
+		),
+	)...)
+
+	return menu.New[Bookmark](mo...)
+}
+
+// withReload reloads the menu items with the output of the command after
+// the keybind action.
+func withReload(k menu.Keymap, cmd string) menu.Keymap {
+	k.Action += "+reload(" + cmd + ")"
+
+	return k
+}
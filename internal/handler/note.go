@@ -0,0 +1,85 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/haaag/gm/internal/config"
+	"github.com/haaag/gm/internal/format/color"
+	"github.com/haaag/gm/internal/repo"
+	"github.com/haaag/gm/internal/sys/files"
+)
+
+// ErrNoNotes is returned when the bookmarks have no notes.
+var ErrNoNotes = errors.New("no notes found")
+
+// notesExt is the extension of the notes buffer, used by editors to pick
+// the Markdown syntax.
+const notesExt = "md"
+
+// EditNotes edits the Markdown notes of the bookmarks with the text editor.
+func EditNotes(r *repo.SQLiteRepository, bs *Slice) error {
+	n := bs.Len()
+	if n == 0 {
+		return repo.ErrRecordQueryNotProvided
+	}
+	prompt := fmt.Sprintf("%s notes of %d bookmarks, continue?", color.BrightOrange("editing").Bold(), n)
+	if err := confirmUserLimit(n, maxItemsToEdit, prompt); err != nil {
+		return err
+	}
+	te, err := files.NewEditor(config.App.Env.Editor)
+	if err != nil {
+		return fmt.Errorf("getting editor: %w", err)
+	}
+
+	return bs.ForEachMutErr(func(b *Bookmark) error {
+		return editNotes(r, te, b)
+	})
+}
+
+// editNotes edits the notes of a single bookmark, saving them only if they
+// changed.
+func editNotes(r *repo.SQLiteRepository, te *files.TextEditor, b *Bookmark) error {
+	original := []byte(b.Notes)
+	data, err := te.EditBytes(bytes.Clone(original), notesExt)
+	if err != nil {
+		return fmt.Errorf("failed to edit notes: %w", err)
+	}
+	notes := cleanNotes(string(data))
+	if notes == b.Notes {
+		return nil
+	}
+	if err := r.SetNotes(context.Background(), b, notes); err != nil {
+		return fmt.Errorf("%w", err)
+	}
+	fmt.Printf("%s: [%d] %s\n", config.App.Name, b.ID, color.Blue("notes updated").Bold())
+
+	return nil
+}
+
+// PrintNotes prints the raw Markdown notes of the bookmarks.
+func PrintNotes(bs *Slice) error {
+	bs.FilterInPlace(func(b *Bookmark) bool {
+		return b.Notes != ""
+	})
+	if bs.Empty() {
+		return ErrNoNotes
+	}
+	bs.ForEach(func(b Bookmark) {
+		if bs.Len() > 1 {
+			fmt.Printf("<!-- %d %s -->\n", b.ID, b.URL)
+		}
+		fmt.Println(b.Notes)
+	})
+
+	return nil
+}
+
+// cleanNotes removes the leading empty lines and the trailing whitespace
+// added by the editor.
+func cleanNotes(s string) string {
+	return strings.TrimRight(strings.TrimLeft(s, "\r\n"), " \t\r\n")
+}
@@ -0,0 +1,114 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/haaag/gm/internal/config"
+	"github.com/haaag/gm/internal/format"
+	"github.com/haaag/gm/internal/format/color"
+	"github.com/haaag/gm/internal/format/frame"
+	"github.com/haaag/gm/internal/repo"
+	"github.com/haaag/gm/internal/sys/terminal"
+)
+
+// ErrOplogEmpty is returned when the journal has no operations.
+var ErrOplogEmpty = errors.New("journal is empty")
+
+// Undo reverts the last n operations, newest first.
+func Undo(r *repo.SQLiteRepository, n int) error {
+	return replayOplog(n, "undone", func(ctx context.Context) (*repo.Entry, error) {
+		return r.Undo(ctx)
+	})
+}
+
+// Redo applies again the last n operations undone, oldest first.
+func Redo(r *repo.SQLiteRepository, n int) error {
+	return replayOplog(n, "redone", func(ctx context.Context) (*repo.Entry, error) {
+		return r.Redo(ctx)
+	})
+}
+
+// replayOplog runs the undo or redo function up to n times, printing each
+// operation. Stops without error once there is nothing left, if at least
+// one operation was replayed.
+func replayOplog(n int, verb string, fn func(ctx context.Context) (*repo.Entry, error)) error {
+	f := frame.New(frame.WithColorBorder(color.Gray))
+	ctx := context.Background()
+	for i := range max(n, 1) {
+		e, err := fn(ctx)
+		if err != nil {
+			if i > 0 && (errors.Is(err, repo.ErrOplogNothingToUndo) || errors.Is(err, repo.ErrOplogNothingToRedo)) {
+				break
+			}
+			f.Flush()
+
+			return fmt.Errorf("%w", err)
+		}
+		v := color.BrightGreen(verb).Italic().String()
+		f.Success(fmt.Sprintf("%s %s %s\n", v, color.BrightCyan(e.Op).Bold(), e.Desc))
+	}
+	f.Flush()
+
+	return nil
+}
+
+// PrintOplog prints the last n operations of the journal, newest first.
+func PrintOplog(r *repo.SQLiteRepository, n int, j bool) error {
+	es, err := r.Oplog(n)
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+	if j {
+		if len(es) == 0 {
+			fmt.Println("[]")
+			return nil
+		}
+		fmt.Println(string(format.ToJSON(es)))
+
+		return nil
+	}
+	if len(es) == 0 {
+		return ErrOplogEmpty
+	}
+	f := frame.New(frame.WithColorBorder(color.Gray))
+	pad := len(strconv.Itoa(es[0].ID))
+	for _, e := range es {
+		id := color.BrightWhite(fmt.Sprintf("%*d", pad, e.ID)).Bold().String()
+		op := color.BrightCyan(fmt.Sprintf("%-6s", e.Op)).Bold().String()
+		when := color.Gray(oplogTime(e.CreatedAt)).Italic().String()
+		desc := format.Shorten(e.Desc, terminal.MinWidth)
+		if e.Undone {
+			op = color.Gray(fmt.Sprintf("%-6s", e.Op)).Italic().String()
+			desc = color.Gray(desc + " (undone)").Italic().String()
+		}
+		f.Mid(fmt.Sprintf("%s %s %s %s", id, when, op, desc)).Ln()
+	}
+	f.Flush()
+
+	return nil
+}
+
+// oplogTime formats the time of the operation in local time.
+func oplogTime(s string) string {
+	for _, layout := range []string{time.RFC3339, time.DateTime} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t.Local().Format("2006-01-02 15:04")
+		}
+	}
+
+	return s
+}
+
+// oplogHint returns the hint printed after an operation that can be
+// undone, with a leading space.
+func oplogHint() string {
+	if config.Journal.Depth == 0 {
+		return ""
+	}
+
+	return " " + color.Gray(fmt.Sprintf("(undo with '%s undo')", config.App.Cmd)).Italic().String()
+}
@@ -11,6 +11,7 @@ import (
 
 	"github.com/haaag/rotato"
 
+	"github.com/haaag/gm/internal/bookmark"
 	"github.com/haaag/gm/internal/bookmark/scraper"
 	"github.com/haaag/gm/internal/config"
 	"github.com/haaag/gm/internal/format/color"
@@ -30,6 +31,7 @@ func parseFoundFromBrowser(t *terminal.Term, r *repo.SQLiteRepository, bs *Slice
 			return nil
 		}
 	}
+	applyRulesToImported(bs)
 
 	msg := fmt.Sprintf("scrape missing data from %d bookmarks found?", bs.Len())
 	f.Row().Ln().Flush().Clear()
@@ -46,11 +48,22 @@ func parseFoundFromBrowser(t *terminal.Term, r *repo.SQLiteRepository, bs *Slice
 	return scrapeMissingDescription(bs)
 }
 
-// cleanDuplicates removes duplicate bookmarks from the import process.
+// cleanDuplicates cleans the URLs and removes duplicate bookmarks from the
+// import process, both the ones already in the repository and the repeated
+// ones, comparing their canonical form.
 func cleanDuplicates(r *repo.SQLiteRepository, bs *Slice) error {
 	originalLen := bs.Len()
+	cleanURLs(bs)
+	idx := newCanonicalIndex(r)
+	seen := make(map[string]bool, originalLen)
 	bs.FilterInPlace(func(b *Bookmark) bool {
-		_, exists := r.Has(b.URL)
+		k := CanonicalURL(b.URL)
+		if seen[k] {
+			return false
+		}
+		seen[k] = true
+		_, exists := idx.find(r, b.URL)
+
 		return !exists
 	})
 	if originalLen != bs.Len() {
@@ -92,6 +105,8 @@ func scrapeMissingDescription(bs *Slice) error {
 			if err := sc.Scrape(); err != nil {
 				errs = append(errs, fmt.Sprintf("url %s: %s", b.URL, err.Error()))
 				slog.Warn("scraping error", "url", b.URL, "err", err)
+			} else {
+				bookmark.ScrapeMetadata(b, sc)
 			}
 			b.Desc = sc.Desc()
 		}(b)
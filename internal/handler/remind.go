@@ -0,0 +1,102 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/haaag/gm/internal/bookmark"
+	"github.com/haaag/gm/internal/config"
+	"github.com/haaag/gm/internal/format/color"
+	"github.com/haaag/gm/internal/format/frame"
+	"github.com/haaag/gm/internal/repo"
+	"github.com/haaag/gm/internal/sys"
+)
+
+// ErrNoDue is returned when no reminder has passed.
+var ErrNoDue = errors.New("no reminders due")
+
+// Remind sets the reminder of the bookmarks, in a duration like `3d` or at a
+// date like `2026-11-01`.
+func Remind(r *repo.SQLiteRepository, bs *Slice, when string) error {
+	at, err := bookmark.ParseRemindAt(when, time.Now())
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+	if err := r.SetRemind(context.Background(), bs, at); err != nil {
+		return fmt.Errorf("%w", err)
+	}
+	f := frame.New(frame.WithColorBorder(color.Gray))
+	t := color.BrightGreen(at.Format("2006-01-02 15:04")).Italic().String()
+	f.Success(fmt.Sprintf("reminder for %d bookmarks set to %s\n", bs.Len(), t)).Flush()
+
+	return nil
+}
+
+// ClearRemind removes the reminder of the bookmarks.
+func ClearRemind(r *repo.SQLiteRepository, bs *Slice) error {
+	if err := r.SetRemind(context.Background(), bs, time.Time{}); err != nil {
+		return fmt.Errorf("%w", err)
+	}
+	f := frame.New(frame.WithColorBorder(color.Gray))
+	f.Success(fmt.Sprintf("reminder for %d bookmarks cleared\n", bs.Len())).Flush()
+
+	return nil
+}
+
+// Due gets the bookmarks whose reminder has passed.
+func Due(r *repo.SQLiteRepository, bs *Slice) error {
+	if err := r.Due(time.Now(), bs); err != nil {
+		return fmt.Errorf("%w", err)
+	}
+	if bs.Empty() {
+		return ErrNoDue
+	}
+
+	return nil
+}
+
+// NotifyDue sends a desktop notification for each due bookmark, with the
+// configured command or `notify-send`.
+func NotifyDue(bs *Slice) error {
+	return bs.ForEachErr(func(b Bookmark) error {
+		if len(config.Remind.NotifyCmd) == 0 {
+			if _, err := sys.Notify(config.App.Name+": reminder", b.Title+"\n"+b.URL); err != nil {
+				return fmt.Errorf("%w", err)
+			}
+
+			return nil
+		}
+
+		return runNotifyCmd(config.Remind.NotifyCmd, &b)
+	})
+}
+
+// runNotifyCmd runs the notification command replacing the placeholders
+// with the bookmark fields.
+func runNotifyCmd(args []string, b *Bookmark) error {
+	cmd := notifyCmdArgs(args, b)
+	slog.Debug("running notify command", "args", cmd)
+	if err := exec.CommandContext(context.Background(), cmd[0], cmd[1:]...).Run(); err != nil {
+		return fmt.Errorf("running notify command: %w", err)
+	}
+
+	return nil
+}
+
+// notifyCmdArgs replaces the placeholders {id}, {url} and {title} in the
+// command arguments.
+func notifyCmdArgs(args []string, b *Bookmark) []string {
+	rp := strings.NewReplacer("{id}", strconv.Itoa(b.ID), "{url}", b.URL, "{title}", b.Title)
+	out := make([]string, 0, len(args))
+	for _, a := range args {
+		out = append(out, rp.Replace(a))
+	}
+
+	return out
+}
@@ -0,0 +1,14 @@
+package handler
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNotifyCmdArgs(t *testing.T) {
+	t.Parallel()
+	b := &Bookmark{ID: 7, URL: "https://example.com", Title: "Example"}
+	got := notifyCmdArgs([]string{"notify-send", "gm: {title}", "{url} ({id})"}, b)
+	assert.Equal(t, []string{"notify-send", "gm: Example", "https://example.com (7)"}, got)
+}
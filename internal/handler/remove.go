@@ -200,3 +200,43 @@ func DroppingDB(r *repo.SQLiteRepository, t *terminal.Term) error {
 
 	return nil
 }
+
+// PruneBackups removes the backups of the database the retention policy
+// does not keep. With dryRun, only lists them.
+func PruneBackups(t *terminal.Term, r *repo.SQLiteRepository, dryRun bool) error {
+	p := repo.RetentionFromConfig()
+	if !p.Enabled() {
+		return fmt.Errorf("%w: set keep_* in the backup config", repo.ErrBackupNoPolicy)
+	}
+	expired, err := r.Prune(p, true)
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+	if len(expired) == 0 {
+		return fmt.Errorf("%w: %q", repo.ErrBackupNoPurge, r.Name())
+	}
+	f := frame.New(frame.WithColorBorder(color.BrightGray))
+	policy := fmt.Sprintf("last: %d, daily: %d, weekly: %d, monthly: %d", p.Last, p.Daily, p.Weekly, p.Monthly)
+	f.Header(fmt.Sprintf("%d backups expired ", len(expired)))
+	f.Text(color.Gray("(" + policy + ")").Italic().String()).Ln().Row("\n")
+	for _, s := range expired {
+		f.Mid(repo.BackupSummaryWithFmtDateFromPath(s)).Ln()
+	}
+	f.Flush()
+	if dryRun {
+		return nil
+	}
+	if !config.App.Force {
+		rm := color.BrightRed("remove").Bold().String()
+		if err := t.ConfirmErr(f.Clear().Row("\n").Question(rm+" expired backups?").String(), "n"); err != nil {
+			return fmt.Errorf("%w", err)
+		}
+	}
+	if _, err := r.Prune(p, false); err != nil {
+		return fmt.Errorf("%w", err)
+	}
+	success := color.BrightGreen("Successfully").Italic().String()
+	f.Clear().Success(success + " " + strconv.Itoa(len(expired)) + " backup/s pruned\n").Flush()
+
+	return nil
+}
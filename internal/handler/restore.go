@@ -0,0 +1,196 @@
+package handler
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/haaag/gm/internal/config"
+	"github.com/haaag/gm/internal/format"
+	"github.com/haaag/gm/internal/format/color"
+	"github.com/haaag/gm/internal/format/frame"
+	"github.com/haaag/gm/internal/menu"
+	"github.com/haaag/gm/internal/repo"
+	"github.com/haaag/gm/internal/sys/terminal"
+)
+
+// restoreSamples is the number of records shown of each kind of change.
+const restoreSamples = 5
+
+// BackupToRestore returns the backup of the database with the given name,
+// the one selected in the menu or the newest one.
+func BackupToRestore(r *repo.SQLiteRepository, name string, useMenu bool) (string, error) {
+	fs, err := r.BackupsList()
+	if err != nil {
+		return "", fmt.Errorf("%w", err)
+	}
+	if len(fs) == 0 {
+		return "", fmt.Errorf("%w: %q", repo.ErrBackupNotFound, r.Name())
+	}
+	switch {
+	case name != "":
+		for _, p := range fs {
+			if p == name || filepath.Base(p) == filepath.Base(name) {
+				return p, nil
+			}
+		}
+
+		return "", fmt.Errorf("%w: %q", repo.ErrBackupNotFound, name)
+	case useMenu:
+		selected, err := Select(fs,
+			func(p *string) string { return repo.BackupSummaryWithFmtDateFromPath(*p) },
+			menu.WithUseDefaults(),
+			menu.WithSettings(config.Fzf.Settings),
+			menu.WithHeader(fmt.Sprintf("select backup to restore %q", r.Name()), false),
+		)
+		if err != nil {
+			return "", fmt.Errorf("%w", err)
+		}
+
+		return selected[0], nil
+	default:
+		p, err := r.LastBackup()
+		if err != nil {
+			return "", fmt.Errorf("%w", err)
+		}
+
+		return p, nil
+	}
+}
+
+// RestoreBackup shows the records added, removed and changed by restoring
+// the backup and, once confirmed, replaces the database with it. The current
+// state is backed up first.
+func RestoreBackup(t *terminal.Term, r *repo.SQLiteRepository, bkPath string) error {
+	image, err := repo.ReadBackup(bkPath)
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+	bk, err := repo.OpenBackup(bkPath, image)
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+	d, err := repo.DiffDB(r, bk)
+	bk.Close()
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+
+	f := frame.New(frame.WithColorBorder(color.BrightGray))
+	name := color.BrightMagenta(r.Name()).Bold().String()
+	f.Header("restore " + name + " from " + color.Text(filepath.Base(bkPath)).Italic().String()).Ln()
+	f.Row().Ln()
+	printRestoreDiff(f, d)
+	f.Flush()
+	if !config.App.Force {
+		q := color.BrightYellow("restore").Bold().String() + " backup?"
+		if err := t.ConfirmErr(f.Clear().Row("\n").Question(q).String(), "n"); err != nil {
+			return fmt.Errorf("%w", err)
+		}
+	}
+	safety, err := r.Restore(image)
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+	success := color.BrightGreen("Successfully").Italic().String()
+	s := color.Text(safety).Italic().String()
+	f.Clear().Success(success + " database restored, previous state: " + s + "\n").Flush()
+
+	return nil
+}
+
+// printRestoreDiff adds the counts of the changes to the frame, with a few
+// records of each.
+func printRestoreDiff(f *frame.Frame, d *repo.DBDiff) {
+	if d.Empty() {
+		f.Mid(color.Gray("no record changes").Italic().String()).Ln()
+		return
+	}
+	counts := fmt.Sprintf("%s added, %s removed, %s changed",
+		color.BrightGreen(fmt.Sprintf("+%d", len(d.Added))).Bold(),
+		color.BrightRed(fmt.Sprintf("-%d", len(d.Removed))).Bold(),
+		color.BrightYellow(fmt.Sprintf("~%d", len(d.Changed))).Bold(),
+	)
+	f.Mid(counts).Ln()
+	url := func(s string) string { return format.Shorten(s, terminal.MinWidth) }
+	more := func(n int) {
+		if n > restoreSamples {
+			f.Row(color.Gray(fmt.Sprintf("  ...and %d more", n-restoreSamples)).Italic().String()).Ln()
+		}
+	}
+	for _, b := range d.Added[:min(len(d.Added), restoreSamples)] {
+		f.Row(color.BrightGreen("+ ").String() + url(b.URL)).Ln()
+	}
+	more(len(d.Added))
+	for _, b := range d.Removed[:min(len(d.Removed), restoreSamples)] {
+		f.Row(color.BrightRed("- ").String() + url(b.URL)).Ln()
+	}
+	more(len(d.Removed))
+	for _, c := range d.Changed[:min(len(d.Changed), restoreSamples)] {
+		fields := make([]string, 0, len(c.Changes))
+		for _, fc := range c.Changes {
+			fields = append(fields, fc.Field)
+		}
+		changed := color.Gray(" (" + strings.Join(fields, ", ") + ")").Italic().String()
+		f.Row(color.BrightYellow("~ ").String() + url(c.URL) + changed).Ln()
+	}
+	more(len(d.Changed))
+}
+
+// VerifyBackups checks the backups of the database with the manifest and
+// prints the result of each, in JSON if j is set.
+func VerifyBackups(r *repo.SQLiteRepository, j bool) error {
+	checks, err := r.VerifyBackups()
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+	if len(checks) == 0 {
+		return fmt.Errorf("%w: %q", repo.ErrBackupNotFound, r.Name())
+	}
+	var failed int
+	for i := range checks {
+		if !checks[i].OK() {
+			failed++
+		}
+	}
+	if j {
+		fmt.Println(string(format.ToJSON(checks)))
+	} else {
+		printBackupChecks(r, checks)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%w: %d backups", repo.ErrBackupVerify, failed)
+	}
+
+	return nil
+}
+
+// printBackupChecks prints the result of the verification of each backup.
+func printBackupChecks(r *repo.SQLiteRepository, checks []repo.BackupCheck) {
+	f := frame.New(frame.WithColorBorder(color.BrightGray))
+	name := color.BrightMagenta(r.Name()).Bold().String()
+	f.Header(fmt.Sprintf("verify %d backups of %s", len(checks), name)).Ln().Row().Ln()
+	var partial int
+	for i := range checks {
+		c := &checks[i]
+		mark := color.BrightGreen("✓ ").String()
+		switch {
+		case !c.OK():
+			mark = color.BrightRed("✗ ").String()
+		case c.Partial():
+			mark = color.BrightYellow("! ").String()
+			partial++
+		}
+		detail := fmt.Sprintf("(checksum: %s, integrity: %s)", c.Checksum, c.Integrity)
+		f.Row(mark + c.File + " " + color.Gray(detail).Italic().String()).Ln()
+		if c.Err != "" {
+			f.Row(color.BrightRed("  " + c.Err).Italic().String()).Ln()
+		}
+	}
+	if partial > 0 {
+		note := fmt.Sprintf("%d backups partially verified: locked backups are checked by checksum only, "+
+			"backups missing from the manifest by integrity only", partial)
+		f.Row().Ln().Warning(note + "\n")
+	}
+	f.Flush()
+}
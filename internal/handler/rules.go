@@ -0,0 +1,179 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/haaag/gm/internal/bookmark"
+	"github.com/haaag/gm/internal/bookmark/rules"
+	"github.com/haaag/gm/internal/config"
+	"github.com/haaag/gm/internal/format"
+	"github.com/haaag/gm/internal/format/color"
+	"github.com/haaag/gm/internal/format/frame"
+	"github.com/haaag/gm/internal/repo"
+	"github.com/haaag/gm/internal/sys/terminal"
+)
+
+// ErrNoRules is returned when no auto-tagging rules are configured.
+var ErrNoRules = errors.New("no auto-tagging rules found in config file")
+
+// RuleResult holds the rules that fired for a bookmark and its new tags.
+type RuleResult struct {
+	Bookmark *Bookmark
+	Matches  []rules.Match
+	Tags     string
+}
+
+// Changed returns true if the rules added tags to the bookmark.
+func (rr *RuleResult) Changed() bool {
+	return rr.Tags != rr.Bookmark.Tags
+}
+
+// tagRules returns the auto-tagging rules engine from the app configuration.
+func tagRules() (*rules.Engine, error) {
+	e, err := rules.New(config.Rules)
+	if err != nil {
+		return nil, fmt.Errorf("%w", err)
+	}
+
+	return e, nil
+}
+
+// matchRules returns the rules that fired for the bookmark and the
+// resulting tags.
+func matchRules(e *rules.Engine, b *Bookmark) RuleResult {
+	ms := e.Match(b.URL, b.Title)
+	rr := RuleResult{Bookmark: b, Matches: ms, Tags: b.Tags}
+	if len(ms) == 0 {
+		return rr
+	}
+	tags := strings.Split(b.Tags, ",")
+	tags = append(tags, rules.Tags(ms)...)
+	tags = removeNoTag(tags)
+	rr.Tags = bookmark.ParseTags(strings.Join(tags, ","))
+
+	return rr
+}
+
+// removeNoTag removes the `notag` placeholder from the tags.
+func removeNoTag(tags []string) []string {
+	out := make([]string, 0, len(tags))
+	for _, t := range tags {
+		if t != "notag" && strings.TrimSpace(t) != "" {
+			out = append(out, t)
+		}
+	}
+
+	return out
+}
+
+// ApplyRules adds the tags of the matching auto-tagging rules to the
+// bookmark, and returns the rules that fired.
+func ApplyRules(b *Bookmark) []rules.Match {
+	e, err := tagRules()
+	if err != nil {
+		slog.Warn("loading auto-tagging rules", "error", err)
+		return nil
+	}
+	rr := matchRules(e, b)
+	b.Tags = rr.Tags
+
+	return rr.Matches
+}
+
+// applyRulesToImported adds the tags of the matching auto-tagging rules to
+// the imported bookmarks.
+func applyRulesToImported(bs *Slice) {
+	e, err := tagRules()
+	if err != nil {
+		slog.Warn("loading auto-tagging rules", "error", err)
+		return
+	}
+	if e.Len() == 0 {
+		return
+	}
+	var n int
+	bs.ForEachMut(func(b *Bookmark) {
+		rr := matchRules(e, b)
+		if rr.Changed() {
+			n++
+		}
+		b.Tags = rr.Tags
+	})
+	if n == 0 {
+		return
+	}
+	f := frame.New(frame.WithColorBorder(color.BrightGray))
+	s := fmt.Sprintf("auto-tagged %d bookmarks", n)
+	f.Row().Ln().Info(color.BrightGreen(s).Italic().String()).Ln().Flush()
+}
+
+// ApplyRulesToRecords runs the auto-tagging rules over the bookmarks and
+// updates the ones that get new tags.
+//
+// If dryRun is true, only reports the changes.
+func ApplyRulesToRecords(t *terminal.Term, r *repo.SQLiteRepository, bs *Slice, dryRun bool) error {
+	e, err := tagRules()
+	if err != nil {
+		return err
+	}
+	if e.Len() == 0 {
+		return ErrNoRules
+	}
+
+	results := make([]RuleResult, 0)
+	bs.ForEachMut(func(b *Bookmark) {
+		if rr := matchRules(e, b); rr.Changed() {
+			results = append(results, rr)
+		}
+	})
+	printRuleResults(results, bs.Len(), dryRun)
+	if dryRun || len(results) == 0 {
+		return nil
+	}
+
+	f := frame.New(frame.WithColorBorder(color.BrightBlue))
+	q := fmt.Sprintf("update tags of %d bookmarks?", len(results))
+	if !config.App.Force {
+		if err := t.ConfirmErr(f.Row("\n").Question(q).String(), "y"); err != nil {
+			return fmt.Errorf("%w", err)
+		}
+	}
+	for _, rr := range results {
+		original := *rr.Bookmark
+		b := *rr.Bookmark
+		b.Tags = rr.Tags
+		if err := updateBookmark(r, &b, &original); err != nil {
+			return err
+		}
+	}
+	success := color.BrightGreen("Successfully").Italic().String()
+	f.Clear().Success(fmt.Sprintf("%s updated %d bookmarks\n", success, len(results))).Flush()
+
+	return nil
+}
+
+// printRuleResults prints the rules that fired for each bookmark and the
+// tags they add.
+func printRuleResults(results []RuleResult, total int, dryRun bool) {
+	f := frame.New(frame.WithColorBorder(color.Gray)).Ln()
+	title := "Auto-tagging rules:"
+	if dryRun {
+		title = "Auto-tagging rules (dry run):"
+	}
+	f.Header(color.BrightGreen(title).Bold().String()).Ln()
+	for _, rr := range results {
+		bid := fmt.Sprintf(color.BrightGray("%-3d").String(), rr.Bookmark.ID)
+		url := color.Gray(format.Shorten(rr.Bookmark.URL, terminal.MinWidth)).Italic().String()
+		f.Row(fmt.Sprintf(" %s %s", bid, url)).Ln()
+		for _, m := range rr.Matches {
+			tags := color.BrightBlue(strings.Join(m.Tags, ",")).String()
+			f.Row(fmt.Sprintf("     %s %s", color.BrightMagenta(m.Rule.String()).Italic(), tags)).Ln()
+		}
+	}
+	n := fmt.Sprintf("%s of %s bookmarks get new tags", color.Blue(len(results)).Bold(), color.Blue(total).Bold())
+	f.Row("\n").Footer(n + "\n")
+	f.Flush()
+}
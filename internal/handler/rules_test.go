@@ -0,0 +1,35 @@
+package handler
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/haaag/gm/internal/bookmark/rules"
+)
+
+func TestMatchRules(t *testing.T) {
+	t.Parallel()
+	e, err := rules.New([]rules.Rule{
+		{Name: "issues", URL: `^https://github\.com/.*/issues`, Tags: "issue,github"},
+		{Domain: "arxiv.org", Tags: "paper"},
+	})
+	assert.NoError(t, err)
+
+	b := &Bookmark{URL: "https://github.com/haaag/gm/issues/1", Tags: "notag,"}
+	rr := matchRules(e, b)
+	assert.True(t, rr.Changed())
+	assert.Equal(t, "github,issue,", rr.Tags)
+	assert.Len(t, rr.Matches, 1)
+	assert.Equal(t, "issues", rr.Matches[0].Rule.String())
+
+	b = &Bookmark{URL: "https://arxiv.org/abs/1", Tags: "ml,paper,"}
+	rr = matchRules(e, b)
+	assert.False(t, rr.Changed(), "tags already present")
+	assert.Len(t, rr.Matches, 1)
+
+	b = &Bookmark{URL: "https://example.com", Tags: "go,"}
+	rr = matchRules(e, b)
+	assert.False(t, rr.Changed())
+	assert.Empty(t, rr.Matches)
+}
@@ -0,0 +1,146 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/haaag/rotato"
+
+	"github.com/haaag/gm/internal/bookmark/scraper"
+	"github.com/haaag/gm/internal/bookmark/snapshot"
+	"github.com/haaag/gm/internal/config"
+	"github.com/haaag/gm/internal/format"
+	"github.com/haaag/gm/internal/format/color"
+	"github.com/haaag/gm/internal/format/frame"
+	"github.com/haaag/gm/internal/repo"
+	"github.com/haaag/gm/internal/sys"
+	"github.com/haaag/gm/internal/sys/terminal"
+)
+
+var ErrNoSnapshot = errors.New("no offline copy")
+
+// openArchivedDelay is the time the browser has to read the extracted
+// snapshots before they are removed.
+const openArchivedDelay = 5 * time.Second
+
+// snapshotStore returns the offline snapshot store next to the database.
+func snapshotStore() *snapshot.Store {
+	return snapshot.NewStore(config.App.Path.Archive)
+}
+
+// ArchivePage saves an offline copy of the bookmark page and sets its
+// hash, returning the readable text of the page.
+func ArchivePage(b *Bookmark) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	data, err := snapshot.New(snapshot.WithContext(ctx)).Page(b.URL)
+	if err != nil {
+		return "", fmt.Errorf("%w", err)
+	}
+	hash, err := snapshotStore().Put(data)
+	if err != nil {
+		return "", fmt.Errorf("%w", err)
+	}
+	b.ArchiveHash = hash
+
+	return scraper.TextFromHTML(data), nil
+}
+
+// Archive saves an offline copy of each bookmark page.
+func Archive(r *repo.SQLiteRepository, bs *Slice) error {
+	n := bs.Len()
+	if n == 0 {
+		return repo.ErrRecordQueryNotProvided
+	}
+
+	const maxItems = 15
+	q := fmt.Sprintf("%s %d bookmarks, continue?", color.BrightGreen("archiving").Bold(), n)
+	if err := confirmUserLimit(n, maxItems, q); err != nil {
+		return err
+	}
+
+	f := frame.New(frame.WithColorBorder(color.BrightBlue))
+	f.Header(fmt.Sprintf("archiving %d bookmarks\n", n)).Flush()
+	var saved int
+	err := bs.ForEachErr(func(b Bookmark) error {
+		sp := rotato.New(
+			rotato.WithMesg("archiving "+format.Shorten(b.URL, terminal.MinWidth)+"..."),
+			rotato.WithMesgColor(rotato.ColorGray),
+			rotato.WithSpinnerColor(rotato.ColorBrightGreen),
+		)
+		sp.Start()
+		original := b
+		text, err := ArchivePage(&b)
+		sp.Done()
+
+		bid := fmt.Sprintf(color.BrightGray("%-3d").String(), b.ID)
+		if err != nil {
+			slog.Warn("archiving page", "url", b.URL, "error", err)
+			f.Clear().Row(fmt.Sprintf(" %s %s", bid, color.Red("failed").Italic())).Ln().Flush()
+			return nil
+		}
+		saved++
+		f.Clear().Row(fmt.Sprintf(" %s %s", bid, color.Gray(b.ArchiveHash[:12]).Italic())).Ln().Flush()
+		IndexBookmarkContent(r, &b, text)
+		if original.ArchiveHash == b.ArchiveHash {
+			return nil
+		}
+
+		return updateBookmark(r, &b, &original)
+	})
+	if err != nil {
+		return err
+	}
+
+	total := fmt.Sprintf("Archived %s of %s", color.Blue(saved).Bold(), color.Blue(n).Bold())
+	f.Clear().Row("\n").Footer(total + "\n").Flush()
+
+	return nil
+}
+
+// OpenArchived opens the offline copy of each bookmark in the browser.
+func OpenArchived(bs *Slice) error {
+	const maxItems = 15
+	o := color.BrightGreen("opening").Bold()
+	q := fmt.Sprintf("%s %d offline copies, continue?", o, bs.Len())
+	if err := confirmUserLimit(bs.Len(), maxItems, q); err != nil {
+		return err
+	}
+
+	st := snapshotStore()
+	extracted := make([]string, 0, bs.Len())
+	defer func() {
+		if len(extracted) == 0 {
+			return
+		}
+		// the browser opens the files asynchronously, give it time to read
+		// them before removing.
+		time.Sleep(openArchivedDelay)
+		for _, p := range extracted {
+			if err := os.Remove(p); err != nil {
+				slog.Warn("removing extracted snapshot", "path", p, "error", err)
+			}
+		}
+	}()
+
+	return bs.ForEachErr(func(b Bookmark) error {
+		if b.ArchiveHash == "" {
+			return fmt.Errorf("%w: bookmark with id=%d", ErrNoSnapshot, b.ID)
+		}
+		p, err := st.Extract(b.ArchiveHash)
+		if err != nil {
+			return fmt.Errorf("%w", err)
+		}
+		extracted = append(extracted, p)
+		if err := sys.OpenInBrowser("file://" + p); err != nil {
+			return fmt.Errorf("%w", err)
+		}
+
+		return nil
+	})
+}
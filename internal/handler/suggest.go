@@ -0,0 +1,30 @@
+package handler
+
+import (
+	"log/slog"
+
+	"github.com/haaag/gm/internal/bookmark"
+	"github.com/haaag/gm/internal/repo"
+)
+
+// maxSuggestedTags is the maximum number of suggested tags.
+const maxSuggestedTags = 5
+
+// SuggestTags returns the tags suggested for the bookmark, ranked from the
+// tags of the same domain, the page metadata and the page text.
+func SuggestTags(r *repo.SQLiteRepository, b *Bookmark, text string) []string {
+	corpus, err := repo.CounterTags(r)
+	if err != nil {
+		slog.Warn("suggesting tags", "error", err)
+		return nil
+	}
+	h := &bookmark.TagHints{Corpus: corpus}
+	if d := bookmark.Domain(b.URL); d != "" {
+		h.Domain, err = repo.CounterTagsByDomain(r, d)
+		if err != nil {
+			slog.Warn("suggesting tags", "domain", d, "error", err)
+		}
+	}
+
+	return bookmark.SuggestTags(b, text, h, maxSuggestedTags)
+}
@@ -191,7 +191,12 @@ func passwordConfirm(t *terminal.Term, f *frame.Frame) (string, error) {
 }
 
 // CheckDBNotEncrypted checks if the database is encrypted.
+//
+// Databases opened by their .enc name are decrypted in memory.
 func CheckDBNotEncrypted() error {
+	if repo.IsEncrypted(config.App.DBName) {
+		return nil
+	}
 	p := filepath.Join(config.App.Path.Data, config.App.DBName)
 	err := locker.IsLocked(p)
 	if err != nil {
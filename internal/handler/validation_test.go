@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"fmt"
 	"io"
 	"path/filepath"
 	"strings"
@@ -11,9 +12,30 @@ import (
 	"github.com/haaag/gm/internal/format/frame"
 	"github.com/haaag/gm/internal/locker"
 	"github.com/haaag/gm/internal/repo"
+	"github.com/haaag/gm/internal/sys/files"
 	"github.com/haaag/gm/internal/sys/terminal"
 )
 
+// testSetupDBFiles creates n empty database files in the given directory and
+// returns their paths.
+func testSetupDBFiles(t *testing.T, dir string, n int) []string {
+	t.Helper()
+	fs := make([]string, 0, n)
+	for i := range n {
+		p := filepath.Join(dir, fmt.Sprintf("test_%d.db", i))
+		f, err := files.Touch(p, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := f.Close(); err != nil {
+			t.Fatal(err)
+		}
+		fs = append(fs, p)
+	}
+
+	return fs
+}
+
 func TestExtractIDsFromString(t *testing.T) {
 	t.Run("extract valid IDs", func(t *testing.T) {
 		t.Parallel()
@@ -0,0 +1,209 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/haaag/gm/internal/config"
+	"github.com/haaag/gm/internal/format"
+	"github.com/haaag/gm/internal/format/color"
+	"github.com/haaag/gm/internal/format/frame"
+	"github.com/haaag/gm/internal/repo"
+	"github.com/haaag/gm/internal/sys"
+	"github.com/haaag/gm/internal/sys/terminal"
+)
+
+// defaultWatchInterval is the default clipboard polling interval.
+const defaultWatchInterval = time.Second
+
+// WatchOptions holds the options of the clipboard watcher.
+type WatchOptions struct {
+	Interval time.Duration // Clipboard polling interval
+	Tag      string        // Tag of the queued URLs
+	Ignore   []string      // Domains never saved
+	Prompt   bool          // Ask before saving
+	Notify   bool          // Use desktop notifications
+	NoFetch  bool          // Do not scrape the page
+}
+
+// WatchStats holds the counters of a watch session.
+type WatchStats struct {
+	Saved   int
+	Skipped int
+}
+
+// ClipboardWatcher polls the clipboard and saves the copied URLs to the
+// read-later inbox.
+type ClipboardWatcher struct {
+	r     *repo.SQLiteRepository
+	t     *terminal.Term
+	o     *WatchOptions
+	read  func() string
+	last  string
+	seen  map[string]bool
+	Stats WatchStats
+}
+
+// NewClipboardWatcher creates a new clipboard watcher.
+func NewClipboardWatcher(t *terminal.Term, r *repo.SQLiteRepository, o *WatchOptions) *ClipboardWatcher {
+	if o.Interval <= 0 {
+		o.Interval = defaultWatchInterval
+	}
+	if o.Tag == "" {
+		o.Tag = config.Watch.Tag
+	}
+	o.Ignore = append(o.Ignore, config.Watch.Ignore...)
+
+	return &ClipboardWatcher{
+		r:    r,
+		t:    t,
+		o:    o,
+		read: sys.ReadClipboard,
+		seen: make(map[string]bool),
+	}
+}
+
+// Watch polls the clipboard until the context is cancelled.
+//
+// The current content of the clipboard is ignored, only the URLs copied
+// after the watcher starts are saved.
+func (w *ClipboardWatcher) Watch(ctx context.Context) error {
+	w.last = w.read()
+	tk := time.NewTicker(w.o.Interval)
+	defer tk.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-tk.C:
+			s := w.read()
+			if s == w.last {
+				continue
+			}
+			w.last = s
+			if err := w.handle(s); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// handle saves the URL found in the clipboard content, if it is new.
+func (w *ClipboardWatcher) handle(s string) error {
+	u, ok := clipboardURL(s)
+	if !ok {
+		return nil
+	}
+	u = strings.TrimRight(CleanURL(u), "/")
+	k := CanonicalURL(u)
+	if w.seen[k] || watchIgnored(u, w.o.Ignore) {
+		slog.Debug("clipboard URL ignored", "url", u)
+		return nil
+	}
+	w.seen[k] = true
+	if b, exists := Duplicate(w.r, u); exists {
+		w.Stats.Skipped++
+		printWatched(u, color.Yellow(fmt.Sprintf("exists id=%d", b.ID)).Italic().String())
+		return nil
+	}
+	if w.o.Prompt && !w.confirm(u) {
+		w.Stats.Skipped++
+		printWatched(u, color.Gray("ignored").Italic().String())
+		return nil
+	}
+
+	return w.save(u)
+}
+
+// confirm asks the user whether to save the URL, with a desktop
+// notification if enabled, otherwise in the terminal.
+func (w *ClipboardWatcher) confirm(u string) bool {
+	if w.o.Notify {
+		key, err := sys.Notify(config.App.Name, "save "+u+"?", "save=Save", "ignore=Ignore")
+		if err == nil {
+			return key == "save"
+		}
+		slog.Warn("desktop notification", "error", err)
+	}
+	f := frame.New(frame.WithColorBorder(color.Gray))
+	q := f.Question("save " + color.Gray(format.Shorten(u, terminal.MinWidth)).Italic().String() + "?").String()
+
+	return w.t.Confirm(q, "y")
+}
+
+// save queues the URL with the watcher tag.
+func (w *ClipboardWatcher) save(u string) error {
+	o := &AddOptions{IfExists: IfExistsSkip, NoFetch: w.o.NoFetch, Later: true}
+	results, err := AddMany(w.r, []AddEntry{{URL: u, Tags: w.o.Tag}}, o)
+	if err != nil {
+		return err
+	}
+	res := results[0]
+	switch res.Status {
+	case AddStatusAdded:
+		w.Stats.Saved++
+		printWatched(u, color.BrightGreen(fmt.Sprintf("saved id=%d", res.ID)).Italic().String())
+		if w.o.Notify && !w.o.Prompt {
+			if _, err := sys.Notify(config.App.Name, fmt.Sprintf("saved to #%s: %s", w.o.Tag, res.Title)); err != nil {
+				slog.Warn("desktop notification", "error", err)
+			}
+		}
+	case AddStatusError:
+		printWatched(u, color.Red(res.Error).Italic().String())
+	default:
+		w.Stats.Skipped++
+	}
+
+	return nil
+}
+
+// clipboardURL returns the URL if the clipboard content is a single valid
+// URL.
+func clipboardURL(s string) (string, bool) {
+	s = strings.TrimSpace(s)
+	if s == "" || strings.ContainsAny(s, " \t\n") {
+		return "", false
+	}
+	if !URLValid(s) || !strings.HasPrefix(s, "http") {
+		return "", false
+	}
+
+	return s, true
+}
+
+// watchIgnored checks if the domain of the URL, or any of its parents, is
+// in the ignore list.
+func watchIgnored(u string, domains []string) bool {
+	p, err := url.Parse(u)
+	if err != nil {
+		return true
+	}
+	host := strings.TrimPrefix(strings.ToLower(p.Hostname()), "www.")
+	for _, d := range domains {
+		d = strings.TrimPrefix(strings.ToLower(d), "www.")
+		if d != "" && (host == d || strings.HasSuffix(host, "."+d)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// printWatched prints a URL found in the clipboard and its status.
+func printWatched(u, status string) {
+	f := frame.New(frame.WithColorBorder(color.Gray))
+	ts := color.BrightGray(time.Now().Format(time.TimeOnly)).String()
+	link := color.Gray(format.Shorten(u, terminal.MinWidth)).Italic().String()
+	f.Row(fmt.Sprintf(" %s %s %s", ts, link, status)).Ln().Flush()
+}
+
+// PrintWatchStats prints the summary of the watch session.
+func PrintWatchStats(s *WatchStats) {
+	f := frame.New(frame.WithColorBorder(color.Gray))
+	f.Footer(fmt.Sprintf("%d saved, %d skipped\n", s.Saved, s.Skipped)).Flush()
+}
@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/haaag/gm/internal/sys/terminal"
+)
+
+func TestClipboardURL(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		in   string
+		want string
+		ok   bool
+	}{
+		{"  https://example.com/a\n", "https://example.com/a", true},
+		{"http://example.com", "http://example.com", true},
+		{"see https://example.com", "", false},
+		{"ftp://example.com/file", "", false},
+		{"just some text", "", false},
+		{"", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := clipboardURL(tt.in)
+		assert.Equal(t, tt.ok, ok, tt.in)
+		assert.Equal(t, tt.want, got, tt.in)
+	}
+}
+
+func TestWatchIgnored(t *testing.T) {
+	t.Parallel()
+	ignore := []string{"localhost", "www.example.com"}
+	assert.True(t, watchIgnored("http://localhost:8080/a", ignore))
+	assert.True(t, watchIgnored("https://example.com/a", ignore))
+	assert.True(t, watchIgnored("https://docs.example.com/a", ignore))
+	assert.False(t, watchIgnored("https://notexample.com/a", ignore))
+}
+
+func TestClipboardWatcherHandle(t *testing.T) {
+	t.Parallel()
+	r := testRepo(t)
+	w := NewClipboardWatcher(terminal.New(), r, &WatchOptions{
+		Tag:     "inbox",
+		Ignore:  []string{"ignored.com"},
+		NoFetch: true,
+	})
+	for _, s := range []string{
+		"https://example.com/a?utm_source=x",
+		"https://example.com/a",
+		"https://ignored.com/b",
+		"not a url",
+		"https://example.com/b/",
+	} {
+		assert.NoError(t, w.handle(s))
+	}
+	assert.Equal(t, WatchStats{Saved: 2}, w.Stats)
+
+	b, ok := r.HasCanonical("https://example.com/b")
+	assert.True(t, ok)
+	assert.Equal(t, "inbox", b.Tags)
+
+	w = NewClipboardWatcher(terminal.New(), r, &WatchOptions{NoFetch: true})
+	assert.NoError(t, w.handle("https://example.com/a"))
+	assert.Equal(t, WatchStats{Skipped: 1}, w.Stats)
+}
@@ -0,0 +1,197 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/haaag/rotato"
+
+	"github.com/haaag/gm/internal/bookmark"
+	"github.com/haaag/gm/internal/bookmark/wayback"
+	"github.com/haaag/gm/internal/config"
+	"github.com/haaag/gm/internal/format"
+	"github.com/haaag/gm/internal/format/color"
+	"github.com/haaag/gm/internal/format/frame"
+	"github.com/haaag/gm/internal/repo"
+	"github.com/haaag/gm/internal/slice"
+	"github.com/haaag/gm/internal/sys"
+	"github.com/haaag/gm/internal/sys/terminal"
+)
+
+// newWayback creates a Wayback Machine client from the app configuration.
+func newWayback() *wayback.Wayback {
+	return wayback.New(
+		wayback.WithAPIURL(config.Wayback.APIURL),
+		wayback.WithSaveURL(config.Wayback.SaveURL),
+	)
+}
+
+// CheckStatusArchive checks the status of the bookmarks and looks up an
+// archived copy for the dead ones.
+//
+// If snapshot is true, requests a new archived copy for the live ones.
+func CheckStatusArchive(t *terminal.Term, r *repo.SQLiteRepository, bs *Slice, snapshot bool) error {
+	n := bs.Len()
+	if n == 0 {
+		return repo.ErrRecordQueryNotProvided
+	}
+
+	const maxGoroutines = 15
+	status := color.BrightGreen("status").Bold()
+	q := fmt.Sprintf("checking %s of %d, continue?", status, n)
+	if err := confirmUserLimit(n, maxGoroutines, q); err != nil {
+		return sys.ErrActionAborted
+	}
+
+	f := frame.New(frame.WithColorBorder(color.BrightBlue))
+	f.Header(fmt.Sprintf("checking %s of %d bookmarks\n", status, n)).Flush()
+	responses, err := bookmark.StatusResponses(bs)
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+
+	dead := make(map[int]bool, responses.Len())
+	responses.ForEach(func(res bookmark.Response) {
+		dead[res.ID()] = res.Dead()
+	})
+
+	wb := newWayback()
+	if err := archiveDead(t, r, bs.Filter(func(b Bookmark) bool { return dead[b.ID] }), wb); err != nil {
+		return err
+	}
+	if !snapshot {
+		return nil
+	}
+
+	return archiveLive(t, r, bs.Filter(func(b Bookmark) bool { return !dead[b.ID] }), wb)
+}
+
+// archiveDead looks up the closest archived copy for the dead bookmarks and
+// lets the user replace the URL or attach the archived copy.
+func archiveDead(t *terminal.Term, r *repo.SQLiteRepository, bs *Slice, wb *wayback.Wayback) error {
+	if bs.Empty() {
+		return nil
+	}
+
+	results, err := withSpinner("looking up archived copies...", func() (*slice.Slice[bookmark.Archived], error) {
+		return bookmark.ClosestSnapshots(bs, wb)
+	})
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+
+	found := results.Filter(func(a bookmark.Archived) bool { return a.Found })
+	printArchived(results, "Archived copies:", found.Len())
+
+	return found.ForEachErr(func(a bookmark.Archived) error {
+		return applyArchived(t, r, &a)
+	})
+}
+
+// archiveLive requests a new archived copy for the live bookmarks and
+// attaches it.
+func archiveLive(t *terminal.Term, r *repo.SQLiteRepository, bs *Slice, wb *wayback.Wayback) error {
+	if bs.Empty() {
+		return nil
+	}
+
+	f := frame.New(frame.WithColorBorder(color.BrightBlue))
+	q := fmt.Sprintf("request %s of %d live bookmarks?", color.BrightGreen("snapshot").Bold(), bs.Len())
+	if !config.App.Force {
+		if err := t.ConfirmErr(f.Question(q).String(), "n"); err != nil {
+			if errors.Is(err, terminal.ErrActionAborted) {
+				return nil
+			}
+
+			return fmt.Errorf("%w", err)
+		}
+	}
+
+	results, err := withSpinner("requesting snapshots...", func() (*slice.Slice[bookmark.Archived], error) {
+		return bookmark.SaveSnapshots(bs, wb)
+	})
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+
+	found := results.Filter(func(a bookmark.Archived) bool { return a.Found })
+	printArchived(results, "Snapshots saved:", found.Len())
+
+	return found.ForEachErr(func(a bookmark.Archived) error {
+		return attachArchived(r, &a)
+	})
+}
+
+// applyArchived prompts the user to replace the bookmark URL with the
+// archived copy or attach it.
+func applyArchived(t *terminal.Term, r *repo.SQLiteRepository, a *bookmark.Archived) error {
+	if config.App.Force {
+		return attachArchived(r, a)
+	}
+
+	f := frame.New(frame.WithColorBorder(color.BrightBlue))
+	id := color.BrightYellow(a.Bookmark.ID).Bold().String()
+	f.Header(id + " " + color.Gray(format.Shorten(a.Bookmark.URL, terminal.MinWidth)).Italic().String()).Ln()
+	f.Mid(color.BrightMagenta(format.Shorten(a.URL, terminal.MinWidth)).String()).Ln()
+	opt, err := t.Choose(f.Question("archived copy found").String(), []string{"replace", "attach", "skip"}, "s")
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+
+	switch strings.ToLower(opt) {
+	case "r", "replace":
+		original := *a.Bookmark
+		b := *a.Bookmark
+		b.URL = a.URL
+
+		return updateBookmark(r, &b, &original)
+	case "a", "attach":
+		return attachArchived(r, a)
+	}
+
+	return nil
+}
+
+// attachArchived stores the archived copy URL in the bookmark.
+func attachArchived(r *repo.SQLiteRepository, a *bookmark.Archived) error {
+	original := *a.Bookmark
+	if original.ArchiveURL == a.URL {
+		return nil
+	}
+	b := *a.Bookmark
+	b.ArchiveURL = a.URL
+
+	return updateBookmark(r, &b, &original)
+}
+
+// printArchived prints a summary of the archive results.
+func printArchived(results *slice.Slice[bookmark.Archived], title string, found int) {
+	f := frame.New(frame.WithColorBorder(color.Gray)).Ln()
+	f.Header(color.BrightGreen(title).Bold().String()).Ln()
+	results.ForEach(func(a bookmark.Archived) {
+		bid := fmt.Sprintf(color.BrightGray("%-3d").String(), a.Bookmark.ID)
+		if !a.Found {
+			f.Row(fmt.Sprintf(" %s %s", bid, color.Red("not found").Italic())).Ln()
+			return
+		}
+		url := color.Gray(format.Shorten(a.URL, terminal.MinWidth)).Italic().String()
+		f.Row(fmt.Sprintf(" %s %s", bid, url)).Ln()
+	})
+	total := fmt.Sprintf("Found %s of %s", color.Blue(found).Bold(), color.Blue(results.Len()).Bold())
+	f.Row("\n").Footer(total + "\n")
+	f.Flush()
+}
+
+// withSpinner runs fn while showing a spinner.
+func withSpinner[T any](mesg string, fn func() (T, error)) (T, error) {
+	sp := rotato.New(
+		rotato.WithMesg(mesg),
+		rotato.WithMesgColor(rotato.ColorBrightGreen),
+		rotato.WithSpinnerColor(rotato.ColorBrightGreen),
+	)
+	sp.Start()
+	defer sp.Done()
+
+	return fn()
+}
@@ -0,0 +1,156 @@
+package locker
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"filippo.io/age"
+
+	"github.com/haaag/gm/internal/sys/files"
+)
+
+var (
+	ErrAgeNoRecipients = errors.New("no age recipients")
+	ErrAgeNoIdentity   = errors.New("age identity required to decrypt")
+	ErrAgeRecipient    = errors.New("invalid age recipient")
+	ErrAgeIdentity     = errors.New("invalid age identity file")
+)
+
+// ageMagic is the start of the files in the age format.
+var ageMagic = []byte("age-encryption.org/")
+
+// recipientsExt is the extension of the file next to an age file with its
+// recipients, the age format does not store them.
+const recipientsExt = ".recipients"
+
+// IsAge reports whether the data is encrypted in the age format.
+func IsAge(data []byte) bool {
+	return bytes.HasPrefix(data, ageMagic)
+}
+
+// ParseRecipients parses the age public keys and the ones in the
+// recipients file, if any. The file has one recipient per line, lines
+// starting with '#' are comments.
+func ParseRecipients(keys []string, file string) ([]age.Recipient, error) {
+	rs := make([]age.Recipient, 0, len(keys))
+	for _, s := range keys {
+		r, err := age.ParseX25519Recipient(strings.TrimSpace(s))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrAgeRecipient, err)
+		}
+		rs = append(rs, r)
+	}
+	if file != "" {
+		f, err := os.Open(file)
+		if err != nil {
+			return nil, fmt.Errorf("recipients file: %w", err)
+		}
+		defer f.Close()
+		fr, err := age.ParseRecipients(f)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q: %w", ErrAgeRecipient, file, err)
+		}
+		rs = append(rs, fr...)
+	}
+
+	return rs, nil
+}
+
+// ParseIdentities parses the identities of the age identity file.
+func ParseIdentities(file string) ([]age.Identity, error) {
+	if file == "" {
+		return nil, ErrAgeNoIdentity
+	}
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, fmt.Errorf("identity file: %w", err)
+	}
+	defer f.Close()
+	ids, err := age.ParseIdentities(f)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %q: %w", ErrAgeIdentity, file, err)
+	}
+
+	return ids, nil
+}
+
+// RecipientsPath returns the path of the recipients file of the age file.
+func RecipientsPath(p string) string {
+	return p + recipientsExt
+}
+
+// WriteRecipients records the recipients of the age file at p next to it,
+// to encrypt it back to all of them.
+func WriteRecipients(p string, rs []age.Recipient) error {
+	var b strings.Builder
+	b.WriteString("# recipients of " + filepath.Base(p) + "\n")
+	for _, r := range rs {
+		s, ok := r.(fmt.Stringer)
+		if !ok {
+			return fmt.Errorf("%w: %T can not be recorded", ErrAgeRecipient, r)
+		}
+		b.WriteString(s.String() + "\n")
+	}
+	if err := os.WriteFile(RecipientsPath(p), []byte(b.String()), files.FilePerm); err != nil {
+		return fmt.Errorf("recipients file: %w", err)
+	}
+
+	return nil
+}
+
+// ReadRecipients returns the recipients recorded for the age file at p.
+func ReadRecipients(p string) ([]age.Recipient, error) {
+	return ParseRecipients(nil, RecipientsPath(p))
+}
+
+// NewAgeKey returns a key that encrypts to the recipients and decrypts
+// with the identities, in the age format. Either can be empty.
+func NewAgeKey(identities []age.Identity, recipients []age.Recipient) *Key {
+	return &Key{
+		identities: identities,
+		recipients: recipients,
+		age:        true,
+	}
+}
+
+// ageEncrypt encrypts the data to the recipients.
+func ageEncrypt(plaintext []byte, recipients []age.Recipient) ([]byte, error) {
+	if len(recipients) == 0 {
+		return nil, ErrAgeNoRecipients
+	}
+	var buf bytes.Buffer
+	w, err := age.Encrypt(&buf, recipients...)
+	if err != nil {
+		return nil, fmt.Errorf("age: %w", err)
+	}
+	if _, err := w.Write(plaintext); err != nil {
+		return nil, fmt.Errorf("age: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return nil, fmt.Errorf("age: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// ageDecrypt decrypts the data with the identities.
+func ageDecrypt(ciphertext []byte, identities []age.Identity) ([]byte, error) {
+	if len(identities) == 0 {
+		return nil, ErrAgeNoIdentity
+	}
+	r, err := age.Decrypt(bytes.NewReader(ciphertext), identities...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrDecryption, err)
+	}
+	plaintext, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrDecryption, err)
+	}
+
+	return plaintext, nil
+}
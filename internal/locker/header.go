@@ -0,0 +1,127 @@
+package locker
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+
+	"golang.org/x/crypto/argon2"
+)
+
+// errHeaderNotExists is returned when the file is a legacy file.
+var errHeaderNotExists = errors.New("no encryption header")
+
+// magic identifies the encrypted files with a header. Files without it are
+// legacy files, encrypted with a SHA-256 key.
+var magic = []byte("GMENC")
+
+const (
+	headerVersion = 1
+
+	// key derivation functions.
+	kdfArgon2id = 1
+
+	// Argon2id parameters, see RFC 9106.
+	argonTime    = 3
+	argonMemory  = 64 * 1024 // KiB
+	argonThreads = 4
+	argonKeyLen  = 32
+
+	// bounds of the parameters read from a header, before it is
+	// authenticated: a tampered header must not make the derivation hang or
+	// allocate more than a small multiple of the defaults.
+	maxArgonTime   = 64
+	maxArgonMemory = 16 * argonMemory // KiB, 1 GiB
+
+	saltLen = 16
+
+	// magic, version, kdf, time, memory, threads, salt length and salt.
+	headerLen = 5 + 1 + 1 + 4 + 4 + 1 + 1 + saltLen
+)
+
+// header holds the version and key derivation parameters of an encrypted
+// file.
+type header struct {
+	version byte
+	kdf     byte
+	time    uint32
+	memory  uint32 // KiB
+	threads uint8
+	salt    []byte
+}
+
+// newHeader returns a header with the current parameters and a random salt.
+func newHeader() (*header, error) {
+	salt := make([]byte, saltLen)
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return nil, fmt.Errorf("salt generation failed: %w", err)
+	}
+
+	return &header{
+		version: headerVersion,
+		kdf:     kdfArgon2id,
+		time:    argonTime,
+		memory:  argonMemory,
+		threads: argonThreads,
+		salt:    salt,
+	}, nil
+}
+
+// key derives the 32-byte key from the passphrase.
+func (h *header) key(passphrase string) []byte {
+	slog.Debug("deriving key from passphrase", "kdf", "argon2id")
+	return argon2.IDKey([]byte(passphrase), h.salt, h.time, h.memory, h.threads, argonKeyLen)
+}
+
+// marshal returns the header bytes, authenticated as additional data of
+// the ciphertext.
+func (h *header) marshal() []byte {
+	b := make([]byte, 0, headerLen)
+	b = append(b, magic...)
+	b = append(b, h.version, h.kdf)
+	b = binary.BigEndian.AppendUint32(b, h.time)
+	b = binary.BigEndian.AppendUint32(b, h.memory)
+	b = append(b, h.threads, byte(len(h.salt)))
+
+	return append(b, h.salt...)
+}
+
+// parseHeader reads the header at the start of the data, returning the
+// header and the remaining ciphertext. Returns errHeaderNotExists for
+// legacy files.
+func parseHeader(data []byte) (*header, []byte, error) {
+	if !bytes.HasPrefix(data, magic) {
+		return nil, data, errHeaderNotExists
+	}
+	if len(data) < headerLen {
+		return nil, nil, fmt.Errorf("%w: header", ErrCipherTextShort)
+	}
+	b := data[len(magic):]
+	h := &header{version: b[0], kdf: b[1]}
+	if h.version != headerVersion {
+		return nil, nil, fmt.Errorf("%w: %d", ErrHeaderVersion, h.version)
+	}
+	if h.kdf != kdfArgon2id {
+		return nil, nil, fmt.Errorf("%w: %d", ErrKDFUnsupported, h.kdf)
+	}
+	h.time = binary.BigEndian.Uint32(b[2:6])
+	h.memory = binary.BigEndian.Uint32(b[6:10])
+	h.threads = b[10]
+	if n := int(b[11]); n != saltLen {
+		return nil, nil, fmt.Errorf("%w: salt length %d", ErrHeaderInvalid, n)
+	}
+	h.salt = append([]byte(nil), b[12:12+saltLen]...)
+	// reject parameters that would make the derivation hang or fail.
+	if h.time == 0 || h.time > maxArgonTime {
+		return nil, nil, fmt.Errorf("%w: time %d", ErrHeaderInvalid, h.time)
+	}
+	if h.threads == 0 || h.memory < 8*uint32(h.threads) || h.memory > maxArgonMemory {
+		return nil, nil, fmt.Errorf("%w: memory %d, threads %d", ErrHeaderInvalid, h.memory, h.threads)
+	}
+
+	return h, data[headerLen:], nil
+}
@@ -0,0 +1,207 @@
+package locker
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+
+	"filippo.io/age"
+)
+
+var (
+	ErrKeyMismatch = errors.New("key does not match the encrypted file")
+	ErrKeyInvalid  = errors.New("invalid key")
+)
+
+// Key is a key derived from a passphrase, with the header holding its
+// derivation parameters. Legacy keys have no header.
+//
+// A key encrypts with its header, the salt is kept and the key stays valid
+// for the file after writing it again.
+//
+// Age keys hold identities and recipients instead, see NewAgeKey.
+type Key struct {
+	header     *header
+	secret     []byte
+	identities []age.Identity
+	recipients []age.Recipient
+	age        bool
+}
+
+// NewKey derives a key from the passphrase with a new salt.
+func NewKey(passphrase string) (*Key, error) {
+	if passphrase == "" {
+		return nil, ErrPassphraseEmpty
+	}
+	h, err := newHeader()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Key{header: h, secret: h.key(passphrase)}, nil
+}
+
+// DeriveKey derives the key of the encrypted data from the passphrase, with
+// the parameters of its header.
+func DeriveKey(ciphertext []byte, passphrase string) (*Key, error) {
+	if passphrase == "" {
+		return nil, ErrPassphraseEmpty
+	}
+	if IsAge(ciphertext) {
+		return nil, ErrAgeNoIdentity
+	}
+	h, _, err := parseHeader(ciphertext)
+	if errors.Is(err, errHeaderNotExists) {
+		return &Key{secret: legacyKey(passphrase)}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &Key{header: h, secret: h.key(passphrase)}, nil
+}
+
+// KeyID returns the ID of the key of the encrypted data, empty for legacy
+// and age files.
+func KeyID(ciphertext []byte) (string, error) {
+	if IsAge(ciphertext) {
+		return "", nil
+	}
+	h, _, err := parseHeader(ciphertext)
+	if errors.Is(err, errHeaderNotExists) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return h.id(), nil
+}
+
+// ID identifies the key by its header, empty for legacy and age keys.
+func (k *Key) ID() string {
+	if k.header == nil {
+		return ""
+	}
+
+	return k.header.id()
+}
+
+// CanEncrypt reports whether the key encrypts, age keys need recipients.
+func (k *Key) CanEncrypt() bool {
+	return !k.age || len(k.recipients) > 0
+}
+
+// Encrypt encrypts the data using AES-GCM, prepending the header and the
+// nonce. Legacy keys write the legacy format, age keys the age format.
+func (k *Key) Encrypt(plaintext []byte) ([]byte, error) {
+	if k.age {
+		return ageEncrypt(plaintext, k.recipients)
+	}
+	gcm, err := newGCM(k.secret)
+	if err != nil {
+		return nil, err
+	}
+	// Create a nonce (number used once)
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err = io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, fmt.Errorf("nonce generation failed: %w", err)
+	}
+	var hb []byte
+	if k.header != nil {
+		hb = k.header.marshal()
+	}
+	out := make([]byte, 0, len(hb)+len(nonce)+len(plaintext)+gcm.Overhead())
+	out = append(append(out, hb...), nonce...)
+
+	return gcm.Seal(out, nonce, plaintext, hb), nil
+}
+
+// Decrypt decrypts the data encrypted with the key.
+func (k *Key) Decrypt(ciphertext []byte) ([]byte, error) {
+	if IsAge(ciphertext) != k.age {
+		return nil, ErrKeyMismatch
+	}
+	if k.age {
+		return ageDecrypt(ciphertext, k.identities)
+	}
+	h, rest, err := parseHeader(ciphertext)
+	var ad []byte
+	switch {
+	case errors.Is(err, errHeaderNotExists):
+		if k.header != nil {
+			return nil, ErrKeyMismatch
+		}
+	case err != nil:
+		return nil, err
+	default:
+		if k.header == nil || h.id() != k.header.id() {
+			return nil, ErrKeyMismatch
+		}
+		ad = ciphertext[:len(ciphertext)-len(rest)]
+	}
+	gcm, err := newGCM(k.secret)
+	if err != nil {
+		return nil, err
+	}
+	// Verify the ciphertext is long enough
+	nonceSize := gcm.NonceSize()
+	if len(rest) < nonceSize+gcm.Overhead() {
+		return nil, ErrCipherTextShort
+	}
+	// Extract the nonce
+	nonce, rest := rest[:nonceSize], rest[nonceSize:]
+	// Decrypt the data
+	plaintext, err := gcm.Open(nil, nonce, rest, ad)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrDecryption, err)
+	}
+
+	return plaintext, nil
+}
+
+// MarshalBinary returns the header and the secret of the key.
+func (k *Key) MarshalBinary() ([]byte, error) {
+	if k.age {
+		return nil, fmt.Errorf("%w: age keys are not marshalled", ErrKeyInvalid)
+	}
+	var b []byte
+	if k.header != nil {
+		b = k.header.marshal()
+	}
+
+	return append(b, k.secret...), nil
+}
+
+// UnmarshalBinary reads a key written by MarshalBinary.
+func (k *Key) UnmarshalBinary(b []byte) error {
+	switch len(b) {
+	case argonKeyLen:
+		k.header = nil
+	case headerLen + argonKeyLen:
+		h, _, err := parseHeader(b)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrKeyInvalid, err)
+		}
+		k.header = h
+	default:
+		return fmt.Errorf("%w: length %d", ErrKeyInvalid, len(b))
+	}
+	k.secret = append([]byte(nil), b[len(b)-argonKeyLen:]...)
+
+	return nil
+}
+
+// Zero overwrites the secret of the key in memory.
+func (k *Key) Zero() {
+	clear(k.secret)
+}
+
+// id returns the hex encoded checksum of the header.
+func (h *header) id() string {
+	sum := sha256.Sum256(h.marshal())
+	return hex.EncodeToString(sum[:16])
+}
@@ -3,11 +3,9 @@ package locker
 import (
 	"crypto/aes"
 	"crypto/cipher"
-	"crypto/rand"
 	"crypto/sha256"
 	"errors"
 	"fmt"
-	"io"
 	"log/slog"
 	"os"
 	"path/filepath"
@@ -24,15 +22,34 @@ var (
 	ErrFileNotEncrypted   = errors.New("file not encrypted")
 	ErrFileExtMismatch    = errors.New("file must have .enc extension")
 	ErrCipherTextShort    = errors.New("ciphertext too short")
+	ErrDecryption         = errors.New("decryption failed, wrong password or corrupted file")
+	ErrHeaderInvalid      = errors.New("invalid encryption header")
+	ErrHeaderVersion      = errors.New("unsupported encryption header version")
+	ErrKDFUnsupported     = errors.New("unsupported key derivation function")
+	ErrFileUpToDate       = errors.New("file already uses the current encryption format")
 )
 
 // Lock encrypts the given file using AES-GCM encryption and adds .enc
 // extension.
 func Lock(path, passphrase string) error {
-	slog.Debug("locking file", "path", path)
 	if err := validateInput(path, passphrase); err != nil {
 		return err
 	}
+	k, err := NewKey(passphrase)
+	if err != nil {
+		return err
+	}
+	defer k.Zero()
+
+	return LockKey(path, k)
+}
+
+// LockKey encrypts the given file with the key and adds .enc extension.
+func LockKey(path string, k *Key) error {
+	slog.Debug("locking file", "path", path)
+	if !files.Exists(path) {
+		return fmt.Errorf("%w: %s", files.ErrFileNotFound, path)
+	}
 	// Create a backup
 	backupPath, err := backupFile(path)
 	if err != nil {
@@ -44,7 +61,7 @@ func Lock(path, passphrase string) error {
 		return fmt.Errorf("failed to read input file: %w", err)
 	}
 	// Perform encryption
-	ciphertext, err := encrypt(plaintext, passphrase)
+	ciphertext, err := k.Encrypt(plaintext)
 	if err != nil {
 		return err
 	}
@@ -63,20 +80,32 @@ func Lock(path, passphrase string) error {
 
 // Unlock decrypts the given .enc file using AES-GCM decryption and removes the .enc extension.
 func Unlock(path, passphrase string) error {
-	slog.Debug("unlocking file", "path", path)
 	if err := validateInput(path, passphrase); err != nil {
 		return err
 	}
-	if !strings.HasSuffix(path, ".enc") {
-		return fmt.Errorf("%w: got %q", ErrFileExtMismatch, filepath.Ext(path))
+	ciphertext, err := ReadFile(path)
+	if err != nil {
+		return err
 	}
-	// Read the encrypted content
-	ciphertext, err := os.ReadFile(path)
+	k, err := DeriveKey(ciphertext, passphrase)
 	if err != nil {
-		return fmt.Errorf("failed to read encrypted file: %w", err)
+		return err
+	}
+	defer k.Zero()
+
+	return UnlockKey(path, k)
+}
+
+// UnlockKey decrypts the given .enc file with the key and removes the .enc
+// extension.
+func UnlockKey(path string, k *Key) error {
+	slog.Debug("unlocking file", "path", path)
+	ciphertext, err := ReadFile(path)
+	if err != nil {
+		return err
 	}
 	// Perform decryption
-	plaintext, err := decrypt(ciphertext, passphrase)
+	plaintext, err := k.Decrypt(ciphertext)
 	if err != nil {
 		return err
 	}
@@ -94,63 +123,131 @@ func Unlock(path, passphrase string) error {
 	slog.Debug("file unlocked", "path", decryptedPath)
 	// Cleanup successful operation
 	_ = os.Remove(backupPath)
+	_ = os.Remove(RecipientsPath(path))
 
 	return nil
 }
 
-// encrypt encrypts data using AES-GCM with the given passphrase.
+// encrypt encrypts data using AES-GCM with a key derived from the
+// passphrase with Argon2id. The header is prepended and authenticated.
 func encrypt(plaintext []byte, passphrase string) ([]byte, error) {
-	// Generate a key from the passphrase
-	key := generateKey(passphrase)
-	// Create a new AES cipher block
+	k, err := NewKey(passphrase)
+	if err != nil {
+		return nil, err
+	}
+	defer k.Zero()
+
+	return k.Encrypt(plaintext)
+}
+
+// decrypt decrypts data using AES-GCM with the given passphrase, reading
+// the key derivation parameters from the header. Legacy files without
+// header use a SHA-256 key.
+func decrypt(ciphertext []byte, passphrase string) ([]byte, error) {
+	k, err := DeriveKey(ciphertext, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	defer k.Zero()
+
+	return k.Decrypt(ciphertext)
+}
+
+// newGCM returns the AES-GCM cipher for the key.
+func newGCM(key []byte) (cipher.AEAD, error) {
 	block, err := aes.NewCipher(key)
 	if err != nil {
 		return nil, fmt.Errorf("cipher creation failed: %w", err)
 	}
-	// Create a new GCM mode
 	gcm, err := cipher.NewGCM(block)
 	if err != nil {
 		return nil, fmt.Errorf("GCM mode creation failed: %w", err)
 	}
-	// Create a nonce (number used once)
-	nonce := make([]byte, gcm.NonceSize())
-	if _, err = io.ReadFull(rand.Reader, nonce); err != nil {
-		return nil, fmt.Errorf("nonce generation failed: %w", err)
+
+	return gcm, nil
+}
+
+// isLegacy reports whether the encrypted data has no header.
+func isLegacy(ciphertext []byte) bool {
+	_, _, err := parseHeader(ciphertext)
+	return errors.Is(err, errHeaderNotExists)
+}
+
+// Rekey encrypts again a legacy .enc file with the current header and key
+// derivation. Returns ErrFileUpToDate if the file already has a header.
+func Rekey(path, passphrase string) error {
+	slog.Debug("rekeying file", "path", path)
+	return reencrypt(path, passphrase, passphrase, true)
+}
+
+// Passwd changes the passphrase of the .enc file. The file is decrypted in
+// memory, the plaintext is never written to disk.
+func Passwd(path, oldPassphrase, newPassphrase string) error {
+	slog.Debug("changing passphrase", "path", path)
+	if newPassphrase == "" {
+		return ErrPassphraseEmpty
+	}
+
+	return reencrypt(path, oldPassphrase, newPassphrase, false)
+}
+
+// ReadFile reads the content of the .enc file.
+func ReadFile(path string) ([]byte, error) {
+	if !strings.HasSuffix(path, ".enc") {
+		return nil, fmt.Errorf("%w: got %q", ErrFileExtMismatch, filepath.Ext(path))
+	}
+	if !files.Exists(path) {
+		return nil, fmt.Errorf("%w: %s", files.ErrFileNotFound, path)
+	}
+	ciphertext, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read encrypted file: %w", err)
 	}
-	// Encrypt the data and prepend nonce
-	ciphertext := gcm.Seal(nonce, nonce, plaintext, nil)
 
 	return ciphertext, nil
 }
 
-// decrypt decrypts data using AES-GCM with the given passphrase.
-func decrypt(ciphertext []byte, passphrase string) ([]byte, error) {
-	// Generate the key from the passphrase
-	key := generateKey(passphrase)
-	// Create a new AES cipher block
-	block, err := aes.NewCipher(key)
+// Replace replaces the content of the file, keeping a backup until the new
+// content is written.
+func Replace(path string, data []byte) error {
+	backupPath, err := backupFile(path)
 	if err != nil {
-		return nil, fmt.Errorf("cipher creation failed: %w", err)
+		return fmt.Errorf("backup creation failed: %w", err)
 	}
-	// Create a new GCM mode
-	gcm, err := cipher.NewGCM(block)
+	if err := writeAndReplaceFile(path, data, path, backupPath); err != nil {
+		return err
+	}
+	_ = os.Remove(backupPath)
+
+	return nil
+}
+
+// reencrypt decrypts the .enc file in memory and encrypts it again with the
+// new passphrase, replacing the file. With legacyOnly, files that already
+// have a header are left untouched.
+func reencrypt(path, oldPassphrase, newPassphrase string, legacyOnly bool) error {
+	if err := validateInput(path, oldPassphrase); err != nil {
+		return err
+	}
+	if !strings.HasSuffix(path, ".enc") {
+		return fmt.Errorf("%w: got %q", ErrFileExtMismatch, filepath.Ext(path))
+	}
+	ciphertext, err := os.ReadFile(path)
 	if err != nil {
-		return nil, fmt.Errorf("GCM mode creation failed: %w", err)
+		return fmt.Errorf("failed to read encrypted file: %w", err)
 	}
-	// Verify the ciphertext is long enough
-	nonceSize := gcm.NonceSize()
-	if len(ciphertext) < nonceSize {
-		return nil, ErrCipherTextShort
+	if legacyOnly && !isLegacy(ciphertext) {
+		return fmt.Errorf("%w: %q", ErrFileUpToDate, filepath.Base(path))
 	}
-	// Extract the nonce
-	nonce, ciphertext := ciphertext[:nonceSize], ciphertext[nonceSize:]
-	// Decrypt the data
-	plaintext, err := gcm.Open(nil, nonce, ciphertext, nil)
+	plaintext, err := decrypt(ciphertext, oldPassphrase)
 	if err != nil {
-		return nil, fmt.Errorf("decryption failed: %w", err)
+		return err
 	}
-
-	return plaintext, nil
+	ciphertext, err = encrypt(plaintext, newPassphrase)
+	if err != nil {
+		return err
+	}
+	return Replace(path, ciphertext)
 }
 
 // IsLocked checks if the given file has .enc extension.
@@ -167,9 +264,10 @@ func IsLocked(s string) error {
 	return nil
 }
 
-// generateKey creates a 32-byte key from the passphrase using SHA-256.
-func generateKey(passphrase string) []byte {
-	slog.Debug("generating key from passphrase")
+// legacyKey creates the 32-byte key of the legacy files from the
+// passphrase using SHA-256.
+func legacyKey(passphrase string) []byte {
+	slog.Debug("generating legacy key from passphrase")
 	hash := sha256.Sum256([]byte(passphrase))
 	return hash[:]
 }
@@ -213,12 +311,19 @@ func backupFile(filePath string) (string, error) {
 }
 
 // writeAndReplaceFile writes data to targetPath, removes originalPath on
-// success, and handles error recovery using backupPath.
+// success, and handles error recovery using backupPath. The original file
+// is replaced in place if both paths are the same.
 func writeAndReplaceFile(targetPath string, data []byte, originalPath, backupPath string) error {
 	slog.Debug("writing file", "path", targetPath)
-	// Write data to the target file
-	err := os.WriteFile(targetPath, data, files.FilePerm)
+	// Write data next to the target file and move it into place, the target
+	// is never left half written.
+	tmpPath := targetPath + ".tmp"
+	err := os.WriteFile(tmpPath, data, files.FilePerm)
+	if err == nil {
+		err = os.Rename(tmpPath, targetPath)
+	}
 	if err != nil {
+		_ = os.Remove(tmpPath)
 		// If writing fails, attempt to restore from backup
 		slog.Debug("restore from backup", "path", backupPath)
 		restoreErr := os.Rename(backupPath, originalPath)
@@ -233,6 +338,10 @@ func writeAndReplaceFile(targetPath string, data []byte, originalPath, backupPat
 		return fmt.Errorf("failed to write file: %w", err)
 	}
 
+	if originalPath == targetPath {
+		slog.Debug("replaced file", "path", targetPath)
+		return nil
+	}
 	slog.Debug("replacing file", "original", originalPath, "target", targetPath)
 	// Remove the original file
 	err = os.Remove(originalPath)
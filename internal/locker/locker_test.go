@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 	"testing"
 
+	"filippo.io/age"
 	"github.com/stretchr/testify/assert"
 
 	"github.com/haaag/gm/internal/sys/files"
@@ -196,3 +197,271 @@ func TestWriteAndReplaceFile(t *testing.T) {
 		assert.Equal(t, originalContent, currentContent)
 	})
 }
+
+// legacyEncrypt encrypts the data like the files without header.
+func legacyEncrypt(t *testing.T, plaintext []byte, passphrase string) []byte {
+	t.Helper()
+	gcm, err := newGCM(legacyKey(passphrase))
+	assert.NoError(t, err)
+	nonce := make([]byte, gcm.NonceSize())
+
+	return gcm.Seal(nonce, nonce, plaintext, nil)
+}
+
+func TestDecryptErrors(t *testing.T) {
+	t.Parallel()
+	pp := "123456"
+	b := []byte("Lorem ipsum dolor sit amet")
+	ciphertext, err := encrypt(b, pp)
+	assert.NoError(t, err)
+	assert.Equal(t, magic, ciphertext[:len(magic)])
+	tamper := func(i int, v byte) []byte {
+		c := append([]byte(nil), ciphertext...)
+		c[i] = v
+
+		return c
+	}
+	tests := []struct {
+		name string
+		data []byte
+		pass string
+		err  error
+	}{
+		{"wrong password", ciphertext, "654321", ErrDecryption},
+		{"truncated header", ciphertext[:headerLen-1], pp, ErrCipherTextShort},
+		{"truncated ciphertext", ciphertext[:headerLen+4], pp, ErrCipherTextShort},
+		{"truncated tag", ciphertext[:len(ciphertext)-1], pp, ErrDecryption},
+		{"tampered version", tamper(len(magic), 9), pp, ErrHeaderVersion},
+		{"tampered kdf", tamper(len(magic)+1, 9), pp, ErrKDFUnsupported},
+		{"tampered time", tamper(len(magic)+2, 0xff), pp, ErrHeaderInvalid},
+		{"tampered threads", tamper(len(magic)+10, 0), pp, ErrHeaderInvalid},
+		{"tampered salt", tamper(headerLen-1, ^ciphertext[headerLen-1]), pp, ErrDecryption},
+		{"tampered data", tamper(len(ciphertext)-20, ^ciphertext[len(ciphertext)-20]), pp, ErrDecryption},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := decrypt(tt.data, tt.pass)
+			assert.ErrorIs(t, err, tt.err)
+		})
+	}
+}
+
+func TestParseHeaderMemory(t *testing.T) {
+	t.Parallel()
+	h, err := newHeader()
+	assert.NoError(t, err)
+	tests := []struct {
+		name   string
+		memory uint32
+		err    error
+	}{
+		{"default", argonMemory, nil},
+		{"at the cap", maxArgonMemory, nil},
+		{"above the cap", maxArgonMemory + 1, ErrHeaderInvalid},
+		{"4 GiB", 4 * 1024 * 1024, ErrHeaderInvalid},
+		{"below the threads", 8*argonThreads - 1, ErrHeaderInvalid},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			hh := *h
+			hh.memory = tt.memory
+			got, rest, err := parseHeader(append(hh.marshal(), "ciphertext"...))
+			if tt.err != nil {
+				assert.ErrorIs(t, err, tt.err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.memory, got.memory)
+			assert.Equal(t, []byte("ciphertext"), rest)
+		})
+	}
+}
+
+func TestDecryptLegacy(t *testing.T) {
+	t.Parallel()
+	b := []byte("Lorem ipsum dolor sit amet")
+	c := legacyEncrypt(t, b, "123456")
+	assert.True(t, isLegacy(c))
+	plaintext, err := decrypt(c, "123456")
+	assert.NoError(t, err)
+	assert.Equal(t, b, plaintext)
+	_, err = decrypt(c, "654321")
+	assert.ErrorIs(t, err, ErrDecryption)
+}
+
+func TestRekey(t *testing.T) {
+	t.Parallel()
+	pp := "123456"
+	b := []byte("Lorem ipsum dolor sit amet")
+	p := filepath.Join(t.TempDir(), "main.db.enc")
+	assert.NoError(t, os.WriteFile(p, legacyEncrypt(t, b, pp), files.FilePerm))
+
+	assert.ErrorIs(t, Rekey(p, "654321"), ErrDecryption)
+	assert.NoError(t, Rekey(p, pp))
+	c, err := os.ReadFile(p)
+	assert.NoError(t, err)
+	assert.False(t, isLegacy(c))
+	plaintext, err := decrypt(c, pp)
+	assert.NoError(t, err)
+	assert.Equal(t, b, plaintext)
+	assert.ErrorIs(t, Rekey(p, pp), ErrFileUpToDate)
+	assert.False(t, files.Exists(p+".tmp"))
+}
+
+func TestPasswd(t *testing.T) {
+	t.Parallel()
+	b := []byte("Lorem ipsum dolor sit amet")
+	dir := t.TempDir()
+	p := filepath.Join(dir, "main.db.enc")
+	c, err := encrypt(b, "old")
+	assert.NoError(t, err)
+	assert.NoError(t, os.WriteFile(p, c, files.FilePerm))
+
+	assert.ErrorIs(t, Passwd(p, "wrong", "new"), ErrDecryption)
+	assert.ErrorIs(t, Passwd(p, "old", ""), ErrPassphraseEmpty)
+	assert.NoError(t, Passwd(p, "old", "new"))
+	c, err = os.ReadFile(p)
+	assert.NoError(t, err)
+	_, err = decrypt(c, "old")
+	assert.ErrorIs(t, err, ErrDecryption)
+	plaintext, err := decrypt(c, "new")
+	assert.NoError(t, err)
+	assert.Equal(t, b, plaintext)
+	// only the encrypted file is left
+	fs, err := os.ReadDir(dir)
+	assert.NoError(t, err)
+	assert.Len(t, fs, 1)
+}
+
+func TestKey(t *testing.T) {
+	t.Parallel()
+	b := []byte("Lorem ipsum dolor sit amet")
+	k, err := NewKey("123456")
+	assert.NoError(t, err)
+	c, err := k.Encrypt(b)
+	assert.NoError(t, err)
+	id, err := KeyID(c)
+	assert.NoError(t, err)
+	assert.Equal(t, k.ID(), id)
+
+	// the key derived again decrypts, and keeps the header when encrypting
+	dk, err := DeriveKey(c, "123456")
+	assert.NoError(t, err)
+	c2, err := dk.Encrypt(b)
+	assert.NoError(t, err)
+	assert.Equal(t, c[:headerLen], c2[:headerLen])
+	plaintext, err := k.Decrypt(c2)
+	assert.NoError(t, err)
+	assert.Equal(t, b, plaintext)
+
+	// marshalled for the agent
+	raw, err := k.MarshalBinary()
+	assert.NoError(t, err)
+	var mk Key
+	assert.NoError(t, mk.UnmarshalBinary(raw))
+	plaintext, err = mk.Decrypt(c)
+	assert.NoError(t, err)
+	assert.Equal(t, b, plaintext)
+	assert.ErrorIs(t, mk.UnmarshalBinary(raw[1:]), ErrKeyInvalid)
+
+	// another file
+	other, err := encrypt(b, "123456")
+	assert.NoError(t, err)
+	_, err = k.Decrypt(other)
+	assert.ErrorIs(t, err, ErrKeyMismatch)
+	_, err = k.Decrypt(legacyEncrypt(t, b, "123456"))
+	assert.ErrorIs(t, err, ErrKeyMismatch)
+
+	k.Zero()
+	assert.Equal(t, make([]byte, argonKeyLen), k.secret)
+}
+
+func TestAgeKey(t *testing.T) {
+	t.Parallel()
+	b := []byte("Lorem ipsum dolor sit amet")
+	alice, err := age.GenerateX25519Identity()
+	assert.NoError(t, err)
+	bob, err := age.GenerateX25519Identity()
+	assert.NoError(t, err)
+	eve, err := age.GenerateX25519Identity()
+	assert.NoError(t, err)
+
+	rs, err := ParseRecipients([]string{alice.Recipient().String(), bob.Recipient().String()}, "")
+	assert.NoError(t, err)
+	c, err := NewAgeKey(nil, rs).Encrypt(b)
+	assert.NoError(t, err)
+	assert.True(t, IsAge(c))
+	id, err := KeyID(c)
+	assert.NoError(t, err)
+	assert.Empty(t, id)
+
+	// any of the recipients decrypts
+	for _, i := range []*age.X25519Identity{alice, bob} {
+		plaintext, err := NewAgeKey([]age.Identity{i}, nil).Decrypt(c)
+		assert.NoError(t, err)
+		assert.Equal(t, b, plaintext)
+	}
+	_, err = NewAgeKey([]age.Identity{eve}, nil).Decrypt(c)
+	assert.ErrorIs(t, err, ErrDecryption)
+	_, err = NewAgeKey(nil, rs).Decrypt(c)
+	assert.ErrorIs(t, err, ErrAgeNoIdentity)
+	_, err = NewAgeKey(nil, nil).Encrypt(b)
+	assert.ErrorIs(t, err, ErrAgeNoRecipients)
+
+	// passphrase keys and age keys don't mix
+	k, err := NewKey("123456")
+	assert.NoError(t, err)
+	_, err = k.Decrypt(c)
+	assert.ErrorIs(t, err, ErrKeyMismatch)
+	_, err = DeriveKey(c, "123456")
+	assert.ErrorIs(t, err, ErrAgeNoIdentity)
+	pc, err := k.Encrypt(b)
+	assert.NoError(t, err)
+	_, err = NewAgeKey([]age.Identity{alice}, nil).Decrypt(pc)
+	assert.ErrorIs(t, err, ErrKeyMismatch)
+}
+
+func TestAgeFiles(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	alice, err := age.GenerateX25519Identity()
+	assert.NoError(t, err)
+	bob, err := age.GenerateX25519Identity()
+	assert.NoError(t, err)
+
+	rf := filepath.Join(dir, "recipients.txt")
+	content := "# team\n" + alice.Recipient().String() + "\n\n" + bob.Recipient().String() + "\n"
+	assert.NoError(t, os.WriteFile(rf, []byte(content), files.FilePerm))
+	rs, err := ParseRecipients(nil, rf)
+	assert.NoError(t, err)
+	assert.Len(t, rs, 2)
+	_, err = ParseRecipients([]string{"age1invalid"}, "")
+	assert.ErrorIs(t, err, ErrAgeRecipient)
+
+	// lock to the recipients file, unlock with an identity file
+	db := filepath.Join(dir, "test.db")
+	assert.NoError(t, os.WriteFile(db, []byte("data"), files.FilePerm))
+	assert.NoError(t, LockKey(db, NewAgeKey(nil, rs)))
+	// the recipients are recorded next to the file
+	assert.NoError(t, WriteRecipients(db+".enc", rs))
+	recorded, err := ReadRecipients(db + ".enc")
+	assert.NoError(t, err)
+	assert.Equal(t, rs, recorded)
+	idf := filepath.Join(dir, "key.txt")
+	assert.NoError(t, os.WriteFile(idf, []byte(bob.String()+"\n"), files.FilePerm))
+	ids, err := ParseIdentities(idf)
+	assert.NoError(t, err)
+	assert.False(t, NewAgeKey(ids, nil).CanEncrypt())
+	assert.NoError(t, UnlockKey(db+".enc", NewAgeKey(ids, nil)))
+	got, err := os.ReadFile(db)
+	assert.NoError(t, err)
+	assert.Equal(t, "data", string(got))
+	assert.NoFileExists(t, RecipientsPath(db+".enc"))
+
+	_, err = ParseIdentities("")
+	assert.ErrorIs(t, err, ErrAgeNoIdentity)
+	_, err = ParseIdentities(rf)
+	assert.ErrorIs(t, err, ErrAgeIdentity)
+}
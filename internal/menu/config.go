@@ -52,6 +52,13 @@ func (c *Config) Validate() error {
 		c.Keymaps.Yank,
 		c.Keymaps.Preview,
 		c.Keymaps.ToggleAll,
+		c.Keymaps.Read,
+		c.Keymaps.Archive,
+		c.Keymaps.Snooze,
+		c.Keymaps.MoveUp,
+		c.Keymaps.MoveDown,
+		c.Keymaps.AddTag,
+		c.Keymaps.RemoveTag,
 	}
 
 	for _, k := range keymaps {
@@ -107,6 +114,13 @@ type Keymaps struct {
 	OpenQR    Keymap `yaml:"open_qr"`
 	ToggleAll Keymap `yaml:"toggle_all"`
 	Yank      Keymap `yaml:"yank"`
+	Read      Keymap `yaml:"read"`       // inbox: mark as read
+	Archive   Keymap `yaml:"archive"`    // inbox: mark as archived
+	Snooze    Keymap `yaml:"snooze"`     // inbox: snooze
+	MoveUp    Keymap `yaml:"move_up"`    // collection: move up
+	MoveDown  Keymap `yaml:"move_down"`  // collection: move down
+	AddTag    Keymap `yaml:"add_tag"`    // batch: add tags to the selection
+	RemoveTag Keymap `yaml:"remove_tag"` // batch: remove tags from the selection
 }
 
 // SetConfig sets menu configuration.
@@ -0,0 +1,216 @@
+package repo
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/haaag/gm/internal/config"
+	"github.com/haaag/gm/internal/locker"
+	"github.com/haaag/gm/internal/sys/files"
+)
+
+// Retention is a backups retention policy. A backup is kept if any rule
+// keeps it.
+type Retention struct {
+	Last    int // newest backups
+	Daily   int // days with their newest backup
+	Weekly  int // weeks with their newest backup
+	Monthly int // months with their newest backup
+}
+
+// RetentionFromConfig returns the retention policy of the configuration.
+func RetentionFromConfig() Retention {
+	c := config.Backup
+	return Retention{Last: c.KeepLast, Daily: c.KeepDaily, Weekly: c.KeepWeekly, Monthly: c.KeepMonthly}
+}
+
+// Enabled reports whether the policy removes any backup.
+func (p Retention) Enabled() bool {
+	return p.Last > 0 || p.Daily > 0 || p.Weekly > 0 || p.Monthly > 0
+}
+
+// Expired returns the backups the policy does not keep. Backups without a
+// timestamp in their name are always kept.
+func (p Retention) Expired(paths []string) []string {
+	if !p.Enabled() {
+		return nil
+	}
+	type backup struct {
+		path string
+		t    time.Time
+	}
+	bs := make([]backup, 0, len(paths))
+	for _, s := range paths {
+		if t, ok := backupTime(s); ok {
+			bs = append(bs, backup{s, t})
+		}
+	}
+	// newest first
+	slices.SortFunc(bs, func(a, b backup) int { return b.t.Compare(a.t) })
+
+	rules := []struct {
+		n   int
+		key func(b backup) string
+	}{
+		{p.Last, func(b backup) string { return b.path }},
+		{p.Daily, func(b backup) string { return b.t.Format(time.DateOnly) }},
+		{p.Weekly, func(b backup) string {
+			y, w := b.t.ISOWeek()
+			return fmt.Sprintf("%d-%02d", y, w)
+		}},
+		{p.Monthly, func(b backup) string { return b.t.Format("2006-01") }},
+	}
+	keep := make(map[string]bool, len(bs))
+	for _, r := range rules {
+		seen := make(map[string]bool, r.n)
+		for _, b := range bs {
+			if len(seen) >= r.n {
+				break
+			}
+			k := r.key(b)
+			if seen[k] {
+				continue
+			}
+			seen[k] = true
+			keep[b.path] = true
+		}
+	}
+	var expired []string
+	for _, b := range bs {
+		if !keep[b.path] {
+			expired = append(expired, b.path)
+		}
+	}
+
+	return expired
+}
+
+// backupTime returns the time of the backup from its name, like
+// '20060102-150405_bookmarks.db'.
+func backupTime(p string) (time.Time, bool) {
+	ts, _, ok := strings.Cut(filepath.Base(p), "_")
+	if !ok {
+		return time.Time{}, false
+	}
+	t, err := time.ParseInLocation(defaultDateFormat, ts, time.Local)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	return t, true
+}
+
+// backupSuffixes are the extensions of the backups of a database, plain,
+// compressed and locked.
+var backupSuffixes = []string{
+	".db", ".db.gz", ".db.zst",
+	".db.enc", ".db.gz.enc", ".db.zst.enc",
+}
+
+// isBackupOf reports whether the file at p is a backup of the database with
+// the given name, without extension, named <timestamp>_<name><suffix>.
+func isBackupOf(p, name string) bool {
+	if _, ok := backupTime(p); !ok {
+		return false
+	}
+	_, rest, _ := strings.Cut(filepath.Base(p), "_")
+	suffix, ok := strings.CutPrefix(rest, name)
+
+	return ok && slices.Contains(backupSuffixes, suffix)
+}
+
+// Prune removes the backups of the database the policy does not keep,
+// returning their paths. With dryRun nothing is removed.
+func (r *SQLiteRepository) Prune(p Retention, dryRun bool) ([]string, error) {
+	fs, err := r.BackupsList()
+	if err != nil {
+		return nil, err
+	}
+	expired := p.Expired(fs)
+	if dryRun {
+		return expired, nil
+	}
+	for _, s := range expired {
+		if err := files.Remove(s); err != nil {
+			return nil, fmt.Errorf("%w", err)
+		}
+		// the recipients recorded for locked backups.
+		_ = os.Remove(locker.RecipientsPath(s))
+		slog.Info("backup pruned", "path", s)
+	}
+	if len(expired) > 0 {
+		// drops the entries of the removed backups.
+		if err := updateManifest(r.Cfg.BackupDir, func(*Manifest) {}); err != nil {
+			slog.Warn("updating manifest", "error", err)
+		}
+	}
+
+	return expired, nil
+}
+
+// lastBackupTime returns the time of the newest backup of the database.
+func (r *SQLiteRepository) lastBackupTime() (time.Time, bool) {
+	fs, err := r.BackupsList()
+	if err != nil {
+		return time.Time{}, false
+	}
+	_, last := newestBackup(fs)
+
+	return last, !last.IsZero()
+}
+
+// newestBackup returns the backup with the newest timestamp in its name.
+func newestBackup(paths []string) (string, time.Time) {
+	var newest string
+	var last time.Time
+	for _, s := range paths {
+		if t, ok := backupTime(s); ok && t.After(last) {
+			newest, last = s, t
+		}
+	}
+
+	return newest, last
+}
+
+// LastBackup returns the newest backup of the database, by its timestamp.
+func (r *SQLiteRepository) LastBackup() (string, error) {
+	fs, err := r.BackupsList()
+	if err != nil {
+		return "", err
+	}
+	p, _ := newestBackup(fs)
+	if p == "" {
+		return "", fmt.Errorf("%w: %q", ErrBackupNotFound, r.Name())
+	}
+
+	return p, nil
+}
+
+// snapshot backs up the database before a destructive operation, unless
+// another backup was created within the configured interval.
+//
+// A failed snapshot is logged, the operation goes on. Backups opened as a
+// database are not backed up.
+func (r *SQLiteRepository) snapshot(op string) {
+	if !config.Backup.Auto || filepath.Base(r.Cfg.Path) == "backup" {
+		return
+	}
+	if r.mem == nil && !r.Cfg.Exists() || r.mem != nil && r.mem.readOnly {
+		return
+	}
+	if t, ok := r.lastBackupTime(); ok && time.Since(t) < config.Backup.Interval {
+		slog.Debug("snapshot skipped, recent backup", "op", op, "last", t)
+		return
+	}
+	p, err := r.Backup()
+	if err != nil {
+		slog.Warn("snapshot failed", "op", op, "error", err)
+		return
+	}
+	slog.Info("snapshot created", "op", op, "backup", p)
+}
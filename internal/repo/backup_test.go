@@ -0,0 +1,197 @@
+package repo
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/haaag/gm/internal/config"
+	"github.com/haaag/gm/internal/sys/files"
+)
+
+// testBackupPaths returns backup paths at the given times.
+func testBackupPaths(ts ...time.Time) []string {
+	paths := make([]string, 0, len(ts))
+	for _, t := range ts {
+		paths = append(paths, filepath.Join("backup", t.Format(defaultDateFormat)+"_bookmarks.db"))
+	}
+
+	return paths
+}
+
+func TestRetentionExpired(t *testing.T) {
+	t.Parallel()
+	day := func(d, h int) time.Time {
+		return time.Date(2024, time.January, d, h, 0, 0, 0, time.Local)
+	}
+	// two backups a day, from the 1st to the 31st of January.
+	var ts []time.Time
+	for d := 1; d <= 31; d++ {
+		ts = append(ts, day(d, 9), day(d, 18))
+	}
+	paths := testBackupPaths(ts...)
+
+	tests := []struct {
+		name string
+		p    Retention
+		keep int
+	}{
+		{name: "disabled", p: Retention{}, keep: len(paths)},
+		{name: "last", p: Retention{Last: 3}, keep: 3},
+		{name: "daily", p: Retention{Daily: 7}, keep: 7},
+		{name: "last and daily overlap", p: Retention{Last: 2, Daily: 3}, keep: 4},
+		{name: "weekly", p: Retention{Weekly: 2}, keep: 2},
+		{name: "monthly", p: Retention{Monthly: 6}, keep: 1},
+		{name: "more than backups", p: Retention{Last: 100}, keep: len(paths)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			expired := tt.p.Expired(paths)
+			assert.Len(t, expired, len(paths)-tt.keep)
+			if tt.p.Enabled() {
+				// the newest backup is always kept
+				assert.NotContains(t, expired, paths[len(paths)-1])
+			}
+		})
+	}
+
+	// daily keeps the newest backup of each day
+	expired := Retention{Daily: 1}.Expired(paths)
+	assert.Contains(t, expired, paths[len(paths)-2])
+	assert.NotContains(t, expired, paths[len(paths)-1])
+
+	// backups without a timestamp are kept
+	odd := append([]string{"backup/bookmarks.db"}, paths...)
+	assert.NotContains(t, Retention{Last: 1}.Expired(odd), "backup/bookmarks.db")
+}
+
+//nolint:paralleltest //modifies the backup config
+func TestSnapshot(t *testing.T) {
+	auto, interval := config.Backup.Auto, config.Backup.Interval
+	t.Cleanup(func() { config.Backup.Auto, config.Backup.Interval = auto, interval })
+	config.Backup.Auto, config.Backup.Interval = true, time.Hour
+
+	p := filepath.Join(t.TempDir(), "bookmarks.db")
+	r, err := Init(p)
+	assert.NoError(t, err)
+	assert.NoError(t, r.Init())
+	defer r.Close()
+	ctx := context.Background()
+	assert.NoError(t, r.InsertMany(ctx, testSliceBookmarks(5)))
+	fs, err := r.BackupsList()
+	assert.NoError(t, err)
+	assert.Len(t, fs, 1, "snapshot before import")
+
+	// rate limited within the interval
+	assert.NoError(t, r.ReorderIDs(ctx))
+	fs, err = r.BackupsList()
+	assert.NoError(t, err)
+	assert.Len(t, fs, 1)
+
+	// an older backup does not limit the next snapshot
+	old := filepath.Join(r.Cfg.BackupDir, time.Now().Add(-2*time.Hour).Format(defaultDateFormat)+"_bookmarks.db")
+	assert.NoError(t, os.Rename(fs[0], old))
+	assert.NoError(t, Drop(r, ctx))
+	fs, err = r.BackupsList()
+	assert.NoError(t, err)
+	assert.Len(t, fs, 2, "snapshot before drop")
+
+	// disabled
+	config.Backup.Auto = false
+	assert.NoError(t, files.Remove(fs[1]))
+	assert.NoError(t, os.Rename(fs[0], old))
+	assert.NoError(t, Drop(r, ctx))
+	fs, err = r.BackupsList()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{old}, fs)
+}
+
+//nolint:paralleltest //modifies the backup config
+func TestPrune(t *testing.T) {
+	auto := config.Backup.Auto
+	t.Cleanup(func() { config.Backup.Auto = auto })
+	config.Backup.Auto = false
+
+	p := filepath.Join(t.TempDir(), "bookmarks.db")
+	r, err := Init(p)
+	assert.NoError(t, err)
+	assert.NoError(t, r.Init())
+	defer r.Close()
+	assert.NoError(t, files.MkdirAll(r.Cfg.BackupDir))
+	now := time.Now()
+	paths := testBackupPaths(now.Add(-3*time.Hour), now.Add(-2*time.Hour), now.Add(-time.Hour))
+	for i, s := range paths {
+		paths[i] = filepath.Join(filepath.Dir(p), s)
+		assert.NoError(t, os.WriteFile(paths[i], []byte("bk"), files.FilePerm))
+	}
+
+	// retention is opt-in, the default config keeps every backup
+	assert.False(t, RetentionFromConfig().Enabled())
+	_, err = r.Backup()
+	assert.NoError(t, err)
+	fs, err := r.BackupsList()
+	assert.NoError(t, err)
+	assert.Len(t, fs, 4)
+	assert.NoError(t, files.Remove(fs[3]))
+
+	expired, err := r.Prune(Retention{Last: 1}, true)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, paths[:2], expired)
+	fs, err = r.BackupsList()
+	assert.NoError(t, err)
+	assert.Len(t, fs, 3, "dry run removes nothing")
+
+	_, err = r.Prune(Retention{Last: 1}, false)
+	assert.NoError(t, err)
+	fs, err = r.BackupsList()
+	assert.NoError(t, err)
+	assert.Equal(t, paths[2:], fs)
+}
+
+//nolint:paralleltest //modifies the backup config
+func TestPruneSharedSuffix(t *testing.T) {
+	auto := config.Backup.Auto
+	t.Cleanup(func() { config.Backup.Auto = auto })
+	config.Backup.Auto = false
+
+	dir := t.TempDir()
+	r, err := Init(filepath.Join(dir, "bookmarks.db"))
+	assert.NoError(t, err)
+	assert.NoError(t, r.Init())
+	defer r.Close()
+	assert.NoError(t, files.MkdirAll(r.Cfg.BackupDir))
+	now := time.Now()
+	var mine, other []string
+	for _, d := range []time.Duration{3 * time.Hour, 2 * time.Hour, time.Hour} {
+		ts := now.Add(-d).Format(defaultDateFormat)
+		mine = append(mine, filepath.Join(r.Cfg.BackupDir, ts+"_bookmarks.db"))
+		other = append(other, filepath.Join(r.Cfg.BackupDir, ts+"_my_bookmarks.db"))
+	}
+	stray := []string{
+		filepath.Join(r.Cfg.BackupDir, now.Format(defaultDateFormat)+"_bookmarks.db.tmp"),
+		filepath.Join(r.Cfg.BackupDir, "bookmarks.db.backup_1"),
+	}
+	for _, p := range append(append(append([]string{}, mine...), other...), stray...) {
+		assert.NoError(t, os.WriteFile(p, []byte("bk"), files.FilePerm))
+	}
+
+	fs, err := r.BackupsList()
+	assert.NoError(t, err)
+	assert.Equal(t, mine, fs)
+	fs, err = ListDatabaseBackups(r.Cfg.BackupDir, "my_bookmarks.db")
+	assert.NoError(t, err)
+	assert.Equal(t, other, fs)
+
+	_, err = r.Prune(Retention{Last: 1}, false)
+	assert.NoError(t, err)
+	for _, p := range append(append([]string{mine[2]}, other...), stray...) {
+		assert.FileExists(t, p)
+	}
+	assert.NoFileExists(t, mine[0])
+	assert.NoFileExists(t, mine[1])
+}
@@ -0,0 +1,105 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Change is an update of a record, with its state before the update.
+type Change struct {
+	New *Row
+	Old *Row
+}
+
+// Changes holds the records to create, update and delete.
+type Changes struct {
+	Create []*Row
+	Update []Change
+	Delete []*Row
+}
+
+// Len returns the number of changes.
+func (c *Changes) Len() int {
+	return len(c.Create) + len(c.Update) + len(c.Delete)
+}
+
+// ApplyChanges creates, updates and deletes the records in a single
+// transaction, nothing is applied if any of them fails.
+func (r *SQLiteRepository) ApplyChanges(ctx context.Context, c *Changes) error {
+	now := time.Now().UTC().Format(time.RFC3339)
+
+	var olds, news []*Row
+	for _, u := range c.Update {
+		olds, news = append(olds, u.Old), append(news, u.New)
+	}
+	olds = append(olds, c.Delete...)
+
+	return r.withTx(ctx, func(tx *sqlx.Tx) error {
+		before, err := rowsTx(tx, urlsOf(olds...))
+		if err != nil {
+			return err
+		}
+		for _, u := range c.Update {
+			if err := r.updateTx(tx, u.New, u.Old, now); err != nil {
+				return err
+			}
+		}
+		for _, b := range c.Create {
+			if err := hasCanonicalTx(tx, b); err != nil {
+				return err
+			}
+			b.CreatedAt, b.UpdatedAt = now, now
+			if err := r.insertIntoTx(tx, b); err != nil {
+				return fmt.Errorf("%w: %q", err, b.URL)
+			}
+		}
+		for _, b := range c.Delete {
+			if _, err := tx.Exec("DELETE FROM bookmark_tags WHERE bookmark_url = ?", b.URL); err != nil {
+				return fmt.Errorf("deleting record: %w: %q", err, b.URL)
+			}
+			if _, err := tx.Exec("DELETE FROM bookmarks WHERE id = ?", b.ID); err != nil {
+				return fmt.Errorf("deleting record: %w: %q", err, b.URL)
+			}
+		}
+
+		if err := pruneOrphans(tx); err != nil {
+			return err
+		}
+		after, err := rowsTx(tx, urlsOf(append(news, c.Create...)...))
+		if err != nil {
+			return err
+		}
+
+		return record(ctx, tx, OpEdit, before, after)
+	})
+}
+
+// updateTx rewrites the record at its ID inside an existing transaction.
+func (r *SQLiteRepository) updateTx(tx *sqlx.Tx, newB, oldB *Row, now string) error {
+	// removing the tags relationships removes the record, see the cleanup
+	// trigger.
+	if _, err := tx.Exec("DELETE FROM bookmark_tags WHERE bookmark_url = ?", oldB.URL); err != nil {
+		return fmt.Errorf("updating record: %w: %q", err, oldB.URL)
+	}
+	if newB.URL != oldB.URL {
+		exists, err := r.hasTx(tx, newB.URL)
+		if err != nil {
+			return err
+		}
+		if exists {
+			return fmt.Errorf("%w: %q", ErrRecordDuplicate, newB.URL)
+		}
+	}
+	newB.UpdatedAt = now
+	if err := r.insertAtID(tx, newB); err != nil {
+		return fmt.Errorf("updating record: %w", err)
+	}
+	if newB.URL != oldB.URL {
+		return renameURL(tx, oldB.URL, newB.URL)
+	}
+
+	return nil
+}
@@ -0,0 +1,60 @@
+package repo
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/haaag/gm/internal/slice"
+)
+
+func TestApplyChanges(t *testing.T) {
+	r := setupTestDB(t)
+	defer teardownthewall(r.DB)
+	ctx := context.Background()
+	bs := testSliceBookmarks(3)
+	assert.NoError(t, r.InsertMany(ctx, bs))
+	all := slice.New[Row]()
+	assert.NoError(t, r.All(all))
+
+	old := all.Item(0)
+	updated := old
+	updated.URL = "https://example.com/updated"
+	updated.Title = "Updated"
+	deleted := all.Item(1)
+	created := testSingleBookmark()
+	created.URL = "https://example.com/created"
+	c := &Changes{
+		Create: []*Row{created},
+		Update: []Change{{New: &updated, Old: &old}},
+		Delete: []*Row{&deleted},
+	}
+	assert.Equal(t, 3, c.Len())
+	assert.NoError(t, r.ApplyChanges(ctx, c))
+
+	got, err := r.ByID(old.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "https://example.com/updated", got.URL)
+	assert.Equal(t, "Updated", got.Title)
+	_, exists := r.Has(deleted.URL)
+	assert.False(t, exists)
+	_, exists = r.Has(created.URL)
+	assert.True(t, exists)
+	assert.NotZero(t, created.ID)
+
+	// nothing is applied if a change fails
+	third := all.Item(2)
+	dup := third
+	dup.URL = updated.URL
+	dup.Title = "Conflict"
+	c = &Changes{
+		Update: []Change{{New: &dup, Old: &third}},
+		Delete: []*Row{got},
+	}
+	assert.ErrorIs(t, r.ApplyChanges(ctx, c), ErrRecordDuplicate)
+	_, exists = r.Has(got.URL)
+	assert.True(t, exists)
+	_, exists = r.Has(third.URL)
+	assert.True(t, exists)
+}
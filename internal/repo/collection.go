@@ -0,0 +1,255 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/haaag/gm/internal/bookmark"
+)
+
+// Collection is a named, ordered list of bookmarks.
+type Collection struct {
+	ID        int    `db:"id"         json:"id"`
+	Name      string `db:"name"       json:"name"`
+	Desc      string `db:"desc"       json:"desc"`
+	CreatedAt string `db:"created_at" json:"created_at"`
+	Count     int    `db:"count"      json:"count"`
+}
+
+// validateCollectionName checks the name is not empty and has no spaces, so
+// it can be used as a command argument.
+func validateCollectionName(name string) error {
+	if name == "" || strings.ContainsAny(name, " \t\n") {
+		return fmt.Errorf("%w: %q", ErrCollectionName, name)
+	}
+
+	return nil
+}
+
+// NewCollection creates a new empty collection.
+func (r *SQLiteRepository) NewCollection(ctx context.Context, name, desc string) (*Collection, error) {
+	if err := validateCollectionName(name); err != nil {
+		return nil, err
+	}
+	if _, err := r.Collection(name); err == nil {
+		return nil, fmt.Errorf("%w: %q", ErrCollectionExists, name)
+	}
+	if err := r.withTx(ctx, func(tx *sqlx.Tx) error {
+		_, err := tx.ExecContext(ctx, "INSERT INTO collections (name, desc) VALUES (?, ?)", name, desc)
+		return err
+	}); err != nil {
+		return nil, fmt.Errorf("creating collection: %w", err)
+	}
+	slog.Info("collection created", "name", name)
+
+	return r.Collection(name)
+}
+
+// Collection returns the collection by name.
+func (r *SQLiteRepository) Collection(name string) (*Collection, error) {
+	q := `
+    SELECT
+      c.*,
+      COUNT(i.bookmark_url) AS count
+    FROM
+      collections c
+      LEFT JOIN collection_items i ON c.id = i.collection_id
+    WHERE
+      c.name = ?
+    GROUP BY
+      c.id;`
+	var c Collection
+	if err := r.DB.Get(&c, q, name); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w: %q", ErrCollectionNotFound, name)
+		}
+
+		return nil, fmt.Errorf("%w", err)
+	}
+
+	return &c, nil
+}
+
+// Collections returns all the collections, sorted by name.
+func (r *SQLiteRepository) Collections() ([]Collection, error) {
+	q := `
+    SELECT
+      c.*,
+      COUNT(i.bookmark_url) AS count
+    FROM
+      collections c
+      LEFT JOIN collection_items i ON c.id = i.collection_id
+    GROUP BY
+      c.id
+    ORDER BY
+      c.name ASC;`
+	var cs []Collection
+	if err := r.DB.Select(&cs, q); err != nil {
+		return nil, fmt.Errorf("%w", err)
+	}
+
+	return cs, nil
+}
+
+// RemoveCollection removes the collection, the bookmarks are kept.
+func (r *SQLiteRepository) RemoveCollection(ctx context.Context, c *Collection) error {
+	return r.withTx(ctx, func(tx *sqlx.Tx) error {
+		if _, err := tx.ExecContext(ctx, "DELETE FROM collection_items WHERE collection_id = ?", c.ID); err != nil {
+			return fmt.Errorf("removing collection items: %w", err)
+		}
+		if _, err := tx.ExecContext(ctx, "DELETE FROM collections WHERE id = ?", c.ID); err != nil {
+			return fmt.Errorf("removing collection: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// ByCollection returns the records of the collection, in order.
+func (r *SQLiteRepository) ByCollection(c *Collection, bs *Slice) error {
+	q := `
+    SELECT
+      b.*,
+      COALESCE(GROUP_CONCAT(t.name, ','), '') AS tags
+    FROM
+      collection_items i
+      JOIN bookmarks b ON b.url = i.bookmark_url
+      LEFT JOIN bookmark_tags bt ON b.url = bt.bookmark_url
+      LEFT JOIN tags t ON bt.tag_id = t.id
+    WHERE
+      i.collection_id = ?
+    GROUP BY
+      b.id
+    ORDER BY
+      i.position ASC;`
+	var bb []Row
+	if err := r.DB.Select(&bb, q, c.ID); err != nil {
+		return fmt.Errorf("%w", err)
+	}
+	for i := range bb {
+		bb[i].Tags = bookmark.ParseTags(bb[i].Tags)
+	}
+	bs.Set(&bb)
+
+	return nil
+}
+
+// AddToCollection appends the records to the end of the collection,
+// skipping the ones already in it.
+func (r *SQLiteRepository) AddToCollection(ctx context.Context, c *Collection, bs *Slice) error {
+	urls, err := r.collectionURLs(c)
+	if err != nil {
+		return err
+	}
+	seen := make(map[string]bool, len(urls))
+	for _, u := range urls {
+		seen[u] = true
+	}
+	bs.ForEach(func(b Row) {
+		if !seen[b.URL] {
+			seen[b.URL] = true
+			urls = append(urls, b.URL)
+		}
+	})
+
+	return r.setCollectionItems(ctx, c, urls)
+}
+
+// RemoveFromCollection removes the records from the collection.
+func (r *SQLiteRepository) RemoveFromCollection(ctx context.Context, c *Collection, bs *Slice) error {
+	urls, err := r.collectionURLs(c)
+	if err != nil {
+		return err
+	}
+	rm := make(map[string]bool, bs.Len())
+	bs.ForEach(func(b Row) {
+		rm[b.URL] = true
+	})
+	keep := make([]string, 0, len(urls))
+	for _, u := range urls {
+		if !rm[u] {
+			keep = append(keep, u)
+		}
+	}
+
+	return r.setCollectionItems(ctx, c, keep)
+}
+
+// MoveInCollection moves the record to the given position, starting at 1,
+// shifting the records in between.
+func (r *SQLiteRepository) MoveInCollection(ctx context.Context, c *Collection, b *Row, pos int) error {
+	urls, err := r.collectionURLs(c)
+	if err != nil {
+		return err
+	}
+	if pos < 1 || pos > len(urls) {
+		return fmt.Errorf("%w: %d (1-%d)", ErrCollectionPosition, pos, len(urls))
+	}
+	from := -1
+	for i, u := range urls {
+		if u == b.URL {
+			from = i
+			break
+		}
+	}
+	if from == -1 {
+		return fmt.Errorf("%w: %q not in %q", ErrRecordNotFound, b.URL, c.Name)
+	}
+	urls = append(urls[:from], urls[from+1:]...)
+	to := pos - 1
+	urls = append(urls[:to], append([]string{b.URL}, urls[to:]...)...)
+
+	return r.setCollectionItems(ctx, c, urls)
+}
+
+// CollectionPosition returns the position of the record in the collection,
+// starting at 1.
+func (r *SQLiteRepository) CollectionPosition(c *Collection, b *Row) (int, error) {
+	urls, err := r.collectionURLs(c)
+	if err != nil {
+		return 0, err
+	}
+	for i, u := range urls {
+		if u == b.URL {
+			return i + 1, nil
+		}
+	}
+
+	return 0, fmt.Errorf("%w: %q not in %q", ErrRecordNotFound, b.URL, c.Name)
+}
+
+// collectionURLs returns the URLs of the collection items, in order.
+func (r *SQLiteRepository) collectionURLs(c *Collection) ([]string, error) {
+	var urls []string
+	q := "SELECT bookmark_url FROM collection_items WHERE collection_id = ? ORDER BY position ASC"
+	if err := r.DB.Select(&urls, q, c.ID); err != nil {
+		return nil, fmt.Errorf("%w", err)
+	}
+
+	return urls, nil
+}
+
+// setCollectionItems replaces the items of the collection, numbering the
+// positions from 1.
+func (r *SQLiteRepository) setCollectionItems(ctx context.Context, c *Collection, urls []string) error {
+	return r.withTx(ctx, func(tx *sqlx.Tx) error {
+		if _, err := tx.ExecContext(ctx, "DELETE FROM collection_items WHERE collection_id = ?", c.ID); err != nil {
+			return fmt.Errorf("clearing collection items: %w", err)
+		}
+		q := "INSERT INTO collection_items (collection_id, bookmark_url, position) VALUES (?, ?, ?)"
+		for i, u := range urls {
+			if _, err := tx.ExecContext(ctx, q, c.ID, u, i+1); err != nil {
+				return fmt.Errorf("inserting collection item: %w: %q", err, u)
+			}
+		}
+		c.Count = len(urls)
+
+		return nil
+	})
+}
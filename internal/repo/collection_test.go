@@ -0,0 +1,79 @@
+package repo
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/haaag/gm/internal/slice"
+)
+
+func collectionURLsOf(bs *Slice) []string {
+	urls := make([]string, 0, bs.Len())
+	bs.ForEach(func(b Row) {
+		urls = append(urls, b.URL)
+	})
+
+	return urls
+}
+
+func TestCollection(t *testing.T) {
+	r := setupTestDB(t)
+	defer teardownthewall(r.DB)
+	ctx := context.Background()
+	bs := testSliceBookmarks(4)
+	assert.NoError(t, r.InsertMany(ctx, bs))
+
+	_, err := r.NewCollection(ctx, "two words", "")
+	assert.ErrorIs(t, err, ErrCollectionName)
+	c, err := r.NewCollection(ctx, "onboarding", "first week")
+	assert.NoError(t, err)
+	_, err = r.NewCollection(ctx, "onboarding", "")
+	assert.ErrorIs(t, err, ErrCollectionExists)
+
+	// added in the given order, skipping duplicates
+	items := slice.New[Row]()
+	items.Append(bs.Item(2), bs.Item(0), bs.Item(3))
+	assert.NoError(t, r.AddToCollection(ctx, c, items))
+	assert.NoError(t, r.AddToCollection(ctx, c, items))
+	assert.Equal(t, 3, c.Count)
+
+	got := slice.New[Row]()
+	assert.NoError(t, r.ByCollection(c, got))
+	want := []string{bs.Item(2).URL, bs.Item(0).URL, bs.Item(3).URL}
+	assert.Equal(t, want, collectionURLsOf(got))
+
+	// move the last item to the top
+	last := bs.Item(3)
+	assert.NoError(t, r.MoveInCollection(ctx, c, &last, 1))
+	assert.ErrorIs(t, r.MoveInCollection(ctx, c, &last, 4), ErrCollectionPosition)
+	pos, err := r.CollectionPosition(c, &last)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, pos)
+
+	// removed bookmarks leave the collection
+	rm := slice.New[Row]()
+	rm.Append(bs.Item(2))
+	assert.NoError(t, r.DeleteMany(ctx, rm))
+	assert.NoError(t, r.ByCollection(c, got))
+	assert.Equal(t, []string{bs.Item(3).URL, bs.Item(0).URL}, collectionURLsOf(got))
+
+	// items follow the bookmark when it is updated
+	old := bs.Item(0)
+	updated := old
+	updated.URL = "https://example.com/updated"
+	_, err = r.UpdateOne(ctx, &updated, &old)
+	assert.NoError(t, err)
+	assert.NoError(t, r.ByCollection(c, got))
+	assert.Equal(t, []string{bs.Item(3).URL, updated.URL}, collectionURLsOf(got))
+
+	cs, err := r.Collections()
+	assert.NoError(t, err)
+	assert.Len(t, cs, 1)
+	assert.Equal(t, 2, cs[0].Count)
+
+	assert.NoError(t, r.RemoveCollection(ctx, c))
+	_, err = r.Collection("onboarding")
+	assert.ErrorIs(t, err, ErrCollectionNotFound)
+}
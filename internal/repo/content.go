@@ -0,0 +1,126 @@
+package repo
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// snippet tokens surrounding the match.
+const snippetTokens = 12
+
+// ContentMatch represents a bookmark whose page content matches a query.
+type ContentMatch struct {
+	URL     string `db:"bookmark_url"`
+	Snippet string `db:"snippet"`
+}
+
+// contentChecksum returns the checksum of the page text.
+func contentChecksum(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+// IndexContent indexes the readable text of the bookmarked page.
+//
+// Returns false if the text is unchanged since the last indexing.
+func (r *SQLiteRepository) IndexContent(ctx context.Context, bURL, text string) (bool, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return false, nil
+	}
+	checksum := contentChecksum(text)
+	indexed := false
+	err := r.withTx(ctx, func(tx *sqlx.Tx) error {
+		var current string
+		err := tx.Get(&current, "SELECT checksum FROM bookmark_content WHERE bookmark_url = ?", bURL)
+		if err == nil && current == checksum {
+			slog.Debug("content unchanged, skipping", "url", bURL)
+			return nil
+		}
+		if _, err := tx.Exec("DELETE FROM bookmark_content WHERE bookmark_url = ?", bURL); err != nil {
+			return fmt.Errorf("removing old content: %w", err)
+		}
+		_, err = tx.Exec(
+			"INSERT INTO bookmark_content (bookmark_url, checksum, content) VALUES (?, ?, ?)",
+			bURL, checksum, text,
+		)
+		if err != nil {
+			return fmt.Errorf("indexing content: %w", err)
+		}
+		indexed = true
+
+		return nil
+	})
+	if err != nil {
+		return false, fmt.Errorf("%w", err)
+	}
+	slog.Debug("content indexed", "url", bURL, "indexed", indexed)
+
+	return indexed, nil
+}
+
+// HasContent checks if the bookmarked page content is indexed.
+func (r *SQLiteRepository) HasContent(bURL string) bool {
+	var n int
+	if err := r.DB.Get(&n, "SELECT COUNT(*) FROM bookmark_content WHERE bookmark_url = ?", bURL); err != nil {
+		slog.Error("checking content", "url", bURL, "error", err)
+		return false
+	}
+
+	return n > 0
+}
+
+// ContentMatches returns the bookmarks whose page content matches the
+// full-text query, with a snippet of the matching text.
+func (r *SQLiteRepository) ContentMatches(query string) ([]ContentMatch, error) {
+	q := fmt.Sprintf(`
+    SELECT
+      c.bookmark_url,
+      snippet(bookmark_content, '[', ']', '...', 2, %d) AS snippet
+    FROM bookmark_content c
+    JOIN bookmarks b ON b.url = c.bookmark_url
+    WHERE bookmark_content MATCH ?
+    ORDER BY b.id ASC;`, snippetTokens)
+	var matches []ContentMatch
+	if err := r.DB.Select(&matches, q, query); err != nil {
+		return nil, fmt.Errorf("content match: %w", err)
+	}
+	slog.Debug("content matches", "query", query, "count", len(matches))
+
+	return matches, nil
+}
+
+// renameURL links the content of the old URL to the new one.
+func renameURL(tx *sqlx.Tx, oldURL, newURL string) error {
+	if _, err := tx.Exec("UPDATE bookmark_content SET bookmark_url = ? WHERE bookmark_url = ?", newURL, oldURL); err != nil {
+		return fmt.Errorf("renaming content url: %w", err)
+	}
+	if _, err := tx.Exec("UPDATE collection_items SET bookmark_url = ? WHERE bookmark_url = ?", newURL, oldURL); err != nil {
+		return fmt.Errorf("renaming collection item url: %w", err)
+	}
+	if _, err := tx.Exec("UPDATE history SET bookmark_url = ? WHERE bookmark_url = ?", newURL, oldURL); err != nil {
+		return fmt.Errorf("renaming history url: %w", err)
+	}
+
+	return nil
+}
+
+// pruneOrphans removes the rows linked to bookmarks that no longer exist.
+func pruneOrphans(tx *sqlx.Tx) error {
+	q := "DELETE FROM bookmark_content WHERE bookmark_url NOT IN (SELECT url FROM bookmarks)"
+	if _, err := tx.Exec(q); err != nil {
+		return fmt.Errorf("pruning content: %w", err)
+	}
+	q = "DELETE FROM collection_items WHERE bookmark_url NOT IN (SELECT url FROM bookmarks)"
+	if _, err := tx.Exec(q); err != nil {
+		return fmt.Errorf("pruning collection items: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,53 @@
+package repo
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/haaag/gm/internal/slice"
+)
+
+func TestIndexContent(t *testing.T) {
+	r := setupTestDB(t)
+	defer teardownthewall(r.DB)
+	ctx := context.Background()
+	b := testSingleBookmark()
+	assert.NoError(t, r.InsertOne(ctx, b))
+
+	text := "Go is an open source programming language that makes it simple to build software."
+	indexed, err := r.IndexContent(ctx, b.URL, text)
+	assert.NoError(t, err)
+	assert.True(t, indexed)
+	assert.True(t, r.HasContent(b.URL))
+
+	// unchanged content is skipped
+	indexed, err = r.IndexContent(ctx, b.URL, text)
+	assert.NoError(t, err)
+	assert.False(t, indexed)
+
+	matches, err := r.ContentMatches("programming")
+	assert.NoError(t, err)
+	assert.Len(t, matches, 1)
+	assert.Equal(t, b.URL, matches[0].URL)
+	assert.Contains(t, matches[0].Snippet, "[programming]")
+
+	matches, err = r.ContentMatches("rust")
+	assert.NoError(t, err)
+	assert.Empty(t, matches)
+
+	// content follows the URL
+	newB := *b
+	newB.URL = "https://www.example.org"
+	_, err = r.UpdateOne(ctx, &newB, b)
+	assert.NoError(t, err)
+	assert.False(t, r.HasContent(b.URL))
+	assert.True(t, r.HasContent(newB.URL))
+
+	// content is removed with the record
+	bs := slice.New[Row]()
+	bs.Push(&newB)
+	assert.NoError(t, r.DeleteMany(ctx, bs))
+	assert.False(t, r.HasContent(newB.URL))
+}
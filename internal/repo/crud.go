@@ -21,14 +21,29 @@ type (
 )
 
 // InsertOne creates a new record in the main table.
+//
+// A record whose URL or canonical URL matches an existing record's URL or
+// canonical URL is considered a duplicate.
 func (r *SQLiteRepository) InsertOne(ctx context.Context, b *Row) error {
 	return r.withTx(ctx, func(tx *sqlx.Tx) error {
-		return r.insertIntoTx(tx, b)
+		if err := hasCanonicalTx(tx, b); err != nil {
+			return err
+		}
+		if err := r.insertIntoTx(tx, b); err != nil {
+			return err
+		}
+		after, err := rowsTx(tx, urlsOf(b))
+		if err != nil {
+			return err
+		}
+
+		return record(ctx, tx, OpAdd, nil, after)
 	})
 }
 
 // InsertMany creates multiple records.
 func (r *SQLiteRepository) InsertMany(ctx context.Context, bs *Slice) error {
+	r.snapshot("import")
 	return r.insertBulk(ctx, bs)
 }
 
@@ -43,12 +58,19 @@ func (r *SQLiteRepository) DeleteMany(ctx context.Context, bs *Slice) error {
 		return ErrRecordIDNotProvided
 	}
 	slog.Debug("deleting many records from the relation table", "count", bs.Len())
+	if bs.Len() > 1 {
+		r.snapshot("remove")
+	}
 	var urls []string
 	bs.ForEach(func(b Row) {
 		urls = append(urls, b.URL)
 	})
 
 	return r.withTx(ctx, func(tx *sqlx.Tx) error {
+		before, err := rowsTx(tx, urls)
+		if err != nil {
+			return err
+		}
 		// create query
 		q, args, err := sqlx.In("DELETE FROM bookmark_tags WHERE bookmark_url IN (?)", urls)
 		if err != nil {
@@ -72,23 +94,41 @@ func (r *SQLiteRepository) DeleteMany(ctx context.Context, bs *Slice) error {
 		if err := stmt.Close(); err != nil {
 			return fmt.Errorf("delete many: %w: closing stmt", err)
 		}
+		if err := pruneOrphans(tx); err != nil {
+			return err
+		}
 
-		return nil
+		return record(ctx, tx, OpRemove, before, nil)
 	})
 }
 
 // UpdateOne updates an existing record in the relation table.
 func (r *SQLiteRepository) UpdateOne(ctx context.Context, newB, oldB *Row) (*Row, error) {
 	if err := r.withTx(ctx, func(tx *sqlx.Tx) error {
-		if err := r.delete(ctx, oldB.URL); err != nil {
+		before, err := rowsTx(tx, urlsOf(oldB))
+		if err != nil {
+			return err
+		}
+		// removing the tags relationships removes the record, see the cleanup
+		// trigger.
+		if _, err := tx.Exec("DELETE FROM bookmark_tags WHERE bookmark_url = ?", oldB.URL); err != nil {
 			return fmt.Errorf("delete old record: %w", err)
 		}
 		newB.UpdatedAt = time.Now().UTC().Format(time.RFC3339)
 		if err := r.insertAtID(tx, newB); err != nil {
 			return fmt.Errorf("insert new record: %w", err)
 		}
+		if oldB.URL != newB.URL {
+			if err := renameURL(tx, oldB.URL, newB.URL); err != nil {
+				return err
+			}
+		}
+		after, err := rowsTx(tx, urlsOf(newB))
+		if err != nil {
+			return err
+		}
 
-		return nil
+		return record(ctx, tx, OpUpdate, before, after)
 	}); err != nil {
 		return nil, fmt.Errorf("%w", err)
 	}
@@ -242,7 +282,7 @@ func (r *SQLiteRepository) ByQuery(query string, bs *Slice) error {
     LEFT JOIN bookmark_tags bt ON b.url = bt.bookmark_url
     LEFT JOIN tags t ON bt.tag_id = t.id
     WHERE
-        (LOWER(b.id || b.title || b.url || b.desc) LIKE LOWER(?) OR
+        (LOWER(b.id || b.title || b.url || b.desc || b.notes) LIKE LOWER(?) OR
         LOWER(t.name) LIKE LOWER(?))
       GROUP BY b.id
       ORDER BY b.id ASC;`
@@ -279,8 +319,28 @@ func (r *SQLiteRepository) Has(bURL string) (*Row, bool) {
 	return item, true
 }
 
+// HasCanonical checks if a record with the given URL as its URL or its
+// canonical URL exists in the main table.
+func (r *SQLiteRepository) HasCanonical(bURL string) (*Row, bool) {
+	if bURL == "" {
+		return nil, false
+	}
+	var u string
+	q := "SELECT url FROM bookmarks WHERE url = ? OR (canonical_url != '' AND canonical_url = ?) LIMIT 1"
+	if err := r.DB.Get(&u, q, bURL, bURL); err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			slog.Error("error getting canonical url", "url", bURL, "error", err)
+		}
+
+		return nil, false
+	}
+
+	return r.Has(u)
+}
+
 // ReorderIDs reorders the IDs in the main table.
 func (r *SQLiteRepository) ReorderIDs(ctx context.Context) error {
+	r.snapshot("reorder")
 	return r.withTx(ctx, func(tx *sqlx.Tx) error {
 		// check if last item has been deleted
 		if r.maxID() == 0 {
@@ -351,12 +411,15 @@ func (r *SQLiteRepository) bySQL(bs *Slice, q string, args ...any) error {
 // DeleteOne deletes one record from the relation table.
 func (r *SQLiteRepository) delete(ctx context.Context, bURL string) error {
 	return r.withTx(ctx, func(tx *sqlx.Tx) error {
-		_, err := tx.Exec("DELETE FROM bookmark_tags WHERE bookmark_url = ?", bURL)
+		before, err := rowsTx(tx, []string{bURL})
 		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec("DELETE FROM bookmark_tags WHERE bookmark_url = ?", bURL); err != nil {
 			return fmt.Errorf("failed to delete record: %w", err)
 		}
 
-		return nil
+		return record(ctx, tx, OpRemove, before, nil)
 	})
 }
 
@@ -385,7 +448,7 @@ func (r *SQLiteRepository) deleteOneTx(tx *sqlx.Tx, b *Row) error {
 	}
 	slog.Debug("deleted record", "id", b.ID)
 
-	return nil
+	return pruneOrphans(tx)
 }
 
 // deleteAll deletes all records in the give table.
@@ -421,6 +484,26 @@ func (r *SQLiteRepository) hasTx(tx *sqlx.Tx, target any) (bool, error) {
 	return exists, nil
 }
 
+// hasCanonicalTx returns ErrRecordDuplicate if the record's URL or canonical
+// URL matches the URL or canonical URL of an existing record.
+func hasCanonicalTx(tx *sqlx.Tx, b *Row) error {
+	for _, u := range []string{b.URL, b.CanonicalURL} {
+		if u == "" {
+			continue
+		}
+		var exists bool
+		q := "SELECT EXISTS(SELECT 1 FROM bookmarks WHERE url = ? OR (canonical_url != '' AND canonical_url = ?))"
+		if err := tx.Get(&exists, q, u, u); err != nil {
+			return fmt.Errorf("%w", err)
+		}
+		if exists {
+			return fmt.Errorf("%w: %q", ErrRecordDuplicate, u)
+		}
+	}
+
+	return nil
+}
+
 // insertAtID inserts a new record at the given ID.
 func (r *SQLiteRepository) insertAtID(tx *sqlx.Tx, b *Row) error {
 	if err := bookmark.Validate(b); err != nil {
@@ -428,9 +511,15 @@ func (r *SQLiteRepository) insertAtID(tx *sqlx.Tx, b *Row) error {
 	}
 	q := `
     INSERT
-    OR IGNORE INTO bookmarks (id, url, title, desc, created_at, updated_at, visit_count, favorite)
+    OR IGNORE INTO bookmarks (
+      id, url, title, desc, created_at, updated_at, visit_count, favorite,
+      archive_url, archive_hash, canonical_url, meta, state, state_at, remind_at, notes
+    )
     VALUES
-    (:id, :url, :title, :desc, :created_at, :updated_at, :visit_count, :favorite)`
+    (
+      :id, :url, :title, :desc, :created_at, :updated_at, :visit_count, :favorite,
+      :archive_url, :archive_hash, :canonical_url, :meta, :state, :state_at, :remind_at, :notes
+    )`
 	_, err := tx.NamedExec(q, b)
 	if err != nil {
 		return fmt.Errorf("%w: %q", err, b.URL)
@@ -450,9 +539,17 @@ func (r *SQLiteRepository) insertBulk(ctx context.Context, bs *Slice) error {
 	})
 
 	return r.withTx(ctx, func(tx *sqlx.Tx) error {
-		return bs.ForEachErr(func(b Row) error {
+		if err := bs.ForEachErr(func(b Row) error {
 			return r.insertIntoTx(tx, &b)
-		})
+		}); err != nil {
+			return err
+		}
+		after, err := rowsTx(tx, sliceURLs(bs))
+		if err != nil {
+			return err
+		}
+
+		return record(ctx, tx, OpImport, nil, after)
 	})
 }
 
@@ -466,6 +563,9 @@ func (r *SQLiteRepository) insertInto(ctx context.Context, b *Row) error {
 	}
 	// create record and associate tags
 	err := r.withTx(ctx, func(tx *sqlx.Tx) error {
+		if err := hasCanonicalTx(tx, b); err != nil {
+			return err
+		}
 		if err := insertRecord(tx, b); err != nil {
 			return err
 		}
@@ -510,12 +610,14 @@ func (r *SQLiteRepository) insertManyIntoTempTable(
 	q := `
   INSERT INTO temp_bookmarks (
     url, title, desc, created_at, last_visit,
-    updated_at, visit_count, favorite
+    updated_at, visit_count, favorite, archive_url, archive_hash,
+    canonical_url, meta, state, state_at, remind_at, notes
   )
   VALUES
     (
       :url, :title, :desc, :created_at, :last_visit,
-      :updated_at, :visit_count, :favorite
+      :updated_at, :visit_count, :favorite, :archive_url, :archive_hash,
+      :canonical_url, :meta, :state, :state_at, :remind_at, :notes
     )
   `
 	// FIX: pass the context
@@ -547,12 +649,14 @@ func insertRecord(tx *sqlx.Tx, b *Row) error {
 	r, err := tx.NamedExec(
 		`INSERT INTO bookmarks (
     url, title, desc, created_at, last_visit,
-    updated_at, visit_count, favorite
+    updated_at, visit_count, favorite, archive_url, archive_hash,
+    canonical_url, meta, state, state_at, remind_at, notes
   )
   VALUES
     (
       :url, :title, :desc, :created_at, :last_visit,
-      :updated_at, :visit_count, :favorite
+      :updated_at, :visit_count, :favorite, :archive_url, :archive_hash,
+      :canonical_url, :meta, :state, :state_at, :remind_at, :notes
     )`,
 		&b,
 	)
@@ -581,8 +685,27 @@ func (r *SQLiteRepository) maxID() int {
 	return lastIndex
 }
 
-// withTx executes a function within a transaction.
+// withTx executes a function within a transaction. Read-only encrypted
+// databases refuse it before any change.
+//
+// The changes to an encrypted database are encrypted back to its file once
+// committed, an exit without Close loses nothing.
 func (r *SQLiteRepository) withTx(ctx context.Context, fn func(tx *sqlx.Tx) error) error {
+	if r.mem == nil {
+		return r.inTx(ctx, fn)
+	}
+	if r.mem.readOnly {
+		return fmt.Errorf("%w: %q", ErrDBReadOnly, r.Name())
+	}
+	if err := r.inTx(ctx, fn); err != nil {
+		return err
+	}
+
+	return r.mem.seal(ctx)
+}
+
+// inTx executes a function within a transaction.
+func (r *SQLiteRepository) inTx(ctx context.Context, fn func(tx *sqlx.Tx) error) error {
 	tx, err := r.DB.BeginTxx(ctx, nil)
 	if err != nil {
 		return fmt.Errorf("begin transaction: %w", err)
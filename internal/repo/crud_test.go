@@ -322,3 +322,58 @@ func TestRecordIDs(t *testing.T) {
 		orderedIDs,
 	)
 }
+
+func TestMetadataRoundTrip(t *testing.T) {
+	r := setupTestDB(t)
+	defer teardownthewall(r.DB)
+	b := testSingleBookmark()
+	b.CanonicalURL = "https://example.com/canonical"
+	b.Meta.SiteName = "Example"
+	b.Meta.Author = "Jane Doe"
+	assert.NoError(t, r.InsertOne(context.Background(), b))
+
+	got, err := r.ByID(b.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, b.CanonicalURL, got.CanonicalURL)
+	assert.Equal(t, b.Meta, got.Meta)
+}
+
+func TestInsertDuplicateCanonical(t *testing.T) {
+	r := setupTestDB(t)
+	defer teardownthewall(r.DB)
+	b := testSingleBookmark()
+	b.CanonicalURL = "https://example.com/canonical"
+	assert.NoError(t, r.InsertOne(context.Background(), b))
+
+	got, exists := r.HasCanonical(b.CanonicalURL)
+	assert.True(t, exists)
+	assert.Equal(t, b.URL, got.URL)
+
+	dup := testSingleBookmark()
+	dup.URL = "https://example.com/canonical?utm_source=feed"
+	dup.CanonicalURL = b.CanonicalURL
+	err := r.InsertOne(context.Background(), dup)
+	assert.ErrorIs(t, err, ErrRecordDuplicate)
+
+	_, exists = r.HasCanonical("https://example.com/other")
+	assert.False(t, exists)
+}
+
+func TestSetNotes(t *testing.T) {
+	r := setupTestDB(t)
+	defer teardownthewall(r.DB)
+
+	b := testSingleBookmark()
+	ctx := context.Background()
+	assert.NoError(t, r.insertInto(ctx, b))
+	assert.NoError(t, r.SetNotes(ctx, b, "read the *second* chapter"))
+
+	got, err := r.ByURL(b.URL)
+	assert.NoError(t, err)
+	assert.Equal(t, "read the *second* chapter", got.Notes)
+
+	bs := slice.New[Row]()
+	assert.Error(t, r.ByQuery("third", bs))
+	assert.NoError(t, r.ByQuery("second", bs))
+	assert.Equal(t, 1, bs.Len())
+}
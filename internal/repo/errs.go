@@ -20,6 +20,10 @@ var (
 	ErrDBNameRequired       = errors.New("name required")
 	ErrDBMainNameReserved   = errors.New("name reserved")
 	ErrDBMainNotFound       = errors.New("main database not found")
+	ErrDBEncryptedInit      = errors.New("cannot initialize an encrypted database, lock it instead")
+	ErrDBConflict           = errors.New("encrypted database changed by another process")
+	ErrDBBusy               = errors.New("encrypted database busy")
+	ErrDBReadOnly           = errors.New("encrypted database opened read-only")
 )
 
 var (
@@ -40,6 +44,25 @@ var (
 	ErrBackupExists     = errors.New("backup already exists")
 	ErrBackupDisabled   = errors.New("backups are disabled")
 	ErrBackupNoPurge    = errors.New("no backup to purge")
+	ErrBackupNoPolicy   = errors.New("no backup retention policy set")
 	ErrBackupNotFound   = errors.New("no backup found")
 	ErrBackupPathNotSet = errors.New("backup path not set")
+	ErrBackupChecksum   = errors.New("backup checksum mismatch")
+	ErrBackupCompress   = errors.New("unknown backup compression")
+	ErrBackupVerify     = errors.New("backup verification failed")
+)
+
+var (
+	// collections errs.
+	ErrCollectionExists   = errors.New("collection already exists")
+	ErrCollectionNotFound = errors.New("collection not found")
+	ErrCollectionName     = errors.New("invalid collection name")
+	ErrCollectionPosition = errors.New("position out of range")
+)
+
+var (
+	// oplog errs.
+	ErrOplogNothingToUndo = errors.New("nothing to undo")
+	ErrOplogNothingToRedo = errors.New("nothing to redo")
+	ErrOplogConflict      = errors.New("journal out of sync with the records")
 )
@@ -7,6 +7,7 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/haaag/gm/internal/bookmark"
 	"github.com/haaag/gm/internal/config"
 	"github.com/haaag/gm/internal/format"
 	"github.com/haaag/gm/internal/format/color"
@@ -25,11 +26,31 @@ func RepoSummary(r *SQLiteRepository) string {
 		name += color.Gray(" (default) ").Italic().String()
 	}
 
-	return f.Header(color.Yellow(name).Italic().String()).
+	f.Header(color.Yellow(name).Italic().String()).
 		Ln().Row(records).
 		Ln().Row(tags).
-		Ln().Row(path).
-		Ln().String()
+		Ln()
+	if states := stateSummary(r); states != "" {
+		f.Row(format.PaddedLine("inbox:", states)).Ln()
+	}
+
+	return f.Row(path).Ln().String()
+}
+
+// stateSummary returns the number of records in each read-later state.
+//
+//	2 unread · 5 read · 1 archived
+func stateSummary(r *SQLiteRepository) string {
+	counts := CountByState(r)
+	if len(counts) == 0 {
+		return ""
+	}
+	parts := make([]string, 0, len(bookmark.States))
+	for _, s := range bookmark.States {
+		parts = append(parts, fmt.Sprintf("%d %s", counts[s], s))
+	}
+
+	return strings.Join(parts, " · ")
 }
 
 // RepoSummaryFromPath returns a summary of the repository.
@@ -91,6 +112,9 @@ func RepoSummaryRecordsFromPath(p string) string {
 		e := color.Gray("(locked)").Italic().String()
 		return format.PaddedLine(s, e)
 	}
+	if compressFormat(p) != "" {
+		return format.PaddedLine(filepath.Base(p), compressedRecords(p))
+	}
 	r, _ := New(p)
 	defer r.Close()
 
@@ -126,6 +150,9 @@ func BackupSummaryWithFmtDateFromPath(p string) string {
 		name += color.Gray(" (locked) ").Italic().String()
 		return name + bkTime.String()
 	}
+	if compressFormat(p) != "" {
+		return name + " " + compressedRecords(p) + " " + bkTime.String()
+	}
 
 	r, err := New(p)
 	if err != nil {
@@ -139,6 +166,34 @@ func BackupSummaryWithFmtDateFromPath(p string) string {
 	return r.Name() + " " + records.String() + " " + bkTime.String()
 }
 
+// compressedRecords returns the record count of a compressed backup, which
+// can not be opened, from the manifest.
+//
+//	(main: n) | (compressed)
+func compressedRecords(p string) string {
+	records := "(compressed)"
+	if m, err := ReadManifest(filepath.Dir(p)); err == nil {
+		if e, ok := m.Entry(p); ok {
+			records = fmt.Sprintf("(main: %d)", e.Records)
+		}
+	}
+
+	return color.Gray(records).Italic().String()
+}
+
+// backupEntryDetail returns the size, counts and compression ratio of the
+// backup recorded in the manifest.
+//
+//	size: 1.2 MiB, records: n, tags: n, ratio: 3.10x
+func backupEntryDetail(e *BackupEntry) string {
+	s := fmt.Sprintf("size: %s, records: %d, tags: %d", format.Size(e.Size), e.Records, e.Tags)
+	if e.Compression != "" {
+		s += fmt.Sprintf(", ratio: %.2fx", e.Ratio())
+	}
+
+	return color.Gray(s).Italic().String()
+}
+
 // BackupListDetail returns the details of a backup.
 func BackupListDetail(r *SQLiteRepository) string {
 	f := frame.New(frame.WithColorBorder(color.BrightGray))
@@ -150,16 +205,27 @@ func BackupListDetail(r *SQLiteRepository) string {
 	if err != nil {
 		return f.Row(format.PaddedLine("found:", "n/a\n")).String()
 	}
+	m, err := ReadManifest(r.Cfg.BackupDir)
+	if err != nil {
+		slog.Warn("reading backups manifest", "error", err)
+		m = &Manifest{}
+	}
 	backups := slice.New[string]()
 	backups.Append(fs...)
 
 	n := backups.Len()
 	backups.ForEach(func(p string) {
-		if n == 1 {
-			f.Footer(BackupSummaryWithFmtDateFromPath(p)).Ln()
-			return
+		lines := []string{BackupSummaryWithFmtDateFromPath(p)}
+		if e, ok := m.Entry(p); ok {
+			lines = append(lines, "  "+backupEntryDetail(e))
+		}
+		for i, s := range lines {
+			if n == 1 && i == len(lines)-1 {
+				f.Footer(s).Ln()
+				continue
+			}
+			f.Row(s).Ln()
 		}
-		f.Row(BackupSummaryWithFmtDateFromPath(p)).Ln()
 		n--
 	})
 
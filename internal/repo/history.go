@@ -0,0 +1,168 @@
+package repo
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/haaag/gm/internal/bookmark"
+	"github.com/haaag/gm/internal/config"
+)
+
+// sourceKey is the context key of the command that makes the changes.
+type sourceKey struct{}
+
+// WithSource returns a context that records the command making the changes
+// in the history, like `import firefox`.
+func WithSource(ctx context.Context, src string) context.Context {
+	return context.WithValue(ctx, sourceKey{}, src)
+}
+
+// sourceFrom returns the command making the changes, if any.
+func sourceFrom(ctx context.Context) string {
+	s, _ := ctx.Value(sourceKey{}).(string)
+	return s
+}
+
+// FieldChanges holds the fields changed by a revision.
+type FieldChanges []bookmark.FieldChange
+
+// Value implements the driver.Valuer interface.
+func (c FieldChanges) Value() (driver.Value, error) {
+	if c == nil {
+		c = FieldChanges{}
+	}
+	b, err := json.Marshal(c)
+	if err != nil {
+		return nil, fmt.Errorf("marshalling changes: %w", err)
+	}
+
+	return string(b), nil
+}
+
+// Scan implements the sql.Scanner interface.
+func (c *FieldChanges) Scan(src any) error {
+	var b []byte
+	switch v := src.(type) {
+	case nil:
+		*c = FieldChanges{}
+		return nil
+	case string:
+		b = []byte(v)
+	case []byte:
+		b = v
+	default:
+		return fmt.Errorf("%w: changes type %T", ErrRecordScan, src)
+	}
+	if err := json.Unmarshal(b, c); err != nil {
+		return fmt.Errorf("unmarshalling changes: %w", err)
+	}
+
+	return nil
+}
+
+// Revision is a change of a bookmark.
+type Revision struct {
+	ID        int          `db:"id"           json:"id"`
+	URL       string       `db:"bookmark_url" json:"url"`
+	Op        string       `db:"op"           json:"op"`
+	Source    string       `db:"source"       json:"source"`
+	Changes   FieldChanges `db:"changes"      json:"changes"`
+	CreatedAt string       `db:"created_at"   json:"created_at"`
+}
+
+// History returns the revisions of the record, oldest first.
+func (r *SQLiteRepository) History(bURL string) ([]Revision, error) {
+	var rs []Revision
+	q := "SELECT * FROM history WHERE bookmark_url = ? ORDER BY id ASC"
+	if err := r.DB.Select(&rs, q, bURL); err != nil {
+		return nil, fmt.Errorf("%w", err)
+	}
+
+	return rs, nil
+}
+
+// record writes the operation to the history of the records and to the
+// journal.
+func record(ctx context.Context, tx *sqlx.Tx, op string, before, after []Row) error {
+	if err := writeHistory(tx, op, sourceFrom(ctx), before, after); err != nil {
+		return err
+	}
+
+	return journal(tx, op, before, after)
+}
+
+// recordTx writes an operation that changed the records in place, reading
+// them again after the change.
+func recordTx(ctx context.Context, tx *sqlx.Tx, op string, before []Row) error {
+	urls := make([]string, 0, len(before))
+	for _, b := range before {
+		urls = append(urls, b.URL)
+	}
+	after, err := rowsTx(tx, urls)
+	if err != nil {
+		return err
+	}
+
+	return record(ctx, tx, op, before, after)
+}
+
+// writeHistory writes a revision for each record changed by the operation,
+// matching the records by ID, and keeps the configured number of revisions
+// per record.
+func writeHistory(tx *sqlx.Tx, op, src string, before, after []Row) error {
+	keep := config.History.Revisions
+	if keep <= 0 {
+		return nil
+	}
+	old := make(map[int]*Row, len(before))
+	for i := range before {
+		old[before[i].ID] = &before[i]
+	}
+	q := "INSERT INTO history (bookmark_url, op, source, changes) VALUES (?, ?, ?, ?)"
+	prune := `
+    DELETE FROM history
+    WHERE bookmark_url = ?1
+      AND id NOT IN (SELECT id FROM history WHERE bookmark_url = ?1 ORDER BY id DESC LIMIT ?2)`
+	write := func(a, b *Row) error {
+		var url string
+		if b != nil {
+			url = b.URL
+		} else {
+			url = a.URL
+		}
+		changes := bookmark.Diff(a, b)
+		if len(changes) == 0 {
+			return nil
+		}
+		if _, err := tx.Exec(q, url, op, src, FieldChanges(changes)); err != nil {
+			return fmt.Errorf("history: %w: %q", err, url)
+		}
+		if _, err := tx.Exec(prune, url, keep); err != nil {
+			return fmt.Errorf("history: pruning: %w: %q", err, url)
+		}
+
+		return nil
+	}
+	for i := range after {
+		b := &after[i]
+		a := old[b.ID]
+		delete(old, b.ID)
+		if err := write(a, b); err != nil {
+			return err
+		}
+	}
+	for i := range before {
+		if _, ok := old[before[i].ID]; !ok {
+			continue
+		}
+		if err := write(&before[i], nil); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,90 @@
+package repo
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/haaag/gm/internal/config"
+	"github.com/haaag/gm/internal/slice"
+)
+
+func TestHistory(t *testing.T) {
+	r := setupTestDB(t)
+	defer teardownthewall(r.DB)
+	ctx := WithSource(context.Background(), "import firefox")
+	assert.NoError(t, r.InsertMany(ctx, testSliceBookmarks(2)))
+	old, err := r.ByID(1)
+	assert.NoError(t, err)
+
+	rs, err := r.History(old.URL)
+	assert.NoError(t, err)
+	assert.Len(t, rs, 1)
+	assert.Equal(t, OpImport, rs[0].Op)
+	assert.Equal(t, "import firefox", rs[0].Source)
+
+	// update the title and the URL, the history follows the new URL
+	updated := *old
+	updated.URL = "https://example.com/renamed"
+	updated.Title = "Renamed"
+	_, err = r.UpdateOne(WithSource(context.Background(), "edit"), &updated, old)
+	assert.NoError(t, err)
+	rs, err = r.History(old.URL)
+	assert.NoError(t, err)
+	assert.Empty(t, rs)
+	rs, err = r.History(updated.URL)
+	assert.NoError(t, err)
+	assert.Len(t, rs, 2)
+	last := rs[1]
+	assert.Equal(t, OpUpdate, last.Op)
+	assert.Equal(t, "edit", last.Source)
+	fields := make(map[string][2]string)
+	for _, c := range last.Changes {
+		fields[c.Field] = [2]string{c.Old, c.New}
+	}
+	assert.Equal(t, [2]string{old.URL, updated.URL}, fields["url"])
+	assert.Equal(t, [2]string{old.Title, "Renamed"}, fields["title"])
+	assert.NotContains(t, fields, "tags")
+
+	// remove
+	bs := slice.New[Row]()
+	bs.Push(&updated)
+	assert.NoError(t, r.DeleteMany(context.Background(), bs))
+	rs, err = r.History(updated.URL)
+	assert.NoError(t, err)
+	assert.Len(t, rs, 3)
+	assert.Equal(t, OpRemove, rs[2].Op)
+}
+
+func TestHistoryRetention(t *testing.T) {
+	r := setupTestDB(t)
+	defer teardownthewall(r.DB)
+	defer func(n int) { config.History.Revisions = n }(config.History.Revisions)
+	config.History.Revisions = 2
+	ctx := context.Background()
+	assert.NoError(t, r.InsertMany(ctx, testSliceBookmarks(1)))
+	b, err := r.ByID(1)
+	assert.NoError(t, err)
+	for _, title := range []string{"one", "two", "three"} {
+		cur := *b
+		cur.Title = title
+		_, err := r.UpdateOne(ctx, &cur, b)
+		assert.NoError(t, err)
+		b = &cur
+	}
+	rs, err := r.History(b.URL)
+	assert.NoError(t, err)
+	assert.Len(t, rs, 2)
+	assert.Equal(t, "three", rs[1].Changes[0].New)
+
+	// disabled
+	config.History.Revisions = 0
+	cur := *b
+	cur.Title = "four"
+	_, err = r.UpdateOne(ctx, &cur, b)
+	assert.NoError(t, err)
+	rs, err = r.History(b.URL)
+	assert.NoError(t, err)
+	assert.Len(t, rs, 2)
+}
@@ -17,6 +17,15 @@ type tableSchema struct {
 
 // tablesAnd returns all tables and their schema.
 func tablesAndSchema() []tableSchema {
+	return append(coreTables(), schemaContent, schemaCollections, schemaCollectionItems, schemaOplog, schemaHistory)
+}
+
+// coreTables returns the tables required to consider a database
+// initialized.
+//
+// Tables added after the first release are created by migrations on older
+// databases.
+func coreTables() []tableSchema {
 	return []tableSchema{
 		schemaMain, schemaTags, schemaRelation,
 	}
@@ -43,7 +52,8 @@ func (r *SQLiteRepository) Init() error {
 			}
 		}
 
-		return nil
+		// new databases start with the latest schema, no need to migrate.
+		return setSchemaVersion(tx, latestSchemaVersion())
 	})
 }
 
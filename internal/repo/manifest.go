@@ -0,0 +1,371 @@
+package repo
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+
+	"github.com/haaag/gm/internal/sys/files"
+)
+
+// manifestName is the file of the backups directory with the details of
+// each backup.
+const manifestName = "manifest.json"
+
+// Compression formats of the backups.
+const (
+	compressGzip = "gzip"
+	compressZstd = "zstd"
+)
+
+// BackupEntry holds the details of a backup, recorded when created.
+type BackupEntry struct {
+	File        string `json:"file"`
+	Source      string `json:"source"`
+	CreatedAt   string `json:"created_at"`
+	SHA256      string `json:"sha256"`                // checksum of the file
+	Size        int64  `json:"size"`                  // size of the file
+	DBSize      int64  `json:"db_size"`               // size of the database image
+	Compression string `json:"compression,omitempty"` // gzip or zstd
+	Records     int    `json:"records"`
+	Tags        int    `json:"tags"`
+	Schema      int    `json:"schema"`
+}
+
+// Ratio returns the compression ratio of the backup.
+func (e *BackupEntry) Ratio() float64 {
+	if e.Size == 0 {
+		return 0
+	}
+
+	return float64(e.DBSize) / float64(e.Size)
+}
+
+// Manifest holds the details of the backups of a directory, by file name.
+type Manifest struct {
+	Backups map[string]*BackupEntry `json:"backups"`
+}
+
+// ReadManifest reads the manifest of the backups directory, empty if it does
+// not exist.
+func ReadManifest(dir string) (*Manifest, error) {
+	m := &Manifest{Backups: make(map[string]*BackupEntry)}
+	b, err := os.ReadFile(filepath.Join(dir, manifestName))
+	if errors.Is(err, fs.ErrNotExist) {
+		return m, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading manifest: %w", err)
+	}
+	if err := json.Unmarshal(b, m); err != nil {
+		return nil, fmt.Errorf("reading manifest: %w", err)
+	}
+	if m.Backups == nil {
+		m.Backups = make(map[string]*BackupEntry)
+	}
+
+	return m, nil
+}
+
+// Entry returns the details of the backup at p.
+func (m *Manifest) Entry(p string) (*BackupEntry, bool) {
+	e, ok := m.Backups[filepath.Base(p)]
+	return e, ok
+}
+
+// updateManifest applies fn to the manifest of the backups directory and
+// writes it back, holding its lock. Entries of removed backups are dropped.
+func updateManifest(dir string, fn func(m *Manifest)) error {
+	p := filepath.Join(dir, manifestName)
+	unlock, err := lockFile(p + ".lock")
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	m, err := ReadManifest(dir)
+	if err != nil {
+		return err
+	}
+	fn(m)
+	for name := range m.Backups {
+		if !files.Exists(filepath.Join(dir, name)) {
+			delete(m.Backups, name)
+		}
+	}
+	b, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("writing manifest: %w", err)
+	}
+	tmp := p + ".tmp"
+	if err := os.WriteFile(tmp, b, files.FilePerm); err != nil {
+		return fmt.Errorf("writing manifest: %w", err)
+	}
+	if err := os.Rename(tmp, p); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("writing manifest: %w", err)
+	}
+
+	return nil
+}
+
+// recordBackup adds the backup at p, taken from the repository, to the
+// manifest.
+func recordBackup(r *SQLiteRepository, p, compression string, dbSize int64) error {
+	data, err := os.ReadFile(p)
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+	sum := sha256.Sum256(data)
+	schema, err := r.SchemaVersion()
+	if err != nil {
+		return err
+	}
+	src := r.Cfg.Fullpath()
+	if r.mem != nil && r.mem.path != "" {
+		src = r.mem.path
+	}
+	e := &BackupEntry{
+		File:        filepath.Base(p),
+		Source:      src,
+		CreatedAt:   time.Now().Format(time.RFC3339),
+		SHA256:      hex.EncodeToString(sum[:]),
+		Size:        int64(len(data)),
+		DBSize:      dbSize,
+		Compression: compression,
+		Records:     CountMainRecords(r),
+		Tags:        CountTagsRecords(r),
+		Schema:      schema,
+	}
+
+	return updateManifest(filepath.Dir(p), func(m *Manifest) {
+		m.Backups[e.File] = e
+	})
+}
+
+// MoveBackupEntry moves the manifest entry of the backup at from to the
+// file at to, like a backup encrypted after created, with its checksum.
+func MoveBackupEntry(from, to string) error {
+	data, err := os.ReadFile(to)
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+	sum := sha256.Sum256(data)
+
+	return updateManifest(filepath.Dir(to), func(m *Manifest) {
+		e, ok := m.Entry(from)
+		if !ok {
+			return
+		}
+		delete(m.Backups, e.File)
+		e.File = filepath.Base(to)
+		e.SHA256 = hex.EncodeToString(sum[:])
+		e.Size = int64(len(data))
+		m.Backups[e.File] = e
+	})
+}
+
+// compressExt returns the extension of the compression format.
+func compressExt(format string) (string, error) {
+	switch format {
+	case "", "none":
+		return "", nil
+	case compressGzip:
+		return ".gz", nil
+	case compressZstd:
+		return ".zst", nil
+	default:
+		return "", fmt.Errorf("%w: %q", ErrBackupCompress, format)
+	}
+}
+
+// compressFormat returns the compression format of the backup by its
+// extension, ignoring the encryption.
+func compressFormat(p string) string {
+	switch filepath.Ext(strings.TrimSuffix(p, ".enc")) {
+	case ".gz":
+		return compressGzip
+	case ".zst":
+		return compressZstd
+	default:
+		return ""
+	}
+}
+
+// compress compresses the database image.
+func compress(image []byte, format string) ([]byte, error) {
+	var buf bytes.Buffer
+	var w io.WriteCloser
+	switch format {
+	case compressGzip:
+		w = gzip.NewWriter(&buf)
+	case compressZstd:
+		zw, err := zstd.NewWriter(&buf)
+		if err != nil {
+			return nil, fmt.Errorf("%w", err)
+		}
+		w = zw
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrBackupCompress, format)
+	}
+	if _, err := w.Write(image); err != nil {
+		return nil, fmt.Errorf("compressing backup: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return nil, fmt.Errorf("compressing backup: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// decompress returns the database image of the data, compressed in the
+// given format.
+func decompress(data []byte, format string) ([]byte, error) {
+	var rd io.Reader
+	switch format {
+	case "":
+		return data, nil
+	case compressGzip:
+		gr, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrDBCorrupted, err)
+		}
+		defer gr.Close()
+		rd = gr
+	case compressZstd:
+		zr, err := zstd.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrDBCorrupted, err)
+		}
+		defer zr.Close()
+		rd = zr
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrBackupCompress, format)
+	}
+	image, err := io.ReadAll(rd)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrDBCorrupted, err)
+	}
+
+	return image, nil
+}
+
+// Results of the checks of a backup.
+const (
+	checkOK       = "ok"
+	checkMismatch = "mismatch" // checksum differs from the manifest
+	checkMissing  = "missing"  // not in the manifest
+	checkFailed   = "failed"   // integrity check failed
+	checkLocked   = "locked"   // encrypted, the integrity is not checked
+)
+
+// BackupCheck is the result of the verification of a backup.
+type BackupCheck struct {
+	File      string `json:"file"`
+	Checksum  string `json:"checksum"`  // ok, mismatch or missing
+	Integrity string `json:"integrity"` // ok, failed or locked
+	Err       string `json:"error,omitempty"`
+}
+
+// OK reports whether the backup passed the checks. A locked backup missing
+// from the manifest could not be checked at all.
+func (c *BackupCheck) OK() bool {
+	if c.Checksum == checkMissing && c.Integrity == checkLocked {
+		return false
+	}
+
+	return c.Checksum != checkMismatch && c.Integrity != checkFailed
+}
+
+// Partial reports whether some check was left out, a backup missing from
+// the manifest or locked.
+func (c *BackupCheck) Partial() bool {
+	return c.Checksum == checkMissing || c.Integrity == checkLocked
+}
+
+// VerifyBackups checks the checksum of the backups of the database with the
+// manifest and the integrity of their database. Locked backups are only
+// checked by their checksum.
+func (r *SQLiteRepository) VerifyBackups() ([]BackupCheck, error) {
+	fs, err := r.BackupsList()
+	if err != nil {
+		return nil, err
+	}
+	m, err := ReadManifest(r.Cfg.BackupDir)
+	if err != nil {
+		return nil, err
+	}
+	checks := make([]BackupCheck, 0, len(fs))
+	for _, p := range fs {
+		checks = append(checks, verifyBackup(p, m))
+	}
+
+	return checks, nil
+}
+
+// verifyBackup checks the backup at p.
+func verifyBackup(p string, m *Manifest) BackupCheck {
+	c := BackupCheck{File: filepath.Base(p), Checksum: checkMissing, Integrity: checkFailed}
+	data, err := os.ReadFile(p)
+	if err != nil {
+		c.Err = err.Error()
+		return c
+	}
+	if e, ok := m.Entry(p); ok {
+		sum := sha256.Sum256(data)
+		c.Checksum = checkOK
+		if hex.EncodeToString(sum[:]) != e.SHA256 {
+			c.Checksum, c.Err = checkMismatch, ErrBackupChecksum.Error()
+			return c
+		}
+	}
+	if IsEncrypted(p) {
+		c.Integrity = checkLocked
+		if c.Checksum == checkMissing {
+			c.Err = "locked and not in the manifest, not verified"
+		}
+
+		return c
+	}
+	if err := verifyImage(p, data); err != nil {
+		c.Err = err.Error()
+		return c
+	}
+	c.Integrity = checkOK
+
+	return c
+}
+
+// verifyImage runs the integrity check of the backup database, compressed
+// ones are checked in memory, the plaintext is never written to disk.
+func verifyImage(p string, data []byte) error {
+	format := compressFormat(p)
+	if format == "" {
+		return verifySQLiteIntegrity(p)
+	}
+	image, err := decompress(data, format)
+	if err != nil {
+		return err
+	}
+	if !bytes.HasPrefix(image, sqliteMagic) {
+		return fmt.Errorf("%w: not a database image", ErrDBCorrupted)
+	}
+	r, err := openImage(&SQLiteCfg{Name: filepath.Base(p), DateFormat: defaultDateFormat}, image)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	return integrityCheck(r.DB)
+}
@@ -0,0 +1,107 @@
+package repo
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/haaag/gm/internal/config"
+	"github.com/haaag/gm/internal/sys/files"
+)
+
+//nolint:paralleltest //modifies the backup config
+func TestCompressedBackup(t *testing.T) {
+	auto, compression := config.Backup.Auto, config.Backup.Compress
+	t.Cleanup(func() { config.Backup.Auto, config.Backup.Compress = auto, compression })
+	config.Backup.Auto = false
+
+	for _, format := range []string{compressGzip, compressZstd} {
+		t.Run(format, func(t *testing.T) {
+			config.Backup.Compress = format
+			p := filepath.Join(t.TempDir(), "bookmarks.db")
+			r, err := Init(p)
+			assert.NoError(t, err)
+			assert.NoError(t, r.Init())
+			defer r.Close()
+			assert.NoError(t, r.InsertMany(context.Background(), testSliceBookmarks(10)))
+
+			name, err := newBackup(r)
+			assert.NoError(t, err)
+			assert.Equal(t, format, compressFormat(name))
+			bkPath := filepath.Join(r.Cfg.BackupDir, name)
+			assert.NoFileExists(t, strings.TrimSuffix(strings.TrimSuffix(bkPath, ".gz"), ".zst"))
+
+			m, err := ReadManifest(r.Cfg.BackupDir)
+			assert.NoError(t, err)
+			e, ok := m.Entry(bkPath)
+			assert.True(t, ok)
+			assert.Equal(t, 10, e.Records)
+			assert.Equal(t, format, e.Compression)
+			assert.Equal(t, p, e.Source)
+			assert.Greater(t, e.Ratio(), 1.0)
+
+			image, err := ReadBackup(bkPath)
+			assert.NoError(t, err)
+			assert.Equal(t, e.DBSize, int64(len(image)))
+			bk, err := OpenBackup(bkPath, image)
+			assert.NoError(t, err)
+			defer bk.Close()
+			assert.Equal(t, 10, CountMainRecords(bk))
+		})
+	}
+
+	config.Backup.Compress = "lz4"
+	_, err := compressExt(config.Backup.Compress)
+	assert.ErrorIs(t, err, ErrBackupCompress)
+}
+
+//nolint:paralleltest //modifies the backup config
+func TestVerifyBackups(t *testing.T) {
+	auto, compression := config.Backup.Auto, config.Backup.Compress
+	t.Cleanup(func() { config.Backup.Auto, config.Backup.Compress = auto, compression })
+	config.Backup.Auto = false
+
+	p := filepath.Join(t.TempDir(), "bookmarks.db")
+	r, err := Init(p)
+	assert.NoError(t, err)
+	assert.NoError(t, r.Init())
+	defer r.Close()
+	assert.NoError(t, r.InsertMany(context.Background(), testSliceBookmarks(3)))
+	plain := testOldBackup(t, r)
+	// the renamed backup is no longer in the manifest
+	config.Backup.Compress = compressZstd
+	zst, err := newBackup(r)
+	assert.NoError(t, err)
+
+	// a locked backup missing from the manifest can not be verified
+	locked := filepath.Join(r.Cfg.BackupDir, time.Now().Add(-2*time.Hour).Format(defaultDateFormat)+"_bookmarks.db.enc")
+	assert.NoError(t, os.WriteFile(locked, []byte("age-encryption.org/v1"), files.FilePerm))
+
+	checks, err := r.VerifyBackups()
+	assert.NoError(t, err)
+	assert.Len(t, checks, 3)
+	assert.Equal(t, checkLocked, checks[0].Integrity)
+	assert.False(t, checks[0].OK())
+	assert.Equal(t, BackupCheck{File: filepath.Base(plain), Checksum: checkMissing, Integrity: checkOK}, checks[1])
+	assert.True(t, checks[1].OK())
+	assert.True(t, checks[1].Partial())
+	assert.Equal(t, BackupCheck{File: zst, Checksum: checkOK, Integrity: checkOK}, checks[2])
+	assert.NoError(t, os.Remove(locked))
+
+	// tampered
+	zstPath := filepath.Join(r.Cfg.BackupDir, zst)
+	assert.NoError(t, os.WriteFile(zstPath, []byte("not a backup"), files.FilePerm))
+	assert.NoError(t, os.WriteFile(plain, []byte("not a database"), files.FilePerm))
+	checks, err = r.VerifyBackups()
+	assert.NoError(t, err)
+	assert.Equal(t, checkMismatch, checks[1].Checksum)
+	assert.Equal(t, checkFailed, checks[0].Integrity)
+	for _, c := range checks {
+		assert.False(t, c.OK())
+	}
+}
@@ -0,0 +1,132 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// migration represents a schema change applied to databases created with an
+// older schema.
+type migration struct {
+	version int    // schema version after applying the migration
+	desc    string // short description
+	sql     string // statements to execute
+}
+
+// migrations holds the schema changes in order.
+//
+// New databases are created with the latest schema, so these only run on
+// databases created by older versions of the app.
+var migrations = []migration{
+	{
+		version: 1,
+		desc:    "add archive_url to bookmarks",
+		sql:     `ALTER TABLE bookmarks ADD COLUMN archive_url TEXT DEFAULT "";`,
+	},
+	{
+		version: 2,
+		desc:    "add archive_hash to bookmarks",
+		sql:     `ALTER TABLE bookmarks ADD COLUMN archive_hash TEXT DEFAULT "";`,
+	},
+	{
+		version: 3,
+		desc:    "add bookmark_content full-text index",
+		sql:     tableContentSchema,
+	},
+	{
+		version: 4,
+		desc:    "add canonical_url and meta to bookmarks",
+		sql: `
+    ALTER TABLE bookmarks ADD COLUMN canonical_url TEXT DEFAULT "";
+    ALTER TABLE bookmarks ADD COLUMN meta TEXT DEFAULT "";`,
+	},
+	{
+		version: 5,
+		desc:    "add read-later state to bookmarks",
+		sql: `
+    ALTER TABLE bookmarks ADD COLUMN state TEXT DEFAULT "";
+    ALTER TABLE bookmarks ADD COLUMN state_at TEXT DEFAULT "";
+    ALTER TABLE bookmarks ADD COLUMN remind_at TEXT DEFAULT "";`,
+	},
+	{
+		version: 6,
+		desc:    "add notes to bookmarks",
+		sql:     `ALTER TABLE bookmarks ADD COLUMN notes TEXT DEFAULT "";`,
+	},
+	{
+		version: 7,
+		desc:    "add collections",
+		sql:     tableCollSchema + tableCollIndex + tableCollItemSchema + tableCollItemIndex,
+	},
+	{
+		version: 8,
+		desc:    "add oplog journal",
+		sql:     tableOplogSchema,
+	},
+	{
+		version: 9,
+		desc:    "add bookmarks history",
+		sql:     tableHistorySchema + tableHistoryIndex,
+	},
+}
+
+// latestSchemaVersion returns the version of the latest schema.
+func latestSchemaVersion() int {
+	if len(migrations) == 0 {
+		return 0
+	}
+
+	return migrations[len(migrations)-1].version
+}
+
+// SchemaVersion returns the schema version of the database.
+func (r *SQLiteRepository) SchemaVersion() (int, error) {
+	var v int
+	if err := r.DB.Get(&v, "PRAGMA user_version"); err != nil {
+		return 0, fmt.Errorf("reading schema version: %w", err)
+	}
+
+	return v, nil
+}
+
+// Migrate applies the pending migrations to the database.
+func (r *SQLiteRepository) Migrate() error {
+	current, err := r.SchemaVersion()
+	if err != nil {
+		return err
+	}
+	pending := make([]migration, 0, len(migrations))
+	for _, m := range migrations {
+		if m.version > current {
+			pending = append(pending, m)
+		}
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+	r.snapshot("migrate")
+
+	// read-only encrypted databases are migrated in memory only.
+	return r.inTx(context.Background(), func(tx *sqlx.Tx) error {
+		for _, m := range pending {
+			slog.Info("applying migration", "database", r.Name(), "version", m.version, "desc", m.desc)
+			if _, err := tx.Exec(m.sql); err != nil {
+				return fmt.Errorf("migration %d (%s): %w", m.version, m.desc, err)
+			}
+		}
+
+		return setSchemaVersion(tx, latestSchemaVersion())
+	})
+}
+
+// setSchemaVersion sets the schema version of the database.
+func setSchemaVersion(tx *sqlx.Tx, v int) error {
+	if _, err := tx.Exec(fmt.Sprintf("PRAGMA user_version = %d", v)); err != nil {
+		return fmt.Errorf("setting schema version: %w", err)
+	}
+
+	return nil
+}
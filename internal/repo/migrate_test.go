@@ -0,0 +1,70 @@
+package repo
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+// legacyMainSchema is the main table schema before any migration.
+const legacyMainSchema = `
+    CREATE TABLE IF NOT EXISTS bookmarks (
+        id          INTEGER PRIMARY KEY AUTOINCREMENT,
+        url         TEXT    NOT NULL UNIQUE,
+        title       TEXT    DEFAULT "",
+        desc        TEXT    DEFAULT "",
+        created_at  TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+        last_visit  TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+        updated_at  TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+        visit_count INTEGER DEFAULT 0,
+        favorite    BOOLEAN DEFAULT FALSE
+    );`
+
+//nolint:errcheck //test
+func createLegacyDB(t *testing.T) string {
+	t.Helper()
+	p := filepath.Join(t.TempDir(), "legacy.db")
+	db, err := sqlx.Open("sqlite3", p)
+	assert.NoError(t, err)
+	defer db.Close()
+
+	for _, s := range []string{legacyMainSchema, tableTagsSchema, tableRelationSchema} {
+		_, err := db.ExecContext(context.Background(), s)
+		assert.NoError(t, err)
+	}
+	_, err = db.Exec(`INSERT INTO bookmarks (url, title) VALUES ('https://example.com', 'Example')`)
+	assert.NoError(t, err)
+
+	return p
+}
+
+func TestMigrate(t *testing.T) {
+	t.Parallel()
+	p := createLegacyDB(t)
+	r, err := New(p)
+	assert.NoError(t, err)
+	defer r.Close()
+
+	v, err := r.SchemaVersion()
+	assert.NoError(t, err)
+	assert.Equal(t, latestSchemaVersion(), v)
+
+	b, err := r.ByID(1)
+	assert.NoError(t, err)
+	assert.Equal(t, "https://example.com", b.URL)
+	assert.Empty(t, b.ArchiveURL)
+
+	// migrating again is a no-op
+	assert.NoError(t, r.Migrate())
+}
+
+func TestInitSetsSchemaVersion(t *testing.T) {
+	r := setupTestDB(t)
+	defer teardownthewall(r.DB)
+	v, err := r.SchemaVersion()
+	assert.NoError(t, err)
+	assert.Equal(t, latestSchemaVersion(), v)
+}
@@ -0,0 +1,29 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// SetNotes sets the Markdown notes of the record.
+func (r *SQLiteRepository) SetNotes(ctx context.Context, b *Row, notes string) error {
+	now := time.Now().UTC().Format(time.RFC3339)
+	q := `UPDATE bookmarks SET notes = ?, updated_at = ? WHERE url = ?`
+
+	return r.withTx(ctx, func(tx *sqlx.Tx) error {
+		before, err := rowsTx(tx, urlsOf(b))
+		if err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, q, notes, now, b.URL); err != nil {
+			return fmt.Errorf("setting notes: %w: %q", err, b.URL)
+		}
+		b.Notes = notes
+		b.UpdatedAt = now
+
+		return recordTx(ctx, tx, OpNotes, before)
+	})
+}
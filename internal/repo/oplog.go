@@ -0,0 +1,339 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strconv"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/haaag/gm/internal/bookmark"
+	"github.com/haaag/gm/internal/config"
+)
+
+// Journal operations.
+const (
+	OpAdd    = "add"
+	OpImport = "import"
+	OpUpdate = "update"
+	OpRemove = "remove"
+	OpEdit   = "edit"
+	OpState  = "state"
+	OpRemind = "remind"
+	OpNotes  = "notes"
+)
+
+// rowsChunk is the max number of URLs per query, below the SQLite limit of
+// variables.
+const rowsChunk = 500
+
+// Image holds the records before or after an operation.
+type Image []Row
+
+// Value implements the driver.Valuer interface.
+func (i Image) Value() (driver.Value, error) {
+	if i == nil {
+		i = Image{}
+	}
+	b, err := json.Marshal(i)
+	if err != nil {
+		return nil, fmt.Errorf("marshalling image: %w", err)
+	}
+
+	return string(b), nil
+}
+
+// Scan implements the sql.Scanner interface.
+func (i *Image) Scan(src any) error {
+	var b []byte
+	switch v := src.(type) {
+	case nil:
+		*i = Image{}
+		return nil
+	case string:
+		b = []byte(v)
+	case []byte:
+		b = v
+	default:
+		return fmt.Errorf("%w: image type %T", ErrRecordScan, src)
+	}
+	if err := json.Unmarshal(b, i); err != nil {
+		return fmt.Errorf("unmarshalling image: %w", err)
+	}
+
+	return nil
+}
+
+// Entry is an operation of the journal, with the records before and after
+// it.
+type Entry struct {
+	ID        int    `db:"id"           json:"id"`
+	Op        string `db:"op"           json:"op"`
+	Desc      string `db:"desc"         json:"desc"`
+	Before    Image  `db:"before_image" json:"before"`
+	After     Image  `db:"after_image"  json:"after"`
+	Undone    bool   `db:"undone"       json:"undone"`
+	CreatedAt string `db:"created_at"   json:"created_at"`
+}
+
+// Oplog returns the last n operations of the journal, newest first. A zero
+// n returns all of them.
+func (r *SQLiteRepository) Oplog(n int) ([]Entry, error) {
+	q := "SELECT * FROM oplog ORDER BY id DESC"
+	if n > 0 {
+		q += " LIMIT " + strconv.Itoa(n)
+	}
+	var es []Entry
+	if err := r.DB.Select(&es, q); err != nil {
+		return nil, fmt.Errorf("%w", err)
+	}
+
+	return es, nil
+}
+
+// Undo reverts the last operation not undone.
+func (r *SQLiteRepository) Undo(ctx context.Context) (*Entry, error) {
+	var e Entry
+	q := "SELECT * FROM oplog WHERE undone = 0 ORDER BY id DESC LIMIT 1"
+	if err := r.DB.Get(&e, q); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrOplogNothingToUndo
+		}
+
+		return nil, fmt.Errorf("%w", err)
+	}
+	if err := r.withTx(ctx, func(tx *sqlx.Tx) error {
+		if err := r.restore(tx, e.After, e.Before); err != nil {
+			return err
+		}
+		if err := writeHistory(tx, "undo", e.Op, e.After, e.Before); err != nil {
+			return err
+		}
+		_, err := tx.Exec("UPDATE oplog SET undone = 1 WHERE id = ?", e.ID)
+
+		return err
+	}); err != nil {
+		return nil, fmt.Errorf("undo %q: %w", e.Op, err)
+	}
+	slog.Info("operation undone", "id", e.ID, "op", e.Op)
+
+	return &e, nil
+}
+
+// Redo applies again the first operation undone.
+//
+// The IDs are reordered after redoing a removal, like the removal does.
+func (r *SQLiteRepository) Redo(ctx context.Context) (*Entry, error) {
+	var e Entry
+	q := "SELECT * FROM oplog WHERE undone = 1 ORDER BY id ASC LIMIT 1"
+	if err := r.DB.Get(&e, q); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrOplogNothingToRedo
+		}
+
+		return nil, fmt.Errorf("%w", err)
+	}
+	if err := r.withTx(ctx, func(tx *sqlx.Tx) error {
+		if err := r.restore(tx, e.Before, e.After); err != nil {
+			return err
+		}
+		if err := writeHistory(tx, "redo", e.Op, e.Before, e.After); err != nil {
+			return err
+		}
+		_, err := tx.Exec("UPDATE oplog SET undone = 0 WHERE id = ?", e.ID)
+
+		return err
+	}); err != nil {
+		return nil, fmt.Errorf("redo %q: %w", e.Op, err)
+	}
+	if e.Op == OpRemove {
+		if err := r.ReorderIDs(ctx); err != nil {
+			return nil, fmt.Errorf("reordering IDs: %w", err)
+		}
+	}
+	slog.Info("operation redone", "id", e.ID, "op", e.Op)
+
+	return &e, nil
+}
+
+// journal writes the operation to the journal, discarding the operations
+// undone and the ones beyond the configured depth.
+func journal(tx *sqlx.Tx, op string, before, after []Row) error {
+	depth := config.Journal.Depth
+	if depth <= 0 || (len(before) == 0 && len(after) == 0) {
+		return nil
+	}
+	if _, err := tx.Exec("DELETE FROM oplog WHERE undone = 1"); err != nil {
+		return fmt.Errorf("journal: %w", err)
+	}
+	q := "INSERT INTO oplog (op, desc, before_image, after_image) VALUES (?, ?, ?, ?)"
+	if _, err := tx.Exec(q, op, journalDesc(before, after), Image(before), Image(after)); err != nil {
+		return fmt.Errorf("journal: %w", err)
+	}
+	q = "DELETE FROM oplog WHERE id NOT IN (SELECT id FROM oplog ORDER BY id DESC LIMIT ?)"
+	if _, err := tx.Exec(q, depth); err != nil {
+		return fmt.Errorf("journal: pruning: %w", err)
+	}
+
+	return nil
+}
+
+// journalDesc describes the records of the operation, the URL for a single
+// record.
+func journalDesc(before, after []Row) string {
+	rows := after
+	if len(rows) == 0 {
+		rows = before
+	}
+	if len(rows) == 1 {
+		return rows[0].URL
+	}
+
+	return fmt.Sprintf("%d bookmarks", len(rows))
+}
+
+// restore replaces the records of the from image with the ones of the to
+// image.
+//
+// The records are matched by ID, a record in both images keeps its current
+// ID, since the IDs can be reordered after the operation. A restored record
+// takes its old ID back, making room for it if the ID is used.
+func (r *SQLiteRepository) restore(tx *sqlx.Tx, from, to []Row) error {
+	cur := make(map[int]int, len(from))
+	for _, b := range from {
+		var id int
+		if err := tx.Get(&id, "SELECT id FROM bookmarks WHERE url = ?", b.URL); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return fmt.Errorf("%w: %q not found", ErrOplogConflict, b.URL)
+			}
+
+			return fmt.Errorf("%w", err)
+		}
+		cur[b.ID] = id
+		// removing the tags relationships removes the record, see the cleanup
+		// trigger.
+		if _, err := tx.Exec("DELETE FROM bookmark_tags WHERE bookmark_url = ?", b.URL); err != nil {
+			return fmt.Errorf("restoring: %w: %q", err, b.URL)
+		}
+	}
+	to = append([]Row(nil), to...)
+	sort.Slice(to, func(i, j int) bool {
+		return to[i].ID < to[j].ID
+	})
+	renames := make(map[string]string)
+	for _, b := range to {
+		if id, ok := cur[b.ID]; ok {
+			for _, o := range from {
+				if o.ID == b.ID && o.URL != b.URL {
+					renames[o.URL] = b.URL
+				}
+			}
+			b.ID = id
+		} else if err := makeRoomTx(tx, b.ID); err != nil {
+			return err
+		}
+		exists, err := r.hasTx(tx, b.URL)
+		if err != nil {
+			return err
+		}
+		if exists {
+			return fmt.Errorf("%w: %q exists", ErrOplogConflict, b.URL)
+		}
+		b.Tags = bookmark.ParseTags(b.Tags)
+		if err := r.insertAtID(tx, &b); err != nil {
+			return fmt.Errorf("restoring: %w", err)
+		}
+	}
+	for o, n := range renames {
+		if err := renameURL(tx, o, n); err != nil {
+			return err
+		}
+	}
+
+	return pruneOrphans(tx)
+}
+
+// makeRoomTx shifts the IDs of the records from the given ID, if it is
+// used.
+func makeRoomTx(tx *sqlx.Tx, id int) error {
+	var used bool
+	if err := tx.Get(&used, "SELECT EXISTS(SELECT 1 FROM bookmarks WHERE id = ?)", id); err != nil {
+		return fmt.Errorf("%w", err)
+	}
+	if !used {
+		return nil
+	}
+	// negate first, the IDs are unique at every step of the update.
+	if _, err := tx.Exec("UPDATE bookmarks SET id = -(id + 1) WHERE id >= ?", id); err != nil {
+		return fmt.Errorf("shifting IDs: %w", err)
+	}
+	if _, err := tx.Exec("UPDATE bookmarks SET id = -id WHERE id < 0"); err != nil {
+		return fmt.Errorf("shifting IDs: %w", err)
+	}
+
+	return nil
+}
+
+// rowsTx returns the records with the given URLs inside a transaction,
+// sorted by ID.
+func rowsTx(tx *sqlx.Tx, urls []string) ([]Row, error) {
+	q := `
+    SELECT
+      b.*,
+      COALESCE(GROUP_CONCAT(t.name, ','), '') AS tags
+    FROM
+      bookmarks b
+      LEFT JOIN bookmark_tags bt ON b.url = bt.bookmark_url
+      LEFT JOIN tags t ON bt.tag_id = t.id
+    WHERE
+      b.url IN (?)
+    GROUP BY
+      b.id`
+	rows := make([]Row, 0, len(urls))
+	for i := 0; i < len(urls); i += rowsChunk {
+		chunk := urls[i:min(i+rowsChunk, len(urls))]
+		query, args, err := sqlx.In(q, chunk)
+		if err != nil {
+			return nil, fmt.Errorf("%w", err)
+		}
+		var bb []Row
+		if err := tx.Select(&bb, tx.Rebind(query), args...); err != nil {
+			return nil, fmt.Errorf("%w", err)
+		}
+		rows = append(rows, bb...)
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		return rows[i].ID < rows[j].ID
+	})
+	for i := range rows {
+		rows[i].Tags = bookmark.ParseTags(rows[i].Tags)
+	}
+
+	return rows, nil
+}
+
+// urlsOf returns the URLs of the records.
+func urlsOf(bs ...*Row) []string {
+	urls := make([]string, 0, len(bs))
+	for _, b := range bs {
+		urls = append(urls, b.URL)
+	}
+
+	return urls
+}
+
+// sliceURLs returns the URLs of the records in the slice.
+func sliceURLs(bs *Slice) []string {
+	urls := make([]string, 0, bs.Len())
+	bs.ForEach(func(b Row) {
+		urls = append(urls, b.URL)
+	})
+
+	return urls
+}
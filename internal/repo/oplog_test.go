@@ -0,0 +1,141 @@
+package repo
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/haaag/gm/internal/config"
+	"github.com/haaag/gm/internal/slice"
+)
+
+// testURLs returns the URLs of all the records, by ID.
+func testURLs(t *testing.T, r *SQLiteRepository) map[int]string {
+	t.Helper()
+	bs := slice.New[Row]()
+	_ = r.All(bs)
+	m := make(map[int]string, bs.Len())
+	bs.ForEach(func(b Row) {
+		m[b.ID] = b.URL
+	})
+
+	return m
+}
+
+func TestUndoRedo(t *testing.T) {
+	r := setupTestDB(t)
+	defer teardownthewall(r.DB)
+	ctx := context.Background()
+	assert.NoError(t, r.InsertMany(ctx, testSliceBookmarks(5)))
+	original := testURLs(t, r)
+
+	// update
+	old, err := r.ByID(2)
+	assert.NoError(t, err)
+	updated := *old
+	updated.URL = "https://example.com/updated"
+	updated.Title = "Updated"
+	_, err = r.UpdateOne(ctx, &updated, old)
+	assert.NoError(t, err)
+	// remove, the IDs are reordered
+	rm, err := r.ByID(3)
+	assert.NoError(t, err)
+	bs := slice.New[Row]()
+	bs.Push(rm)
+	assert.NoError(t, r.DeleteMany(ctx, bs))
+	assert.NoError(t, r.ReorderIDs(ctx))
+	assert.Len(t, testURLs(t, r), 4)
+
+	es, err := r.Oplog(0)
+	assert.NoError(t, err)
+	assert.Len(t, es, 3)
+	assert.Equal(t, []string{OpRemove, OpUpdate, OpImport}, []string{es[0].Op, es[1].Op, es[2].Op})
+
+	// undo the removal, the record gets its ID back
+	e, err := r.Undo(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, OpRemove, e.Op)
+	got := testURLs(t, r)
+	assert.Equal(t, rm.URL, got[3])
+	assert.Equal(t, original[4], got[4])
+	// undo the update
+	_, err = r.Undo(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, original, testURLs(t, r))
+	b, err := r.ByID(2)
+	assert.NoError(t, err)
+	assert.Equal(t, old.Title, b.Title)
+	assert.Equal(t, old.Tags, b.Tags)
+
+	// redo both
+	_, err = r.Redo(ctx)
+	assert.NoError(t, err)
+	b, err = r.ByID(2)
+	assert.NoError(t, err)
+	assert.Equal(t, "Updated", b.Title)
+	_, err = r.Redo(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, testURLs(t, r), 4)
+	_, err = r.Redo(ctx)
+	assert.ErrorIs(t, err, ErrOplogNothingToRedo)
+
+	// a new operation discards the undone ones
+	_, err = r.Undo(ctx)
+	assert.NoError(t, err)
+	assert.NoError(t, r.InsertOne(ctx, testSingleBookmark()))
+	_, err = r.Redo(ctx)
+	assert.ErrorIs(t, err, ErrOplogNothingToRedo)
+
+	// undo everything
+	for range 3 {
+		_, err = r.Undo(ctx)
+		assert.NoError(t, err)
+	}
+	assert.Empty(t, testURLs(t, r))
+	_, err = r.Undo(ctx)
+	assert.ErrorIs(t, err, ErrOplogNothingToUndo)
+}
+
+func TestJournalDepth(t *testing.T) {
+	r := setupTestDB(t)
+	defer teardownthewall(r.DB)
+	depth := config.Journal.Depth
+	t.Cleanup(func() { config.Journal.Depth = depth })
+	ctx := context.Background()
+
+	config.Journal.Depth = 2
+	testSliceBookmarks(3).ForEach(func(b Row) {
+		assert.NoError(t, r.InsertOne(ctx, &b))
+	})
+	es, err := r.Oplog(0)
+	assert.NoError(t, err)
+	assert.Len(t, es, 2)
+	assert.Equal(t, "https://www.example2.com", es[0].Desc)
+
+	// disabled
+	config.Journal.Depth = 0
+	assert.NoError(t, r.InsertOne(ctx, testSingleBookmark()))
+	es, err = r.Oplog(0)
+	assert.NoError(t, err)
+	assert.Len(t, es, 2)
+}
+
+func TestUndoDrop(t *testing.T) {
+	r := setupTestDB(t)
+	defer teardownthewall(r.DB)
+	ctx := context.Background()
+	assert.NoError(t, r.InsertMany(ctx, testSliceBookmarks(3)))
+	original := testURLs(t, r)
+
+	assert.NoError(t, r.DropSecure(ctx))
+	assert.Empty(t, testURLs(t, r))
+	es, err := r.Oplog(0)
+	assert.NoError(t, err)
+	assert.Len(t, es, 2, "the journal survives the drop")
+	assert.Equal(t, OpRemove, es[0].Op)
+
+	_, err = r.Undo(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, original, testURLs(t, r))
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"os"
 	"path/filepath"
 	"time"
 
@@ -106,7 +107,7 @@ func DatabasesLocked(root string) ([]string, error) {
 
 // newBackup creates a new backup from the given repository.
 func newBackup(r *SQLiteRepository) (string, error) {
-	if err := files.MkdirAll(config.App.Path.Backup); err != nil {
+	if err := files.MkdirAll(r.Cfg.BackupDir); err != nil {
 		return "", fmt.Errorf("%w", err)
 	}
 	// destDSN -> 20060102-150405_dbName.db
@@ -116,18 +117,71 @@ func newBackup(r *SQLiteRepository) (string, error) {
 		"src", r.Cfg.Fullpath(),
 		"dest", destPath,
 	)
-	if files.Exists(destPath) {
+	ext, err := compressExt(config.Backup.Compress)
+	if err != nil {
+		return "", err
+	}
+	if files.Exists(destPath) || (ext != "" && files.Exists(destPath+ext)) {
 		return "", fmt.Errorf("%w: %q", ErrBackupExists, destPath)
 	}
-	_ = r.DB.MustExec("VACUUM INTO ?", destPath)
-	if err := verifySQLiteIntegrity(destPath); err != nil {
-		return "", err
+	var compression string
+	var dbSize int64
+	if r.mem != nil && r.mem.key != nil {
+		// the backup of an encrypted database is encrypted too.
+		ciphertext, n, err := r.mem.encrypted(context.Background())
+		if err != nil {
+			return "", err
+		}
+		if err := os.WriteFile(destPath, ciphertext, files.FilePerm); err != nil {
+			return "", fmt.Errorf("%w", err)
+		}
+		dbSize = int64(n)
+	} else {
+		_ = r.DB.MustExec("VACUUM INTO ?", destPath)
+		if err := verifySQLiteIntegrity(destPath); err != nil {
+			return "", err
+		}
+		image, err := os.ReadFile(destPath)
+		if err != nil {
+			return "", fmt.Errorf("%w", err)
+		}
+		dbSize = int64(len(image))
+		if ext != "" {
+			compression = config.Backup.Compress
+			if err := compressBackup(destPath, destPath+ext, image, compression); err != nil {
+				return "", err
+			}
+			destDSN, destPath = destDSN+ext, destPath+ext
+		}
+	}
+	if err := recordBackup(r, destPath, compression, dbSize); err != nil {
+		slog.Warn("recording backup in manifest", "path", destPath, "error", err)
 	}
 
 	return destDSN, nil
 }
 
+// compressBackup writes the compressed image of the backup at src to dest,
+// removing src.
+func compressBackup(src, dest string, image []byte, format string) error {
+	data, err := compress(image, format)
+	if err != nil {
+		_ = os.Remove(src)
+		return err
+	}
+	if err := os.WriteFile(dest, data, files.FilePerm); err != nil {
+		_ = os.Remove(src)
+		return fmt.Errorf("%w", err)
+	}
+	slog.Debug("backup compressed", "path", dest, "format", format, "size", len(data), "db_size", len(image))
+
+	return os.Remove(src)
+}
+
 // ListDatabaseBackups returns a filtered list of database backups.
+//
+// Only the backups of the exact database name are listed, not the ones of
+// other databases ending with it nor temporary files.
 func ListDatabaseBackups(dir, dbName string) ([]string, error) {
 	// Remove .db|.enc extension for matching
 	baseName := format.StripSuffixes(dbName)
@@ -135,8 +189,14 @@ func ListDatabaseBackups(dir, dbName string) ([]string, error) {
 	if err != nil {
 		return nil, fmt.Errorf("listing backups: %w", err)
 	}
+	backups := make([]string, 0, len(entries))
+	for _, p := range entries {
+		if isBackupOf(p, baseName) {
+			backups = append(backups, p)
+		}
+	}
 
-	return entries, nil
+	return backups, nil
 }
 
 // Backups returns a filtered list of backup paths and an error if any.
@@ -172,6 +232,11 @@ func verifySQLiteIntegrity(path string) error {
 		}
 	}()
 
+	return integrityCheck(db)
+}
+
+// integrityCheck runs the SQLite integrity check on the database.
+func integrityCheck(db *sqlx.DB) error {
 	var result string
 	row := db.QueryRow("PRAGMA integrity_check;")
 	if err := row.Scan(&result); err != nil {
@@ -190,7 +255,7 @@ func verifySQLiteIntegrity(path string) error {
 // isInit returns true if the database is initialized.
 func isInit(r *SQLiteRepository) bool {
 	allExist := true
-	for _, s := range tablesAndSchema() {
+	for _, s := range coreTables() {
 		exists, err := r.tableExists(s.name)
 		if err != nil {
 			slog.Error("checking if table exists", "name", s.name, "error", err)
@@ -213,7 +278,7 @@ func IsInitialized(p string) (bool, error) {
 	if err != nil {
 		return false, err
 	}
-	for _, s := range tablesAndSchema() {
+	for _, s := range coreTables() {
 		exists, err := r.tableExists(s.name)
 		if err != nil {
 			slog.Error("checking if table exists", "name", s.name, "error", err)
@@ -229,19 +294,39 @@ func IsInitialized(p string) (bool, error) {
 }
 
 // Drop removes all records database.
+//
+// The journal and the history are kept, the drop is recorded as a removal
+// of all the records so it can be undone.
 func Drop(r *SQLiteRepository, ctx context.Context) error {
+	r.snapshot("drop")
 	tts := tablesAndSchema()
 	tables := make([]Table, 0, len(tts))
 	for _, t := range tts {
+		if t.name == schemaOplog.name || t.name == schemaHistory.name {
+			continue
+		}
 		tables = append(tables, t.name)
 	}
 
 	err := r.withTx(ctx, func(tx *sqlx.Tx) error {
-		if err := r.deleteAll(ctx, tables...); err != nil {
+		var urls []string
+		if err := tx.Select(&urls, "SELECT url FROM bookmarks"); err != nil {
 			return fmt.Errorf("%w", err)
 		}
+		before, err := rowsTx(tx, urls)
+		if err != nil {
+			return err
+		}
+		for _, t := range tables {
+			if _, err := tx.Exec(fmt.Sprintf("DELETE FROM %s", t)); err != nil {
+				return fmt.Errorf("%w", err)
+			}
+		}
+		if err := resetSQLiteSequence(tx, tables...); err != nil {
+			return err
+		}
 
-		return resetSQLiteSequence(tx, tables...)
+		return record(ctx, tx, OpRemove, before, nil)
 	})
 	if err != nil {
 		return fmt.Errorf("%w", err)
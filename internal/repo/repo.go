@@ -5,12 +5,14 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"os"
 	"path/filepath"
 	"sync"
 
 	"github.com/jmoiron/sqlx"
 	_ "github.com/mattn/go-sqlite3"
 
+	"github.com/haaag/gm/internal/config"
 	"github.com/haaag/gm/internal/sys/files"
 )
 
@@ -26,6 +28,7 @@ type SQLiteRepository struct {
 	DB        *sqlx.DB   `json:"-"`
 	Cfg       *SQLiteCfg `json:"db"`
 	closeOnce sync.Once
+	mem       *memDB // encrypted database opened in memory
 }
 
 // Name returns the name of the SQLite database.
@@ -34,9 +37,18 @@ func (r *SQLiteRepository) Name() string {
 }
 
 // Close closes the SQLite database connection and logs any errors encountered.
+//
+// The changes of an encrypted database are encrypted back to its file.
 func (r *SQLiteRepository) Close() {
 	s := r.Name()
 	r.closeOnce.Do(func() {
+		if r.mem != nil {
+			if err := r.mem.seal(context.Background()); err != nil {
+				slog.Error("saving encrypted database", "name", s, "error", err)
+				fmt.Fprintf(os.Stderr, "%s: %v\n", config.App.Name, err)
+			}
+			_ = r.mem.conn.Close()
+		}
 		if err := r.DB.Close(); err != nil {
 			slog.Error("closing database", "name", s, "error", err)
 		} else {
@@ -52,8 +64,22 @@ func (r *SQLiteRepository) BackupsList() ([]string, error) {
 
 // Backup creates a backup of the SQLite database and returns the path to the
 // backup filepath.
+//
+// Once a retention policy is set, the backups it does not keep are removed.
 func (r *SQLiteRepository) Backup() (string, error) {
-	return newBackup(r)
+	s, err := newBackup(r)
+	if err != nil {
+		return "", err
+	}
+	p := RetentionFromConfig()
+	if !p.Enabled() {
+		return s, nil
+	}
+	if _, err := r.Prune(p, false); err != nil {
+		slog.Warn("pruning backups", "error", err)
+	}
+
+	return s, nil
 }
 
 // IsInitialized returns true if the database is initialized.
@@ -70,19 +96,50 @@ func newSQLiteRepository(db *sqlx.DB, cfg *SQLiteCfg) *SQLiteRepository {
 }
 
 // New returns a new SQLiteRepository from an existing database path.
+//
+// Pending schema migrations are applied to initialized databases.
+//
+// Encrypted databases, with the .enc extension, are decrypted in memory.
 func New(p string) (*SQLiteRepository, error) {
-	return newRepository(p, func(path string) error {
-		slog.Debug("new repo: checking if database exists", "path", path)
-		if !files.Exists(path) {
-			return fmt.Errorf("%w: %q", ErrDBNotFound, path)
-		}
+	var (
+		r   *SQLiteRepository
+		err error
+	)
+	if IsEncrypted(p) {
+		r, err = newEncrypted(p)
+	} else {
+		r, err = newRepository(p, validateExists)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !r.IsInitialized() {
+		return r, nil
+	}
+	if err := r.Migrate(); err != nil {
+		r.Close()
+		return nil, fmt.Errorf("%w", err)
+	}
 
-		return nil
-	})
+	return r, nil
+}
+
+// validateExists checks that the database exists.
+func validateExists(path string) error {
+	slog.Debug("new repo: checking if database exists", "path", path)
+	if !files.Exists(path) {
+		return fmt.Errorf("%w: %q", ErrDBNotFound, path)
+	}
+
+	return nil
 }
 
 // Init initializes a new SQLiteRepository at the provided path.
 func Init(p string) (*SQLiteRepository, error) {
+	if IsEncrypted(p) {
+		return nil, fmt.Errorf("%w: %q", ErrDBEncryptedInit, filepath.Base(p))
+	}
+
 	return newRepository(p, func(path string) error {
 		slog.Debug("init repo: checking if database exists", "path", path)
 		if files.Exists(path) {
@@ -0,0 +1,167 @@
+package repo
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"github.com/haaag/gm/internal/bookmark"
+	"github.com/haaag/gm/internal/locker"
+	"github.com/haaag/gm/internal/slice"
+)
+
+// sqliteMagic is the start of every SQLite database file.
+var sqliteMagic = []byte("SQLite format 3\x00")
+
+// RecordDiff is a record found in both databases, with different fields.
+type RecordDiff struct {
+	URL     string                 `json:"url"`
+	Changes []bookmark.FieldChange `json:"changes"`
+}
+
+// DBDiff holds the differences of the records of a database with a backup,
+// from the database to the backup.
+type DBDiff struct {
+	Added   []Row        `json:"added"`   // only in the backup
+	Removed []Row        `json:"removed"` // only in the database
+	Changed []RecordDiff `json:"changed"` // in both, with changes
+}
+
+// Empty reports whether the records are the same.
+func (d *DBDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// DiffDB compares the records of the database with the ones of the backup,
+// matching them by URL.
+func DiffDB(r, bk *SQLiteRepository) (*DBDiff, error) {
+	cur, err := allRows(r)
+	if err != nil {
+		return nil, err
+	}
+	old, err := allRows(bk)
+	if err != nil {
+		return nil, err
+	}
+	byURL := make(map[string]*Row, len(cur))
+	for i := range cur {
+		byURL[cur[i].URL] = &cur[i]
+	}
+	d := &DBDiff{}
+	for i := range old {
+		b := &old[i]
+		a, ok := byURL[b.URL]
+		if !ok {
+			d.Added = append(d.Added, *b)
+			continue
+		}
+		delete(byURL, b.URL)
+		// tags are grouped in no particular order.
+		ta, tb := a.Tags, b.Tags
+		a.Tags, b.Tags = bookmark.ParseTags(ta), bookmark.ParseTags(tb)
+		if changes := bookmark.Diff(a, b); len(changes) > 0 {
+			d.Changed = append(d.Changed, RecordDiff{URL: b.URL, Changes: changes})
+		}
+		a.Tags, b.Tags = ta, tb
+	}
+	for i := range cur {
+		if _, ok := byURL[cur[i].URL]; ok {
+			d.Removed = append(d.Removed, cur[i])
+		}
+	}
+
+	return d, nil
+}
+
+// allRows returns all the records of the database, none if it is empty.
+func allRows(r *SQLiteRepository) ([]Row, error) {
+	bs := slice.New[Row]()
+	if err := r.All(bs); err != nil && !errors.Is(err, ErrRecordNotFound) {
+		return nil, fmt.Errorf("%w: %q", err, r.Name())
+	}
+
+	return *bs.Items(), nil
+}
+
+// ReadBackup returns the database image of the backup, decrypting and
+// decompressing it.
+func ReadBackup(p string) ([]byte, error) {
+	var data []byte
+	var err error
+	if IsEncrypted(p) {
+		_, _, data, err = decryptFile(p)
+	} else {
+		data, err = os.ReadFile(p)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w", err)
+	}
+
+	return decompress(data, compressFormat(p))
+}
+
+// OpenBackup opens the image of the backup at p in memory. The backup file
+// is never changed, pending migrations apply only in memory.
+func OpenBackup(p string, image []byte) (*SQLiteRepository, error) {
+	if !bytes.HasPrefix(image, sqliteMagic) {
+		return nil, fmt.Errorf("%w: %q", ErrDBCorrupted, filepath.Base(p))
+	}
+	dir := filepath.Dir(p)
+	c := &SQLiteCfg{
+		Path:       dir,
+		Name:       filepath.Base(p),
+		BackupDir:  dir,
+		DateFormat: defaultDateFormat,
+	}
+	bk, err := openImage(c, image)
+	if err != nil {
+		return nil, err
+	}
+	if !bk.IsInitialized() {
+		bk.Close()
+		return nil, fmt.Errorf("%w: %q", ErrDBNotInitialized, filepath.Base(p))
+	}
+	if err := bk.Migrate(); err != nil {
+		bk.Close()
+		return nil, fmt.Errorf("%w", err)
+	}
+
+	return bk, nil
+}
+
+// Restore replaces the database with the image of a backup, returning the
+// backup of its current state taken first.
+//
+// The file of a plain database is replaced atomically, an encrypted
+// database loads the image and encrypts it back to its file with its key.
+// The repository is closed.
+func (r *SQLiteRepository) Restore(image []byte) (string, error) {
+	defer r.Close()
+	if !bytes.HasPrefix(image, sqliteMagic) {
+		return "", fmt.Errorf("%w: not a database image", ErrDBCorrupted)
+	}
+	safety, err := newBackup(r)
+	if err != nil {
+		return "", fmt.Errorf("safety backup: %w", err)
+	}
+	slog.Info("restoring database", "name", r.Name(), "safety", safety)
+	if r.mem != nil {
+		ctx := context.Background()
+		if err := loadImage(ctx, r.mem.conn, image); err != nil {
+			return safety, err
+		}
+
+		return safety, r.mem.seal(ctx)
+	}
+	// the open connections would keep writing to the replaced file.
+	r.Close()
+	if err := locker.Replace(r.Cfg.Fullpath(), image); err != nil {
+		return safety, fmt.Errorf("%w", err)
+	}
+
+	return safety, nil
+}
@@ -0,0 +1,124 @@
+package repo
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/haaag/gm/internal/config"
+	"github.com/haaag/gm/internal/sys/files"
+)
+
+// testOldBackup creates a backup of the database, dated an hour ago to leave
+// room for the safety backup of the restore.
+func testOldBackup(t *testing.T, r *SQLiteRepository) string {
+	t.Helper()
+	name, err := newBackup(r)
+	assert.NoError(t, err)
+	_, rest, _ := strings.Cut(name, "_")
+	old := filepath.Join(r.Cfg.BackupDir, time.Now().Add(-time.Hour).Format(defaultDateFormat)+"_"+rest)
+	assert.NoError(t, os.Rename(filepath.Join(r.Cfg.BackupDir, name), old))
+
+	return old
+}
+
+// testChangeRecords removes, adds and updates records of the database.
+func testChangeRecords(t *testing.T, r *SQLiteRepository) {
+	t.Helper()
+	ctx := context.Background()
+	assert.NoError(t, r.DeleteOne(ctx, "https://www.example0.com"))
+	b := testSingleBookmark()
+	b.URL = "https://example.com/new"
+	assert.NoError(t, r.InsertOne(ctx, b))
+	old, err := r.ByURL("https://www.example1.com")
+	assert.NoError(t, err)
+	nb := *old
+	nb.Title = "Changed"
+	_, err = r.UpdateOne(ctx, &nb, old)
+	assert.NoError(t, err)
+}
+
+//nolint:paralleltest //modifies the backup config
+func TestRestore(t *testing.T) {
+	auto := config.Backup.Auto
+	t.Cleanup(func() { config.Backup.Auto = auto })
+	config.Backup.Auto = false
+
+	p := filepath.Join(t.TempDir(), "bookmarks.db")
+	r, err := Init(p)
+	assert.NoError(t, err)
+	assert.NoError(t, r.Init())
+	assert.NoError(t, r.InsertMany(context.Background(), testSliceBookmarks(3)))
+	bkPath := testOldBackup(t, r)
+	// a newer backup of another database is never restored
+	other := filepath.Join(r.Cfg.BackupDir, time.Now().Format(defaultDateFormat)+"_my_bookmarks.db")
+	assert.NoError(t, os.WriteFile(other, []byte("bk"), files.FilePerm))
+	last, err := r.LastBackup()
+	assert.NoError(t, err)
+	assert.Equal(t, bkPath, last)
+	testChangeRecords(t, r)
+
+	image, err := ReadBackup(bkPath)
+	assert.NoError(t, err)
+	bk, err := OpenBackup(bkPath, image)
+	assert.NoError(t, err)
+	d, err := DiffDB(r, bk)
+	bk.Close()
+	assert.NoError(t, err)
+	assert.Len(t, d.Added, 1)
+	assert.Equal(t, "https://www.example0.com", d.Added[0].URL)
+	assert.Len(t, d.Removed, 1)
+	assert.Equal(t, "https://example.com/new", d.Removed[0].URL)
+	assert.Len(t, d.Changed, 1)
+	assert.Equal(t, "title", d.Changed[0].Changes[0].Field)
+	assert.Equal(t, "Changed", d.Changed[0].Changes[0].Old)
+
+	safety, err := r.Restore(image)
+	assert.NoError(t, err)
+	assert.FileExists(t, filepath.Join(r.Cfg.BackupDir, safety))
+	r, err = New(p)
+	assert.NoError(t, err)
+	defer r.Close()
+	assert.Equal(t, 3, CountMainRecords(r))
+	_, ok := r.Has("https://example.com/new")
+	assert.False(t, ok)
+
+	_, err = r.Restore([]byte("not a database"))
+	assert.ErrorIs(t, err, ErrDBCorrupted)
+}
+
+//nolint:paralleltest //sets the key function
+func TestRestoreEncrypted(t *testing.T) {
+	auto := config.Backup.Auto
+	t.Cleanup(func() { config.Backup.Auto = auto })
+	config.Backup.Auto = false
+
+	p := testEncryptedDB(t, "123456")
+	r, err := New(p)
+	assert.NoError(t, err)
+	assert.NoError(t, r.InsertMany(context.Background(), testSliceBookmarks(3)))
+	// the backup of an encrypted database is locked
+	bkPath := testOldBackup(t, r)
+	testChangeRecords(t, r)
+	r.Close()
+
+	r, err = New(p)
+	assert.NoError(t, err)
+	image, err := ReadBackup(bkPath)
+	assert.NoError(t, err)
+	safety, err := r.Restore(image)
+	assert.NoError(t, err)
+	assert.True(t, IsEncrypted(safety))
+
+	r, err = New(p)
+	assert.NoError(t, err)
+	defer r.Close()
+	assert.Equal(t, 3, CountMainRecords(r))
+	_, ok := r.Has("https://www.example0.com")
+	assert.True(t, ok)
+}
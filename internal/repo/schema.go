@@ -6,6 +6,11 @@ const (
 	tableTagsName     = "tags"
 	tableRelationName = "bookmark_tags"
 	tableTempName     = "temp_bookmarks"
+	tableContentName  = "bookmark_content"
+	tableCollName     = "collections"
+	tableCollItemName = "collection_items"
+	tableOplogName    = "oplog"
+	tableHistoryName  = "history"
 )
 
 // schemaMain is the schema for the main table.
@@ -30,6 +35,40 @@ var schemaRelation = tableSchema{
 	trigger: tableRelationTriggerCleanup,
 }
 
+// schemaContent is the full-text index of the bookmarked pages content.
+var schemaContent = tableSchema{
+	name: tableContentName,
+	sql:  tableContentSchema,
+}
+
+// schemaCollections holds the named, ordered lists of bookmarks.
+var schemaCollections = tableSchema{
+	name:  tableCollName,
+	sql:   tableCollSchema,
+	index: tableCollIndex,
+}
+
+// schemaCollectionItems holds the bookmarks of each collection and their
+// position.
+var schemaCollectionItems = tableSchema{
+	name:  tableCollItemName,
+	sql:   tableCollItemSchema,
+	index: tableCollItemIndex,
+}
+
+// schemaOplog is the journal of the operations, used to undo and redo them.
+var schemaOplog = tableSchema{
+	name: tableOplogName,
+	sql:  tableOplogSchema,
+}
+
+// schemaHistory holds the revisions of each bookmark.
+var schemaHistory = tableSchema{
+	name:  tableHistoryName,
+	sql:   tableHistorySchema,
+	index: tableHistoryIndex,
+}
+
 // schemaTemp is used for reordering the IDs in the main table.
 var schemaTemp = tableSchema{
 	name:    tableTempName,
@@ -52,7 +91,15 @@ const (
         last_visit  TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
         updated_at  TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
         visit_count INTEGER DEFAULT 0,
-        favorite    BOOLEAN DEFAULT FALSE
+        favorite    BOOLEAN DEFAULT FALSE,
+        archive_url TEXT    DEFAULT "",
+        archive_hash TEXT   DEFAULT "",
+        canonical_url TEXT  DEFAULT "",
+        meta        TEXT    DEFAULT "",
+        state       TEXT    DEFAULT "",
+        state_at    TEXT    DEFAULT "",
+        remind_at   TEXT    DEFAULT "",
+        notes       TEXT    DEFAULT ""
     );`
 
 	tableMainIndex = `
@@ -74,7 +121,15 @@ const (
         last_visit  TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
         updated_at  TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
         visit_count INTEGER DEFAULT 0,
-        favorite    BOOLEAN DEFAULT FALSE
+        favorite    BOOLEAN DEFAULT FALSE,
+        archive_url TEXT    DEFAULT "",
+        archive_hash TEXT   DEFAULT "",
+        canonical_url TEXT  DEFAULT "",
+        meta        TEXT    DEFAULT "",
+        state       TEXT    DEFAULT "",
+        state_at    TEXT    DEFAULT "",
+        remind_at   TEXT    DEFAULT "",
+        notes       TEXT    DEFAULT ""
     );`
 )
 
@@ -124,3 +179,85 @@ const (
       );
   END;`
 )
+
+// content table.
+const (
+	// tableContentSchema is an FTS4 table, linked to the bookmark by URL.
+	//
+	// the checksum of the indexed text is used to skip unchanged pages when
+	// re-indexing.
+	tableContentSchema = `
+    CREATE VIRTUAL TABLE IF NOT EXISTS bookmark_content USING fts4(
+        bookmark_url,
+        checksum,
+        content,
+        notindexed=bookmark_url,
+        notindexed=checksum,
+        tokenize=unicode61 "remove_diacritics=1"
+    );`
+)
+
+// collections tables.
+const (
+	tableCollSchema = `
+    CREATE TABLE IF NOT EXISTS collections (
+        id          INTEGER PRIMARY KEY AUTOINCREMENT,
+        name        TEXT    NOT NULL UNIQUE,
+        desc        TEXT    DEFAULT "",
+        created_at  TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+    );`
+
+	tableCollIndex = `
+    CREATE UNIQUE INDEX IF NOT EXISTS idx_collections_name
+    ON collections(name);`
+
+	// tableCollItemSchema links the bookmarks by URL, like the content
+	// table, so the items survive the bookmark being rewritten on update.
+	tableCollItemSchema = `
+    CREATE TABLE IF NOT EXISTS collection_items (
+        collection_id INTEGER NOT NULL,
+        bookmark_url  TEXT    NOT NULL,
+        position      INTEGER NOT NULL,
+        FOREIGN KEY (collection_id) REFERENCES collections(id) ON DELETE CASCADE,
+        PRIMARY KEY (collection_id, bookmark_url)
+    );`
+
+	tableCollItemIndex = `
+    CREATE INDEX IF NOT EXISTS idx_collection_items_position
+    ON collection_items(collection_id, position);`
+)
+
+// oplog table.
+const (
+	// tableOplogSchema stores the records before and after each operation as
+	// JSON arrays.
+	tableOplogSchema = `
+    CREATE TABLE IF NOT EXISTS oplog (
+        id            INTEGER PRIMARY KEY AUTOINCREMENT,
+        op            TEXT    NOT NULL,
+        desc          TEXT    DEFAULT "",
+        before_image  TEXT    DEFAULT "[]",
+        after_image   TEXT    DEFAULT "[]",
+        undone        INTEGER DEFAULT 0,
+        created_at    TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+    );`
+)
+
+// history table.
+const (
+	// tableHistorySchema links the revisions by URL, they are kept after the
+	// bookmark is removed.
+	tableHistorySchema = `
+    CREATE TABLE IF NOT EXISTS history (
+        id            INTEGER PRIMARY KEY AUTOINCREMENT,
+        bookmark_url  TEXT    NOT NULL,
+        op            TEXT    NOT NULL,
+        source        TEXT    DEFAULT "",
+        changes       TEXT    DEFAULT "[]",
+        created_at    TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+    );`
+
+	tableHistoryIndex = `
+    CREATE INDEX IF NOT EXISTS idx_history_url
+    ON history(bookmark_url, id);`
+)
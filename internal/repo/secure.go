@@ -0,0 +1,317 @@
+package repo
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+
+	"github.com/haaag/gm/internal/locker"
+	"github.com/haaag/gm/internal/sys/files"
+)
+
+const (
+	// lockWait is how long to wait for another process writing the encrypted
+	// database.
+	lockWait = 5 * time.Second
+
+	// lockStale is the age of a lock file left by a crashed process.
+	lockStale = time.Minute
+)
+
+// memSeq numbers the in-memory databases of the process.
+var memSeq atomic.Int64
+
+// KeyFn returns the key of the encrypted database at path, with the given
+// content.
+type KeyFn func(path string, ciphertext []byte) (*locker.Key, error)
+
+// keyFn returns the key of an encrypted database.
+var keyFn KeyFn
+
+// SetKeyFn sets the function that returns the key of the encrypted
+// databases, usually prompting the user for the passphrase.
+func SetKeyFn(fn KeyFn) {
+	keyFn = fn
+}
+
+// IsEncrypted reports whether the path is an encrypted database.
+func IsEncrypted(p string) bool {
+	return strings.HasSuffix(p, ".enc")
+}
+
+// memDB is an encrypted database opened in memory.
+type memDB struct {
+	path  string      // path to the .enc file
+	key   *locker.Key // key of the file, nil for read-only images
+	sum   [32]byte    // checksum of the .enc file, when loaded or saved
+	image [32]byte    // checksum of the database, when loaded or saved
+	conn  *sql.Conn   // keeps the in-memory database alive
+	// readOnly is set when the changes could not be encrypted back, like an
+	// age file without recipients. Writes are refused.
+	readOnly bool
+}
+
+// newEncrypted decrypts the database at p into an in-memory database, the
+// plaintext is never written to disk. The changes are encrypted back to the
+// file on Close.
+func newEncrypted(p string) (*SQLiteRepository, error) {
+	if !files.Exists(p) {
+		return nil, fmt.Errorf("%w: %q", ErrDBLockedNotFound, filepath.Base(p))
+	}
+	key, ciphertext, plaintext, err := decryptFile(p)
+	if err != nil {
+		return nil, err
+	}
+	c, err := NewSQLiteCfg(p)
+	if err != nil {
+		return nil, fmt.Errorf("%w", err)
+	}
+	r, err := openImage(c, plaintext)
+	if err != nil {
+		return nil, err
+	}
+	image, err := serialize(context.Background(), r.mem.conn)
+	if err != nil {
+		r.Close()
+		return nil, err
+	}
+	slog.Debug("encrypted database opened in memory", "path", p)
+	r.mem.path = p
+	r.mem.key = key
+	r.mem.sum = sha256.Sum256(ciphertext)
+	r.mem.image = sha256.Sum256(image)
+	if !key.CanEncrypt() {
+		r.mem.readOnly = true
+		slog.Warn("no recipients to encrypt the changes to, opened read-only", "path", p)
+	}
+
+	return r, nil
+}
+
+// decryptFile reads and decrypts the encrypted file, with the key returned
+// by the key function.
+func decryptFile(p string) (key *locker.Key, ciphertext, plaintext []byte, err error) {
+	if keyFn == nil {
+		return nil, nil, nil, fmt.Errorf("%w: %q", ErrDBUnlockFirst, filepath.Base(p))
+	}
+	ciphertext, err = locker.ReadFile(p)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("%w", err)
+	}
+	key, err = keyFn(p, ciphertext)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("%w", err)
+	}
+	plaintext, err = key.Decrypt(ciphertext)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("%w", err)
+	}
+
+	return key, ciphertext, plaintext, nil
+}
+
+// openImage opens the database image in memory. Without a key, set by
+// newEncrypted, the changes are discarded on Close.
+func openImage(c *SQLiteCfg, image []byte) (*SQLiteRepository, error) {
+	// a shared cache lets every connection of the pool see the database.
+	dsn := fmt.Sprintf("file:gm-%d-%d?mode=memory&cache=shared", os.Getpid(), memSeq.Add(1))
+	db, err := openDatabase(dsn)
+	if err != nil {
+		return nil, err
+	}
+	ctx := context.Background()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("%w", err)
+	}
+	if err := loadImage(ctx, conn, image); err != nil {
+		_ = conn.Close()
+		_ = db.Close()
+
+		return nil, err
+	}
+	r := newSQLiteRepository(db, c)
+	r.mem = &memDB{conn: conn}
+
+	return r, nil
+}
+
+// loadImage loads the database image into the connection.
+//
+// SQLite detaches a deserialized connection from the shared cache, the image
+// is deserialized into a private database and copied with the backup API.
+func loadImage(ctx context.Context, conn *sql.Conn, image []byte) error {
+	tmp, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+	defer tmp.Close()
+	tc, err := tmp.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+	defer tc.Close()
+
+	err = tc.Raw(func(src any) error {
+		sc, ok := src.(*sqlite3.SQLiteConn)
+		if !ok {
+			return fmt.Errorf("%w: unexpected driver %T", ErrDBCorrupted, src)
+		}
+		if err := sc.Deserialize(image, "main"); err != nil {
+			return fmt.Errorf("%w: %w", ErrDBCorrupted, err)
+		}
+
+		return conn.Raw(func(dest any) error {
+			dc, ok := dest.(*sqlite3.SQLiteConn)
+			if !ok {
+				return fmt.Errorf("%w: unexpected driver %T", ErrDBCorrupted, dest)
+			}
+			b, err := dc.Backup("main", sc, "main")
+			if err != nil {
+				return fmt.Errorf("%w", err)
+			}
+			if _, err := b.Step(-1); err != nil {
+				_ = b.Finish()
+				return fmt.Errorf("%w", err)
+			}
+
+			return b.Finish()
+		})
+	})
+	if err != nil {
+		return fmt.Errorf("loading database: %w", err)
+	}
+
+	return nil
+}
+
+// serialize returns the image of the database of the connection.
+func serialize(ctx context.Context, conn *sql.Conn) ([]byte, error) {
+	var image []byte
+	err := conn.Raw(func(dc any) error {
+		c, ok := dc.(*sqlite3.SQLiteConn)
+		if !ok {
+			return fmt.Errorf("%w: unexpected driver %T", ErrDBCorrupted, dc)
+		}
+		var err error
+		image, err = c.Serialize("main")
+
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("serializing database: %w", err)
+	}
+
+	return image, nil
+}
+
+// encrypted returns the database encrypted with its key, and the size of
+// the database image.
+func (m *memDB) encrypted(ctx context.Context) ([]byte, int, error) {
+	image, err := serialize(ctx, m.conn)
+	if err != nil {
+		return nil, 0, err
+	}
+	ciphertext, err := m.key.Encrypt(image)
+	if err != nil {
+		return nil, 0, fmt.Errorf("%w", err)
+	}
+
+	return ciphertext, len(image), nil
+}
+
+// seal encrypts the database back to its file, if it changed. The key is
+// kept, the file can be opened again with it. Images opened without a key
+// are never written.
+//
+// The file is replaced only if no other process changed it since it was
+// loaded. Otherwise the changes are saved next to it, and ErrDBConflict is
+// returned.
+func (m *memDB) seal(ctx context.Context) error {
+	if m.key == nil || m.readOnly {
+		return nil
+	}
+	image, err := serialize(ctx, m.conn)
+	if err != nil {
+		return err
+	}
+	if sha256.Sum256(image) == m.image {
+		slog.Debug("encrypted database unchanged", "path", m.path)
+		return nil
+	}
+	ciphertext, err := m.key.Encrypt(image)
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+	unlock, err := lockFile(m.path + ".lock")
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	cur, err := os.ReadFile(m.path)
+	if err != nil || sha256.Sum256(cur) != m.sum {
+		p := conflictPath(m.path)
+		if err := os.WriteFile(p, ciphertext, files.FilePerm); err != nil {
+			return fmt.Errorf("%w: saving changes: %w", ErrDBConflict, err)
+		}
+		// saved aside once, not again on Close.
+		m.image = sha256.Sum256(image)
+
+		return fmt.Errorf("%w: changes saved to %q", ErrDBConflict, filepath.Base(p))
+	}
+	if err := locker.Replace(m.path, ciphertext); err != nil {
+		return fmt.Errorf("%w", err)
+	}
+	m.sum, m.image = sha256.Sum256(ciphertext), sha256.Sum256(image)
+	slog.Debug("encrypted database saved", "path", m.path)
+
+	return nil
+}
+
+// conflictPath returns the path where the changes are saved on conflict,
+// like 'secrets.conflict-20060102-150405.db.enc'.
+func conflictPath(p string) string {
+	dir, name := filepath.Split(p)
+	base := strings.TrimSuffix(strings.TrimSuffix(name, ".enc"), ".db")
+	ts := time.Now().Format(defaultDateFormat)
+
+	return filepath.Join(dir, fmt.Sprintf("%s.conflict-%s.db.enc", base, ts))
+}
+
+// lockFile creates the lock file, waiting for other processes holding it.
+// A lock left by a crashed process is removed once stale.
+func lockFile(p string) (unlock func(), err error) {
+	deadline := time.Now().Add(lockWait)
+	for {
+		f, err := os.OpenFile(p, os.O_CREATE|os.O_EXCL|os.O_WRONLY, files.FilePerm)
+		if err == nil {
+			_ = f.Close()
+			return func() { _ = os.Remove(p) }, nil
+		}
+		if !errors.Is(err, fs.ErrExist) {
+			return nil, fmt.Errorf("%w", err)
+		}
+		if fi, err := os.Stat(p); err == nil && time.Since(fi.ModTime()) > lockStale {
+			slog.Warn("removing stale lock", "path", p)
+			_ = os.Remove(p)
+
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("%w: %q", ErrDBBusy, filepath.Base(p))
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
@@ -0,0 +1,159 @@
+package repo
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"filippo.io/age"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/haaag/gm/internal/locker"
+	"github.com/haaag/gm/internal/sys/files"
+)
+
+// testEncryptedDB creates an initialized encrypted database, returning its
+// path.
+func testEncryptedDB(t *testing.T, pass string) string {
+	t.Helper()
+	p := filepath.Join(t.TempDir(), "secrets.db")
+	r, err := Init(p)
+	assert.NoError(t, err)
+	assert.NoError(t, r.Init())
+	r.Close()
+	assert.NoError(t, locker.Lock(p, pass))
+	t.Cleanup(func() { SetKeyFn(nil) })
+	SetKeyFn(func(_ string, c []byte) (*locker.Key, error) {
+		return locker.DeriveKey(c, pass)
+	})
+
+	return p + ".enc"
+}
+
+//nolint:paralleltest //sets the key function
+func TestEncryptedInMemory(t *testing.T) {
+	p := testEncryptedDB(t, "123456")
+	before, err := os.ReadFile(p)
+	assert.NoError(t, err)
+
+	// reading leaves the file untouched
+	r, err := New(p)
+	assert.NoError(t, err)
+	assert.True(t, r.IsInitialized())
+	r.Close()
+	after, err := os.ReadFile(p)
+	assert.NoError(t, err)
+	assert.Equal(t, before, after)
+
+	// writing encrypts the changes back
+	r, err = New(p)
+	assert.NoError(t, err)
+	assert.NoError(t, r.InsertOne(context.Background(), testSingleBookmark()))
+	r.Close()
+	fs, err := os.ReadDir(filepath.Dir(p))
+	assert.NoError(t, err)
+	assert.Len(t, fs, 1, "only the encrypted file is left")
+	// the key stays valid
+	after, err = os.ReadFile(p)
+	assert.NoError(t, err)
+	id, err := locker.KeyID(before)
+	assert.NoError(t, err)
+	afterID, err := locker.KeyID(after)
+	assert.NoError(t, err)
+	assert.Equal(t, id, afterID)
+
+	r, err = New(p)
+	assert.NoError(t, err)
+	defer r.Close()
+	assert.Equal(t, 1, CountMainRecords(r))
+
+	SetKeyFn(func(_ string, c []byte) (*locker.Key, error) {
+		return locker.DeriveKey(c, "wrong")
+	})
+	_, err = New(p)
+	assert.ErrorIs(t, err, locker.ErrDecryption)
+}
+
+//nolint:paralleltest //sets the key function
+func TestEncryptedConflict(t *testing.T) {
+	p := testEncryptedDB(t, "123456")
+	ctx := context.Background()
+	r1, err := New(p)
+	assert.NoError(t, err)
+	r2, err := New(p)
+	assert.NoError(t, err)
+
+	// each committed write is encrypted back to the file, without Close
+	before, err := os.ReadFile(p)
+	assert.NoError(t, err)
+	assert.NoError(t, r1.InsertOne(ctx, testSingleBookmark()))
+	after, err := os.ReadFile(p)
+	assert.NoError(t, err)
+	assert.NotEqual(t, before, after)
+	b := testSingleBookmark()
+	b.URL = "https://example.com/other"
+	assert.ErrorIs(t, r2.InsertOne(ctx, b), ErrDBConflict)
+	r1.Close()
+	r2.Close()
+
+	// the first write wins, the second one is kept aside
+	cs, err := filepath.Glob(filepath.Join(filepath.Dir(p), "secrets.conflict-*.db.enc"))
+	assert.NoError(t, err)
+	assert.Len(t, cs, 1)
+	r, err := New(p)
+	assert.NoError(t, err)
+	defer r.Close()
+	_, ok := r.Has(b.URL)
+	assert.False(t, ok)
+}
+
+func TestLockFile(t *testing.T) {
+	t.Parallel()
+	p := filepath.Join(t.TempDir(), "secrets.db.enc.lock")
+	unlock, err := lockFile(p)
+	assert.NoError(t, err)
+	assert.True(t, files.Exists(p))
+	unlock()
+	assert.False(t, files.Exists(p))
+
+	// stale lock of a crashed process
+	assert.NoError(t, os.WriteFile(p, nil, files.FilePerm))
+	old := time.Now().Add(-2 * lockStale)
+	assert.NoError(t, os.Chtimes(p, old, old))
+	unlock, err = lockFile(p)
+	assert.NoError(t, err)
+	unlock()
+}
+
+//nolint:paralleltest //sets the key function
+func TestEncryptedReadOnly(t *testing.T) {
+	id, err := age.GenerateX25519Identity()
+	assert.NoError(t, err)
+	p := filepath.Join(t.TempDir(), "secrets.db")
+	r, err := Init(p)
+	assert.NoError(t, err)
+	assert.NoError(t, r.Init())
+	assert.NoError(t, r.InsertMany(context.Background(), testSliceBookmarks(3)))
+	r.Close()
+	assert.NoError(t, locker.LockKey(p, locker.NewAgeKey(nil, []age.Recipient{id.Recipient()})))
+	p += ".enc"
+	before, err := os.ReadFile(p)
+	assert.NoError(t, err)
+	// the identity decrypts, there are no recipients to encrypt to
+	t.Cleanup(func() { SetKeyFn(nil) })
+	SetKeyFn(func(_ string, _ []byte) (*locker.Key, error) {
+		return locker.NewAgeKey([]age.Identity{id}, nil), nil
+	})
+
+	r, err = New(p)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, CountMainRecords(r))
+	err = r.DeleteOne(context.Background(), "https://www.example0.com")
+	assert.ErrorIs(t, err, ErrDBReadOnly)
+	r.Close()
+	after, err := os.ReadFile(p)
+	assert.NoError(t, err)
+	assert.Equal(t, before, after)
+}
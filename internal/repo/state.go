@@ -0,0 +1,134 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/haaag/gm/internal/bookmark"
+)
+
+// ByState returns the records with the given read-later state, oldest
+// first.
+func (r *SQLiteRepository) ByState(state string, bs *Slice) error {
+	q := `
+    SELECT
+      b.*,
+      COALESCE(GROUP_CONCAT(t.name, ','), '') AS tags
+    FROM
+      bookmarks b
+      LEFT JOIN bookmark_tags bt ON b.url = bt.bookmark_url
+      LEFT JOIN tags t ON bt.tag_id = t.id
+    WHERE
+      b.state = ?
+    GROUP BY
+      b.id
+    ORDER BY
+      b.created_at ASC,
+      b.id ASC;`
+
+	return r.bySQL(bs, q, state)
+}
+
+// SetState sets the read-later state of the records, clearing any snooze.
+func (r *SQLiteRepository) SetState(ctx context.Context, bs *Slice, state string) error {
+	if state != bookmark.StateNone {
+		if err := bookmark.ValidateState(state); err != nil {
+			return fmt.Errorf("%w", err)
+		}
+	}
+	q := `UPDATE bookmarks SET state = ?, state_at = ?, remind_at = '' WHERE url = ?`
+
+	return r.withTx(ctx, func(tx *sqlx.Tx) error {
+		before, err := rowsTx(tx, sliceURLs(bs))
+		if err != nil {
+			return err
+		}
+		if err := bs.ForEachMutErr(func(b *Row) error {
+			b.SetState(state)
+			if _, err := tx.ExecContext(ctx, q, b.State, b.StateAt, b.URL); err != nil {
+				return fmt.Errorf("setting state: %w: %q", err, b.URL)
+			}
+
+			return nil
+		}); err != nil {
+			return err
+		}
+
+		return recordTx(ctx, tx, OpState, before)
+	})
+}
+
+// SetRemind sets the reminder of the records, a zero time clears it.
+//
+// Unread records with a future reminder are hidden from the read-later
+// inbox.
+func (r *SQLiteRepository) SetRemind(ctx context.Context, bs *Slice, at time.Time) error {
+	var v string
+	if !at.IsZero() {
+		v = at.UTC().Format(time.RFC3339)
+	}
+	q := `UPDATE bookmarks SET remind_at = ? WHERE url = ?`
+
+	return r.withTx(ctx, func(tx *sqlx.Tx) error {
+		before, err := rowsTx(tx, sliceURLs(bs))
+		if err != nil {
+			return err
+		}
+		if err := bs.ForEachMutErr(func(b *Row) error {
+			if _, err := tx.ExecContext(ctx, q, v, b.URL); err != nil {
+				return fmt.Errorf("setting reminder: %w: %q", err, b.URL)
+			}
+			b.RemindAt = v
+
+			return nil
+		}); err != nil {
+			return err
+		}
+
+		return recordTx(ctx, tx, OpRemind, before)
+	})
+}
+
+// Due returns the records whose reminder has passed at the given time,
+// oldest reminder first.
+func (r *SQLiteRepository) Due(now time.Time, bs *Slice) error {
+	q := `
+    SELECT
+      b.*,
+      COALESCE(GROUP_CONCAT(t.name, ','), '') AS tags
+    FROM
+      bookmarks b
+      LEFT JOIN bookmark_tags bt ON b.url = bt.bookmark_url
+      LEFT JOIN tags t ON bt.tag_id = t.id
+    WHERE
+      b.remind_at != ''
+      AND b.remind_at <= ?
+    GROUP BY
+      b.id
+    ORDER BY
+      b.remind_at ASC,
+      b.id ASC;`
+
+	return r.bySQL(bs, q, now.UTC().Format(time.RFC3339))
+}
+
+// CountByState returns the number of records in each read-later state.
+func CountByState(r *SQLiteRepository) map[string]int {
+	q := `SELECT state, COUNT(*) AS n FROM bookmarks WHERE state != '' GROUP BY state`
+	var rows []struct {
+		State string `db:"state"`
+		N     int    `db:"n"`
+	}
+	counts := make(map[string]int, len(bookmark.States))
+	if err := r.DB.Select(&rows, q); err != nil {
+		return counts
+	}
+	for _, row := range rows {
+		counts[row.State] = row.N
+	}
+
+	return counts
+}
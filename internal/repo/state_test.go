@@ -0,0 +1,61 @@
+package repo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/haaag/gm/internal/bookmark"
+	"github.com/haaag/gm/internal/slice"
+)
+
+func TestReadLaterState(t *testing.T) {
+	r := setupTestDB(t)
+	defer teardownthewall(r.DB)
+	ctx := context.Background()
+	bs := testSliceBookmarks(4)
+	assert.NoError(t, r.InsertMany(ctx, bs))
+
+	unread := slice.New[Row]()
+	unread.Append(bs.Item(0), bs.Item(1), bs.Item(2))
+	assert.NoError(t, r.SetState(ctx, unread, bookmark.StateUnread))
+	assert.NotEmpty(t, unread.Item(0).StateAt)
+
+	read := slice.New[Row]()
+	read.Append(bs.Item(2))
+	assert.NoError(t, r.SetState(ctx, read, bookmark.StateRead))
+	assert.Error(t, r.SetState(ctx, read, "done"))
+
+	got := slice.New[Row]()
+	assert.NoError(t, r.ByState(bookmark.StateUnread, got))
+	assert.Equal(t, 2, got.Len())
+	assert.Equal(t, map[string]int{"unread": 2, "read": 1}, CountByState(r))
+
+	// reminders
+	now := time.Now()
+	remind := slice.New[Row]()
+	remind.Append(bs.Item(3))
+	assert.NoError(t, r.SetRemind(ctx, remind, now.Add(time.Hour)))
+	b, err := r.ByURL(bs.Item(3).URL)
+	assert.NoError(t, err)
+	assert.True(t, b.IsSnoozed(now))
+
+	due := slice.New[Row]()
+	assert.NoError(t, r.Due(now, due))
+	assert.Equal(t, 0, due.Len())
+	assert.NoError(t, r.Due(now.Add(2*time.Hour), due))
+	assert.Equal(t, 1, due.Len())
+
+	assert.NoError(t, r.SetRemind(ctx, remind, time.Time{}))
+	b, err = r.ByURL(bs.Item(3).URL)
+	assert.NoError(t, err)
+	assert.Empty(t, b.RemindAt)
+
+	// state survives reordering the IDs
+	assert.NoError(t, r.ReorderIDs(ctx))
+	b, err = r.ByURL(bs.Item(2).URL)
+	assert.NoError(t, err)
+	assert.Equal(t, bookmark.StateRead, b.State)
+}
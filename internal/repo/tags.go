@@ -107,3 +107,39 @@ func CounterTags(r *SQLiteRepository) (map[string]int, error) {
 
 	return tagCounts, nil
 }
+
+// CounterTagsByDomain returns a map with the tags used by the bookmarks of
+// the given domain as key and count as value.
+func CounterTagsByDomain(r *SQLiteRepository, domain string) (map[string]int, error) {
+	q := `
+    SELECT
+      t.name,
+      COUNT(bt.tag_id) AS tag_count
+    FROM
+      bookmarks b
+      JOIN bookmark_tags bt ON b.url = bt.bookmark_url
+      JOIN tags t ON bt.tag_id = t.id
+    WHERE
+      b.url LIKE ? OR b.url LIKE ? OR b.url LIKE ? OR b.url LIKE ?
+    GROUP BY
+      t.id,
+      t.name;`
+
+	var results []struct {
+		Name  string `db:"name"`
+		Count int    `db:"tag_count"`
+	}
+	args := []any{
+		"%://" + domain, "%://" + domain + "/%",
+		"%://www." + domain, "%://www." + domain + "/%",
+	}
+	if err := r.DB.Select(&results, q, args...); err != nil {
+		return nil, fmt.Errorf("error querying domain tags count: %w", err)
+	}
+	tagCounts := make(map[string]int, len(results))
+	for _, row := range results {
+		tagCounts[row.Name] = row.Count
+	}
+
+	return tagCounts, nil
+}
@@ -88,3 +88,23 @@ func TestGetOrCreateTag(t *testing.T) {
 		return nil
 	})
 }
+
+func TestTagsCounterByDomain(t *testing.T) {
+	t.Parallel()
+	r := setupTestDB(t)
+	defer teardownthewall(r.DB)
+	ctx := context.Background()
+	for _, b := range []struct{ url, tags string }{
+		{"https://github.com/haaag/gm", "code,go,"},
+		{"https://www.github.com/golang/go", "code,"},
+		{"https://github.com.evil.org/x", "spam,"},
+		{"https://gitlab.com/x", "code,"},
+	} {
+		row := &Row{URL: b.url, Tags: b.tags}
+		assert.NoError(t, r.InsertOne(ctx, row))
+	}
+
+	got, err := CounterTagsByDomain(r, "github.com")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int{"code": 2, "go": 1}, got)
+}
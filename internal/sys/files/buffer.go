@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"log/slog"
 	"os"
+	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/haaag/gm/internal/sys"
@@ -20,6 +22,9 @@ const (
 	FilePerm = 0o644 // Permissions for new files.
 )
 
+// lineEditors are the editors that open a file at a line with `+<line>`.
+var lineEditors = []string{"vi", "vim", "nvim", "nano", "emacs", "micro", "kak"}
+
 // Fallback text editors if $EDITOR || $GOMARKS_EDITOR var is not set.
 var textEditors = []string{"vim", "nvim", "nano", "emacs"}
 
@@ -31,6 +36,12 @@ type TextEditor struct {
 
 // EditBytes edits a byte slice with a text editor.
 func (te *TextEditor) EditBytes(content []byte, extension string) ([]byte, error) {
+	return te.EditBytesAt(content, extension, 0)
+}
+
+// EditBytesAt edits a byte slice with a text editor, opening it at the given
+// line if the editor supports it.
+func (te *TextEditor) EditBytesAt(content []byte, extension string, line int) ([]byte, error) {
 	if te.cmd == "" {
 		return nil, ErrCommandNotFound
 	}
@@ -40,8 +51,9 @@ func (te *TextEditor) EditBytes(content []byte, extension string) ([]byte, error
 	}
 	defer closeAndClean(f)
 
-	slog.Debug("editing file", "name", f.Name(), "editor", te.name)
-	if err := sys.RunCmd(te.cmd, append(te.args, f.Name())...); err != nil {
+	slog.Debug("editing file", "name", f.Name(), "editor", te.name, "line", line)
+	args := append(append([]string{}, te.args...), te.lineArgs(line)...)
+	if err := sys.RunCmd(te.cmd, append(args, f.Name())...); err != nil {
 		return nil, fmt.Errorf("error running editor: %w", err)
 	}
 
@@ -53,6 +65,16 @@ func (te *TextEditor) EditBytes(content []byte, extension string) ([]byte, error
 	return data, nil
 }
 
+// lineArgs returns the arguments to open the file at the line, if the
+// editor supports it.
+func (te *TextEditor) lineArgs(line int) []string {
+	if line <= 0 || !slices.Contains(lineEditors, filepath.Base(te.name)) {
+		return nil
+	}
+
+	return []string{fmt.Sprintf("+%d", line)}
+}
+
 // EditFile edits a file with a text editor.
 func (te *TextEditor) EditFile(p string) error {
 	if te.cmd == "" {
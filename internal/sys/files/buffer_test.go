@@ -54,3 +54,12 @@ func TestDiff(t *testing.T) {
 		})
 	}
 }
+
+func TestLineArgs(t *testing.T) {
+	t.Parallel()
+	te := newTextEditor("/usr/bin/nvim", "nvim", nil)
+	assert.Equal(t, []string{"+12"}, te.lineArgs(12))
+	assert.Nil(t, te.lineArgs(0))
+	te = newTextEditor("/usr/bin/code", "code", []string{"--wait"})
+	assert.Nil(t, te.lineArgs(12))
+}
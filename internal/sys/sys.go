@@ -20,6 +20,7 @@ var (
 	ErrCopyToClipboard   = errors.New("copy to clipboard")
 	ErrNotImplementedYet = errors.New("not implemented yet")
 	ErrActionAborted     = errors.New("action aborted")
+	ErrNotifyNotFound    = errors.New("notify-send not found")
 )
 
 // Env retrieves an environment variable.
@@ -125,6 +126,32 @@ func ReadClipboard() string {
 	return s
 }
 
+// Notify sends a desktop notification using `notify-send`.
+//
+// If actions are given, as `key=label`, waits until the user chooses one and
+// returns its key.
+func Notify(title, body string, actions ...string) (string, error) {
+	if !BinExists("notify-send") {
+		return "", ErrNotifyNotFound
+	}
+	args := []string{"--app-name=" + config.App.Name}
+	if len(actions) > 0 {
+		args = append(args, "--wait")
+	}
+	for _, a := range actions {
+		args = append(args, "--action="+a)
+	}
+	args = append(args, title, body)
+
+	slog.Debug("sending notification", "title", title, "actions", actions)
+	out, err := exec.CommandContext(context.Background(), "notify-send", args...).Output()
+	if err != nil {
+		return "", fmt.Errorf("sending notification: %w", err)
+	}
+
+	return strings.TrimSpace(string(out)), nil
+}
+
 // ErrAndExit logs the error and exits the program.
 func ErrAndExit(err error) {
 	if errors.Is(err, ErrActionAborted) {
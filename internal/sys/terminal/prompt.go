@@ -23,10 +23,13 @@ type PromptSuggester = func(in prompt.Document) []prompt.Suggest
 type filterFn = func(completions []prompt.Suggest, sub string, ignoreCase bool) []prompt.Suggest
 
 // inputWithTags prompts the user for input with suggestions based on
-// the provided tags.
-func inputWithTags[T comparable, V any](p string, items map[T]V, exitFn func(error)) string {
+// the provided tags, pre-filling the input with initial.
+func inputWithTags[T comparable, V any](p, initial string, items map[T]V, exitFn func(error)) string {
 	o, restore := prepareInputState(exitFn)
 	defer restore()
+	if initial != "" {
+		o = append(o, prompt.OptionInitialBufferText(initial))
+	}
 
 	s := prompt.Input(p, completerTagsWithCount(items, prompt.FilterHasPrefix), o...)
 
@@ -162,7 +162,18 @@ func (t *Term) PromptWithFuzzySuggestions(p string, items []string) string {
 // ChooseTags prompts the user for input with suggestions based on
 // the provided tags.
 func (t *Term) ChooseTags(p string, items map[string]int) string {
-	return inputWithTags(p, items, t.InterruptFn)
+	return inputWithTags(p, "", items, t.InterruptFn)
+}
+
+// ChooseTagsWithSuggested prompts the user for input with suggestions based
+// on the provided tags, pre-filling the input with the suggested tags.
+func (t *Term) ChooseTagsWithSuggested(p string, items map[string]int, suggested []string) string {
+	initial := strings.Join(suggested, " ")
+	if initial != "" {
+		initial += " "
+	}
+
+	return inputWithTags(p, initial, items, t.InterruptFn)
 }
 
 // Confirm prompts the user with a question and options.